@@ -0,0 +1,177 @@
+package voxelraytrace
+
+import (
+	"github.com/go-gl/mathgl/mgl64"
+	"math"
+)
+
+// AABB is an axis-aligned bounding box spanning from Min to Max.
+type AABB struct {
+	Min, Max mgl64.Vec3
+}
+
+// NewAABB creates an AABB spanning the two corners passed, which need not be ordered.
+func NewAABB(a, b mgl64.Vec3) AABB {
+	box := AABB{Min: a, Max: b}
+	for i := 0; i < 3; i++ {
+		if box.Min[i] > box.Max[i] {
+			box.Min[i], box.Max[i] = box.Max[i], box.Min[i]
+		}
+	}
+	return box
+}
+
+// Center returns the centre of the box.
+func (b AABB) Center() mgl64.Vec3 {
+	return b.Min.Add(b.Max).Mul(0.5)
+}
+
+// Corners returns the eight corners of the box, ordered lexicographically by whether they lie on the minimum or the
+// maximum side on the X, Y and Z axis, starting with Min and ending with Max.
+func (b AABB) Corners() [8]mgl64.Vec3 {
+	var corners [8]mgl64.Vec3
+	for i := range corners {
+		corners[i] = b.Min
+		if i&4 != 0 {
+			corners[i][0] = b.Max[0]
+		}
+		if i&2 != 0 {
+			corners[i][1] = b.Max[1]
+		}
+		if i&1 != 0 {
+			corners[i][2] = b.Max[2]
+		}
+	}
+	return corners
+}
+
+// Translate returns the box moved by the vector passed.
+func (b AABB) Translate(v mgl64.Vec3) AABB {
+	return AABB{Min: b.Min.Add(v), Max: b.Max.Add(v)}
+}
+
+// TraceAABBCorners predicts if a box moving by the movement vector passed is obstructed by the Grid, by tracing rays
+// from its centre and its eight corners along the movement. The rays are traced together using TracePacket, and
+// corners that are at the same position, such as those of a flat box, are only traced once. blocked is true if any
+// of the rays hit a solid voxel, in which case firstHit is the nearest hit among them, with ties going to the centre
+// and then to the corners in the order returned by AABB.Corners. ok is false if the movement is zero, in which case
+// nothing is traced.
+// This is coarser than sweeping the full box, as solid voxels between the rays are missed, but also much cheaper.
+func TraceAABBCorners(box AABB, movement mgl64.Vec3, g Grid) (blocked bool, firstHit HitResult, ok bool) {
+	if movement.LenSqr() == 0 {
+		return false, HitResult{}, false
+	}
+
+	corners := box.Corners()
+	starts := append(make([]mgl64.Vec3, 0, 9), box.Center())
+	for _, c := range corners {
+		if !containsVec3(starts, c) {
+			starts = append(starts, c)
+		}
+	}
+	var (
+		p   RayPacket
+		out HitPacket
+	)
+	for _, start := range starts {
+		// The movement is passed as the direction with a length of 1, so that every ray ends at exactly
+		// start+movement, like the ray traced by FirstSolidHit below.
+		p.Add(start, movement, 1)
+	}
+	TracePacket(&p, g, &out)
+
+	nearest := -1
+	for i, hit := range out.Hit {
+		if hit && (nearest == -1 || out.Distance[i] < out.Distance[nearest]) {
+			nearest = i
+		}
+	}
+	if nearest == -1 {
+		return false, HitResult{}, true
+	}
+	start := starts[nearest]
+	firstHit, _, _ = FirstSolidHit(g, start, start.Add(movement))
+	return true, firstHit, true
+}
+
+// containsVec3 checks if the vector passed is in the slice passed.
+func containsVec3(vectors []mgl64.Vec3, v mgl64.Vec3) bool {
+	for _, vec := range vectors {
+		if vec == v {
+			return true
+		}
+	}
+	return false
+}
+
+// ClipAABB intersects the segment between the start and end coordinates with the box passed using the slab method,
+// such as to test the collision box of a block found by FirstSolidHit. It returns the point at which the segment
+// enters the box and the face of the box it enters through. If the start lies inside the box or on its surface, the
+// start is returned with FaceNone. If the segment does not reach the box, false is returned.
+func ClipAABB(start, end mgl64.Vec3, box AABB) (hit mgl64.Vec3, face Face, ok bool) {
+	hit, face, _, ok = clipAABB(start, end, box)
+	return hit, face, ok
+}
+
+// IntersectAABBs intersects the segment between the start and end coordinates with every box passed, such as the
+// hitboxes of entities, and returns the index of the box that the segment enters first, along with the point at
+// which it enters it. The boxes are intersected the same way as done by ClipAABB, and a box containing the start is
+// entered at the start. If several boxes are entered at the same point, the one with the lowest index is returned.
+// If the segment does not reach any of the boxes, false is returned.
+func IntersectAABBs(start, end mgl64.Vec3, boxes []AABB) (index int, hitPoint mgl64.Vec3, ok bool) {
+	nearest := math.Inf(1)
+	for i, box := range boxes {
+		if hit, _, t, hitOk := clipAABB(start, end, box); hitOk && t < nearest {
+			index, hitPoint, nearest, ok = i, hit, t, true
+		}
+	}
+	return index, hitPoint, ok
+}
+
+// clipAABB intersects the segment between the start and end coordinates with the box passed like ClipAABB, and also
+// returns the position along the segment at which it enters the box, from 0 at the start to 1 at the end.
+func clipAABB(start, end mgl64.Vec3, box AABB) (hit mgl64.Vec3, face Face, t float64, ok bool) {
+	diff := end.Sub(start)
+	// The distances are in multiples of diff, so the segment spans from 0 to 1.
+	tEntry, tExit, axis := 0.0, 1.0, -1
+	for i := 0; i < 3; i++ {
+		if diff[i] == 0 {
+			if start[i] < box.Min[i] || start[i] > box.Max[i] {
+				return mgl64.Vec3{}, FaceNone, 0, false
+			}
+			continue
+		}
+		t1, t2, step := (box.Min[i]-start[i])/diff[i], (box.Max[i]-start[i])/diff[i], 1
+		if t1 > t2 {
+			t1, t2, step = t2, t1, -1
+		}
+		if t1 > tEntry {
+			tEntry, axis, face = t1, i, axisEntryFace(step, i)
+		}
+		tExit = math.Min(tExit, t2)
+	}
+	if tEntry > tExit {
+		return mgl64.Vec3{}, FaceNone, 0, false
+	}
+	if axis == -1 {
+		return start, FaceNone, 0, true
+	}
+	hit = along(start, diff, tEntry)
+	// The point lies exactly on the face entered, which rounding might otherwise move it off.
+	if face == axisEntryFace(1, axis) {
+		hit[axis] = box.Min[axis]
+	} else {
+		hit[axis] = box.Max[axis]
+	}
+	return hit, face, tEntry, true
+}
+
+// AABBInt is an axis-aligned box of voxels spanning from the voxel at Min to the voxel at Max, both inclusive.
+type AABBInt struct {
+	Min, Max BlockPos
+}
+
+// Empty checks if the box holds no voxels, which is the case if Max is below Min on any axis.
+func (b AABBInt) Empty() bool {
+	return b.Max[0] < b.Min[0] || b.Max[1] < b.Min[1] || b.Max[2] < b.Min[2]
+}
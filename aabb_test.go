@@ -0,0 +1,56 @@
+package voxelraytrace
+
+import (
+	"github.com/go-gl/mathgl/mgl64"
+	"math/rand"
+	"testing"
+)
+
+func TestClipAABB(t *testing.T) {
+	box := AABB{Min: mgl64.Vec3{1, 0, 1}, Max: mgl64.Vec3{2, 0.5, 2}}
+	tests := []struct {
+		name       string
+		start, end mgl64.Vec3
+		hit        mgl64.Vec3
+		face       Face
+		ok         bool
+	}{
+		{name: "west", start: mgl64.Vec3{-1, 0.25, 1.5}, end: mgl64.Vec3{3, 0.25, 1.5}, hit: mgl64.Vec3{1, 0.25, 1.5}, face: FaceWest, ok: true},
+		{name: "east", start: mgl64.Vec3{3, 0.25, 1.5}, end: mgl64.Vec3{-1, 0.25, 1.5}, hit: mgl64.Vec3{2, 0.25, 1.5}, face: FaceEast, ok: true},
+		{name: "top of slab", start: mgl64.Vec3{1.5, 2, 1.5}, end: mgl64.Vec3{1.5, -1, 1.5}, hit: mgl64.Vec3{1.5, 0.5, 1.5}, face: FaceUp, ok: true},
+		{name: "north diagonal", start: mgl64.Vec3{1.5, 1.25, 0}, end: mgl64.Vec3{1.5, -0.75, 2}, hit: mgl64.Vec3{1.5, 0.25, 1}, face: FaceNorth, ok: true},
+		{name: "start inside", start: mgl64.Vec3{1.5, 0.25, 1.5}, end: mgl64.Vec3{5, 5, 5}, hit: mgl64.Vec3{1.5, 0.25, 1.5}, face: FaceNone, ok: true},
+		{name: "start on surface", start: mgl64.Vec3{1, 0.25, 1.5}, end: mgl64.Vec3{-5, 0.25, 1.5}, hit: mgl64.Vec3{1, 0.25, 1.5}, face: FaceNone, ok: true},
+		{name: "ending on surface", start: mgl64.Vec3{-1, 0.25, 1.5}, end: mgl64.Vec3{1, 0.25, 1.5}, hit: mgl64.Vec3{1, 0.25, 1.5}, face: FaceWest, ok: true},
+		{name: "too short", start: mgl64.Vec3{-1, 0.25, 1.5}, end: mgl64.Vec3{0.5, 0.25, 1.5}},
+		{name: "over slab", start: mgl64.Vec3{-1, 0.75, 1.5}, end: mgl64.Vec3{3, 0.75, 1.5}},
+		{name: "parallel outside", start: mgl64.Vec3{-1, 0.25, 3}, end: mgl64.Vec3{3, 0.25, 3}},
+		{name: "pointing away", start: mgl64.Vec3{3, 0.25, 1.5}, end: mgl64.Vec3{5, 0.25, 1.5}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			hit, face, ok := ClipAABB(test.start, test.end, box)
+			if ok != test.ok || ok && (!vec3Near(hit, test.hit) || face != test.face) {
+				t.Errorf("got %v, %v, %v, want %v, %v, %v", hit, face, ok, test.hit, test.face, test.ok)
+			}
+		})
+	}
+}
+
+func TestClipAABBMatchesFirstSolidHit(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 5000; i++ {
+		// The rays are not rounded, so that they never touch only an edge or corner of the voxel.
+		start, dir, length := randomUnitRay(rng)
+		end := start.Add(dir.Mul(length))
+		pos := BlockPos{rng.Intn(9) - 4, rng.Intn(9) - 4, rng.Intn(9) - 4}
+		g := NewSparseGrid()
+		g.Set(pos[0], pos[1], pos[2], true)
+
+		want, wantOK, _ := FirstSolidHit(g, start, end)
+		hit, face, ok := ClipAABB(start, end, AABB{Min: pos.Vec3Min(), Max: pos.Vec3Min().Add(mgl64.Vec3{1, 1, 1})})
+		if ok != wantOK || ok && (!vec3Near(hit, want.Point) || face != want.Face) {
+			t.Fatalf("trace %v -> %v at %v: got %v, %v, %v, want %v, %v, %v", start, end, pos, hit, face, ok, want.Point, want.Face, wantOK)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package voxelraytrace
+
+import (
+	"errors"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// TraceArc traces the path of a projectile affected by gravity and drag, such as an arrow or a snowball, and calls fn
+// for every voxel it passes through, in the order they are passed through, until fn returns false. The path is
+// simulated in ticks like Minecraft does: every tick, the projectile moves in a straight line by its velocity, after
+// which the velocity is multiplied by 1-drag and gravity is subtracted from its Y component. The voxels along every
+// tick are found using the same traversal as BetweenPoints, and the Options passed apply to every tick separately. fn
+// is called with the time at which the projectile entered the voxel, in ticks since the start, including the fraction
+// of the tick. The voxel shared by the end of one tick and the start of the next is only passed once. The path ends
+// after maxTicks ticks. An error is returned if drag is not between 0 and 1 or if a tick fails to be traced.
+func TraceArc(start, velocity mgl64.Vec3, gravity, drag float64, maxTicks int, fn func(pos BlockPos, time float64) bool, opts ...Option) error {
+	if !(drag >= 0 && drag <= 1) {
+		return errors.New("drag must be between 0 and 1")
+	}
+	var (
+		last    BlockPos
+		visited bool
+	)
+	pos, conf := start, newConfig(opts)
+	for tick := 0; tick < maxTicks; tick++ {
+		end := pos.Add(velocity)
+		// The products are rounded explicitly, so that they are not fused with the additions of the next tick.
+		k := 1 - drag
+		velocity = mgl64.Vec3{float64(velocity[0] * k), float64(velocity[1]*k) - gravity, float64(velocity[2] * k)}
+		if end == pos {
+			continue
+		}
+		t, err := newTracer(pos, end, conf)
+		if err != nil {
+			return err
+		}
+		for {
+			if p := t.pos(); !visited || p != last {
+				if !fn(p, float64(tick)+t.t/t.radius) {
+					return nil
+				}
+				last, visited = p, true
+			}
+			if !t.next() {
+				break
+			}
+		}
+		if t.err != nil {
+			return t.err
+		}
+		pos = end
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package voxelraytrace
+
+import (
+	"errors"
+	"github.com/go-gl/mathgl/mgl64"
+	"math"
+)
+
+// TraceAttenuated traces light from the start to the end coordinates, accumulating the opacity of the voxels passed
+// through until the threshold is reached. Every voxel adds its opacity multiplied by the length of the part of the
+// ray inside it, so voxels with an opacity of zero do not attenuate the light.
+// remaining is the threshold minus the opacity accumulated, which is 0 if the light was exhausted before reaching the
+// end. In that case, stoppedAt is the voxel in which the threshold was reached and dist the exact distance along the
+// ray at which that happened. Otherwise, stoppedAt is the last voxel passed through and dist the length of the ray.
+// The threshold must be positive and opacities must not be negative.
+func TraceAttenuated(start, end mgl64.Vec3, opacity func(pos BlockPos) float64, threshold float64, opts ...Option) (remaining float64, stoppedAt BlockPos, dist float64, err error) {
+	if !(threshold > 0) {
+		return 0, BlockPos{}, 0, errors.New("attenuation threshold must be positive")
+	}
+	t, err := newTracer(start, end, newConfig(opts))
+	if err != nil {
+		return 0, BlockPos{}, 0, err
+	}
+	var accumulated float64
+	for {
+		if o := opacity(t.pos()); o > 0 {
+			exit, _ := t.peek()
+			a := accumulated + o*(math.Min(exit, t.radius)-t.t)
+			if a >= threshold {
+				return 0, t.pos(), t.t + (threshold-accumulated)/o, nil
+			}
+			accumulated = a
+		}
+		if !t.next() {
+			return threshold - accumulated, t.pos(), t.radius, t.err
+		}
+	}
+}
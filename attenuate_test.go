@@ -0,0 +1,83 @@
+package voxelraytrace
+
+import (
+	"github.com/go-gl/mathgl/mgl64"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestTraceAttenuatedUniform(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 2000; i++ {
+		start, end := randomPoint(rng, 8), randomPoint(rng, 8)
+		length := end.Sub(start).Len()
+		if length == 0 {
+			continue
+		}
+		o, threshold := 0.1+rng.Float64(), rng.Float64()*10+0.1
+		remaining, stoppedAt, dist, err := TraceAttenuated(start, end, func(BlockPos) float64 { return o }, threshold)
+		if err != nil {
+			t.Fatalf("trace %v -> %v: unexpected error: %v", start, end, err)
+		}
+		// With a uniform opacity, the light is exhausted at a distance of threshold/o along the ray.
+		if want := threshold / o; want < length {
+			if remaining != 0 || math.Abs(dist-want) > 1e-9 {
+				t.Fatalf("trace %v -> %v: got %v remaining at %v, want 0 at %v", start, end, remaining, dist, want)
+			}
+			positions, _ := BetweenPointsInt(start, end)
+			if !containsPos(positions, stoppedAt) || !voxelContains(stoppedAt, along(start, end.Sub(start).Normalize(), dist)) {
+				t.Fatalf("trace %v -> %v: stopped at voxel %v, which is not the voxel at distance %v", start, end, stoppedAt, dist)
+			}
+		} else if want := threshold - o*length; math.Abs(remaining-want) > 1e-9 || dist != length {
+			t.Fatalf("trace %v -> %v: got %v remaining at %v, want %v at %v", start, end, remaining, dist, want, length)
+		}
+	}
+}
+
+func TestTraceAttenuated(t *testing.T) {
+	// Only a slab of voxels from X 4 to 5 is opaque, with an opacity of 2 per unit of length.
+	opacity := func(pos BlockPos) float64 {
+		if pos[0] == 4 || pos[0] == 5 {
+			return 2
+		}
+		return 0
+	}
+	start := mgl64.Vec3{0.5, 0.5, 0.5}
+	tests := []struct {
+		name      string
+		end       mgl64.Vec3
+		threshold float64
+		remaining float64
+		stoppedAt BlockPos
+		dist      float64
+	}{
+		{name: "before slab", end: mgl64.Vec3{3.5, 0.5, 0.5}, threshold: 1, remaining: 1, stoppedAt: BlockPos{3, 0, 0}, dist: 3},
+		{name: "through slab", end: mgl64.Vec3{9.5, 0.5, 0.5}, threshold: 5, remaining: 1, stoppedAt: BlockPos{9, 0, 0}, dist: 9},
+		{name: "exhausted in slab", end: mgl64.Vec3{9.5, 0.5, 0.5}, threshold: 3, stoppedAt: BlockPos{5, 0, 0}, dist: 5},
+		{name: "exhausted on boundary", end: mgl64.Vec3{9.5, 0.5, 0.5}, threshold: 2, stoppedAt: BlockPos{4, 0, 0}, dist: 4.5},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			remaining, stoppedAt, dist, err := TraceAttenuated(start, test.end, opacity, test.threshold)
+			if err != nil || math.Abs(remaining-test.remaining) > 1e-9 || stoppedAt != test.stoppedAt || math.Abs(dist-test.dist) > 1e-9 {
+				t.Errorf("got %v, %v, %v, %v, want %v, %v, %v", remaining, stoppedAt, dist, err, test.remaining, test.stoppedAt, test.dist)
+			}
+		})
+	}
+	for _, threshold := range []float64{0, -1, math.NaN()} {
+		if _, _, _, err := TraceAttenuated(start, start.Add(mgl64.Vec3{1, 0, 0}), opacity, threshold); err == nil {
+			t.Errorf("expected an error for a threshold of %v", threshold)
+		}
+	}
+}
+
+// containsPos checks if the positions passed contain pos.
+func containsPos(positions []BlockPos, pos BlockPos) bool {
+	for _, p := range positions {
+		if p == pos {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,59 @@
+package voxelraytrace
+
+import (
+	"fmt"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// Axis represents one of the three axes of the grid.
+type Axis uint8
+
+const (
+	// AxisNone is the Axis reported where no axis applies, such as for the voxel a ray starts in.
+	AxisNone Axis = iota
+	// AxisX is the X axis.
+	AxisX
+	// AxisY is the Y axis.
+	AxisY
+	// AxisZ is the Z axis.
+	AxisZ
+)
+
+// String returns the name of the axis.
+func (a Axis) String() string {
+	switch a {
+	case AxisX:
+		return "x"
+	case AxisY:
+		return "y"
+	case AxisZ:
+		return "z"
+	}
+	return "none"
+}
+
+// UnitVector returns the unit vector pointing towards positive coordinates along the axis. UnitVector panics if
+// AxisNone or an unknown axis is passed.
+func (a Axis) UnitVector() mgl64.Vec3 {
+	return a.PosFace().Offset().Vec3Min()
+}
+
+// PosFace returns the face of a voxel pointing towards positive coordinates along the axis. PosFace panics if
+// AxisNone or an unknown axis is passed.
+func (a Axis) PosFace() FaceID {
+	switch a {
+	case AxisX:
+		return FacePosX
+	case AxisY:
+		return FacePosY
+	case AxisZ:
+		return FacePosZ
+	}
+	panic(fmt.Sprintf("voxelraytrace: axis %v (%d) is not a valid axis", a, uint8(a)))
+}
+
+// NegFace returns the face of a voxel pointing towards negative coordinates along the axis. NegFace panics if
+// AxisNone or an unknown axis is passed.
+func (a Axis) NegFace() FaceID {
+	return a.PosFace().Opposite()
+}
@@ -0,0 +1,57 @@
+package voxelraytrace
+
+import (
+	"github.com/go-gl/mathgl/mgl64"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// batchSize is the amount of rays handed to a goroutine of TraceBatch at once. Single rays are often too cheap to
+// hand out separately.
+const batchSize = 64
+
+// TraceBatch performs a ray trace along every ray passed, like Ray.Cast, and returns the coordinates of the voxels
+// passed through by each of them, at the same index as the ray. The rays are traced by one goroutine per CPU, which
+// suits the many independent rays of lighting bakes and visibility passes. All rays are traced, even if some of them
+// fail: the voxels passed through before a failure are kept, and the error of the first ray that failed, by index,
+// is returned.
+func TraceBatch(rays []Ray, opts ...Option) ([][]mgl64.Vec3, error) {
+	results, errs := make([][]mgl64.Vec3, len(rays)), make([]error, len(rays))
+	workers := runtime.GOMAXPROCS(0)
+	if n := (len(rays) + batchSize - 1) / batchSize; workers > n {
+		workers = n
+	}
+
+	var (
+		next int64
+		wg   sync.WaitGroup
+	)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				end := int(atomic.AddInt64(&next, batchSize))
+				start := end - batchSize
+				if start >= len(rays) {
+					return
+				}
+				if end > len(rays) {
+					end = len(rays)
+				}
+				for i := start; i < end; i++ {
+					results[i], errs[i] = rays[i].Cast(opts...)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return results, err
+		}
+	}
+	return results, nil
+}
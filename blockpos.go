@@ -0,0 +1,57 @@
+package voxelraytrace
+
+import (
+	"github.com/go-gl/mathgl/mgl64"
+	"math"
+)
+
+// BlockPos holds the integer coordinates of a voxel. It is a comparable value type and may be used as a map key.
+type BlockPos [3]int
+
+// BlockPosFromVec3 returns the BlockPos of the voxel that contains the vector passed. The coordinates are floored,
+// so that (-0.5, 0, 0) is in the voxel at (-1, 0, 0).
+func BlockPosFromVec3(v mgl64.Vec3) BlockPos {
+	return BlockPos{int(math.Floor(v[0])), int(math.Floor(v[1])), int(math.Floor(v[2]))}
+}
+
+// X returns the X coordinate of the BlockPos.
+func (p BlockPos) X() int {
+	return p[0]
+}
+
+// Y returns the Y coordinate of the BlockPos.
+func (p BlockPos) Y() int {
+	return p[1]
+}
+
+// Z returns the Z coordinate of the BlockPos.
+func (p BlockPos) Z() int {
+	return p[2]
+}
+
+// Add adds the BlockPos passed to p and returns the result.
+func (p BlockPos) Add(o BlockPos) BlockPos {
+	return BlockPos{p[0] + o[0], p[1] + o[1], p[2] + o[2]}
+}
+
+// Side returns the position of the voxel on the other side of the face passed.
+func (p BlockPos) Side(f Face) BlockPos {
+	return p.Add(f.Offset())
+}
+
+// Neighbours returns the positions of the six voxels sharing a face with the voxel, in the order of the faces
+// FaceDown, FaceUp, FaceNorth, FaceSouth, FaceWest and FaceEast.
+func (p BlockPos) Neighbours() [6]BlockPos {
+	return [6]BlockPos{p.Side(FaceDown), p.Side(FaceUp), p.Side(FaceNorth), p.Side(FaceSouth), p.Side(FaceWest), p.Side(FaceEast)}
+}
+
+// Vec3Centre returns the centre of the voxel as a vector.
+func (p BlockPos) Vec3Centre() mgl64.Vec3 {
+	return mgl64.Vec3{float64(p[0]) + 0.5, float64(p[1]) + 0.5, float64(p[2]) + 0.5}
+}
+
+// Vec3Min returns the corner of the voxel with the lowest coordinates as a vector. This is also the vector
+// representation used by BetweenPoints.
+func (p BlockPos) Vec3Min() mgl64.Vec3 {
+	return mgl64.Vec3{float64(p[0]), float64(p[1]), float64(p[2])}
+}
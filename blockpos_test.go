@@ -0,0 +1,65 @@
+package voxelraytrace
+
+import (
+	"github.com/go-gl/mathgl/mgl64"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestBlockPosFromVec3(t *testing.T) {
+	tests := []struct {
+		v    mgl64.Vec3
+		want BlockPos
+	}{
+		{v: mgl64.Vec3{0, 0, 0}, want: BlockPos{0, 0, 0}},
+		{v: mgl64.Vec3{0.5, 1.999, 2}, want: BlockPos{0, 1, 2}},
+		{v: mgl64.Vec3{-0.5, -1, -1.001}, want: BlockPos{-1, -1, -2}},
+		{v: mgl64.Vec3{-0.000001, 64.5, -30000000.5}, want: BlockPos{-1, 64, -30000001}},
+	}
+	for _, test := range tests {
+		if got := BlockPosFromVec3(test.v); got != test.want {
+			t.Errorf("BlockPosFromVec3(%v): got %v, want %v", test.v, got, test.want)
+		}
+	}
+}
+
+func TestBlockPosVec3RoundTrip(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 1000; i++ {
+		pos := BlockPos{rng.Intn(2001) - 1000, rng.Intn(2001) - 1000, rng.Intn(2001) - 1000}
+		if got := BlockPosFromVec3(pos.Vec3Min()); got != pos {
+			t.Fatalf("%v: got %v from the minimum corner", pos, got)
+		}
+		if got := BlockPosFromVec3(pos.Vec3Centre()); got != pos {
+			t.Fatalf("%v: got %v from the centre", pos, got)
+		}
+		if got := pos.Vec3Centre().Sub(pos.Vec3Min()); got != (mgl64.Vec3{0.5, 0.5, 0.5}) {
+			t.Fatalf("%v: centre is %v away from the minimum corner", pos, got)
+		}
+	}
+}
+
+func TestBlockPosNeighbours(t *testing.T) {
+	pos := BlockPos{1, -2, 3}
+	want := [6]BlockPos{{1, -3, 3}, {1, -1, 3}, {1, -2, 2}, {1, -2, 4}, {0, -2, 3}, {2, -2, 3}}
+	if got := pos.Neighbours(); got != want {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	for i, f := range []Face{FaceDown, FaceUp, FaceNorth, FaceSouth, FaceWest, FaceEast} {
+		if got := pos.Side(f); got != want[i] {
+			t.Errorf("%v: got %v, want %v", f, got, want[i])
+		}
+		if got := pos.Side(f).Side(f.Opposite()); got != pos {
+			t.Errorf("%v: got %v after stepping back through the opposite face, want %v", f, got, pos)
+		}
+	}
+}
+
+func TestBlockPosMapKey(t *testing.T) {
+	m := map[BlockPos]int{{1, 2, 3}: 1}
+	m[BlockPosFromVec3(mgl64.Vec3{1.5, 2.5, 3.5})]++
+	if !reflect.DeepEqual(m, map[BlockPos]int{{1, 2, 3}: 2}) {
+		t.Errorf("got %v, want a single key", m)
+	}
+}
@@ -0,0 +1,45 @@
+package voxelraytrace
+
+import (
+	"github.com/go-gl/mathgl/mgl64"
+	"math"
+)
+
+// nudgeEpsilon is the epsilon used by WithNudgeBoundary.
+const nudgeEpsilon = 1e-9
+
+// IsOnVoxelBoundary checks if any of the coordinates of the point passed lies within epsilon of an integer, and thus
+// of a boundary between two voxels.
+func IsOnVoxelBoundary(point mgl64.Vec3, epsilon float64) bool {
+	for _, v := range point {
+		if math.Abs(v-math.Round(v)) <= epsilon {
+			return true
+		}
+	}
+	return false
+}
+
+// NudgeOffBoundary moves the point passed along the direction passed, just far enough for every coordinate that lies
+// within epsilon of a voxel boundary to end up twice epsilon away from it. Coordinates on an axis the direction does
+// not move along cannot be moved off their boundary and are left as they are. If the point is not on a boundary, it
+// is returned unchanged.
+func NudgeOffBoundary(point, direction mgl64.Vec3, epsilon float64) mgl64.Vec3 {
+	if sumSquares(direction[0], direction[1], direction[2]) == 0 {
+		return point
+	}
+	direction = yUp.normalize(direction)
+	return along(point, direction, nudgeDistance(point, direction, epsilon))
+}
+
+// nudgeDistance returns the distance a point must be moved along the normalised direction passed for NudgeOffBoundary.
+func nudgeDistance(point, direction mgl64.Vec3, epsilon float64) float64 {
+	var dist float64
+	for i, v := range point {
+		d := math.Abs(v - math.Round(v))
+		if d > epsilon || direction[i] == 0 {
+			continue
+		}
+		dist = math.Max(dist, (float64(2*epsilon)-d)/math.Abs(direction[i]))
+	}
+	return dist
+}
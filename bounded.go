@@ -0,0 +1,128 @@
+package voxelraytrace
+
+import (
+	"errors"
+	"fmt"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// ErrOutOfBounds is matched by the OutOfBoundsError returned when a ray starts outside of the bounds of a
+// BoundedGrid, using errors.Is.
+var ErrOutOfBounds = errors.New("start position out of grid bounds")
+
+// OutOfBoundsError is returned by the functions consuming a Grid when the ray starts in a voxel outside of the bounds
+// of a BoundedGrid.
+type OutOfBoundsError struct {
+	// Pos is the position of the voxel the ray starts in.
+	Pos BlockPos
+}
+
+// Error returns a message holding the position of the voxel the ray starts in.
+func (e OutOfBoundsError) Error() string {
+	return fmt.Sprintf("start position %v out of grid bounds", e.Pos)
+}
+
+// Is reports if the target is ErrOutOfBounds.
+func (e OutOfBoundsError) Is(target error) bool {
+	return target == ErrOutOfBounds
+}
+
+// BoundedGrid is a Grid that only holds voxels within finite bounds. The functions consuming a Grid, such as
+// FirstSolidHit and SolidHits, return an OutOfBoundsError if the ray starts outside the bounds of a BoundedGrid, and
+// stop silently, as if the ray ended there, when it leaves the bounds. Voxels outside the bounds are never reported as
+// hits. ArrayGrid and BitsetGrid implement BoundedGrid.
+type BoundedGrid interface {
+	Grid
+	// Bounds returns the positions of the voxels at the minimum and maximum corners of the grid, both inclusive.
+	Bounds() (min, max BlockPos)
+}
+
+// bound limits the tracer to the bounds of the Grid passed if it implements BoundedGrid. An OutOfBoundsError is
+// returned if the tracer is positioned outside the bounds.
+func (t *tracer) bound(g Grid) error {
+	bg, ok := g.(BoundedGrid)
+	if !ok {
+		return nil
+	}
+	min, max := bg.Bounds()
+	if pos := t.pos(); !insideRegion(pos, min, max) {
+		return OutOfBoundsError{Pos: pos}
+	}
+	// The grid is never left on an axis that the coordinates wrap around on, so those axes are not limited.
+	for i, size := range t.wrap {
+		if size > 0 {
+			min[i], max[i] = minInt, maxInt
+		}
+	}
+	t.limit(min, max)
+	return nil
+}
+
+// minInt and maxInt are the smallest and largest values of an int, which limit an axis to any coordinate.
+const (
+	maxInt = int(^uint(0) >> 1)
+	minInt = -maxInt - 1
+)
+
+// limit limits the tracer to the voxels between min and max, both inclusive, in addition to any ranges it is already
+// limited to.
+func (t *tracer) limit(min, max BlockPos) {
+	for i, r := range t.ranges {
+		if !r.set || r.min < min[i] {
+			r.min = min[i]
+		}
+		if !r.set || r.max > max[i] {
+			r.max = max[i]
+		}
+		r.set = true
+		t.ranges[i] = r
+	}
+	t.limited = true
+}
+
+// enter moves the tracer to the first voxel passed through that lies within the bounds set using WithBounds and
+// beyond the distance set using WithMinDistance in the config passed, limiting it to the bounds. False is returned if
+// the ray does not pass through any such voxel, in which case the tracer should not be used further.
+func (t *tracer) enter(conf config) bool {
+	if conf.bounded {
+		if conf.bounds.Empty() || !t.clip(conf.bounds.Min, conf.bounds.Max) {
+			return false
+		}
+	}
+	return t.skipTo(conf.minDistance)
+}
+
+// clip limits the tracer to the box of voxels between min and max, both inclusive, and moves it to the first voxel
+// inside the box passed through by the ray, skipping the voxels before it. False is returned if the ray does not pass
+// through any voxel inside the box, in which case the tracer should not be used further.
+func (t *tracer) clip(min, max BlockPos) bool {
+	// The box is grown by a margin, so that rays only touching it where rounding matters are still stepped through.
+	margin := mgl64.Vec3{skipMargin, skipMargin, skipMargin}
+	lower, upper := min.Vec3Min().Sub(margin), max.Add(BlockPos{1, 1, 1}).Vec3Min().Add(margin)
+	tEntry, tExit, ok := intersectBox(t.start, t.dir, lower, upper)
+	if !ok || tExit < 0 || tEntry > t.radius {
+		return false
+	}
+	t.limit(min, max)
+	if insideRegion(t.rawPos(), min, max) {
+		return true
+	}
+	// The voxels skipped are neither passed to the step hook nor counted towards WithMaxVoxels.
+	hook, maxVoxels := t.hook, t.maxVoxels
+	t.hook, t.maxVoxels = nil, 0
+	defer func() { t.hook, t.maxVoxels = hook, maxVoxels }()
+
+	t.approach(tEntry)
+	// The ray is stepped into the box, rather than jumped into it, so that it enters the box through the same voxels
+	// as it would if every voxel before it were stepped through, even where it enters through an edge or corner.
+	for !insideRegion(t.rawPos(), min, max) {
+		if !t.next() {
+			return false
+		}
+	}
+	t.visited = 1
+	if hook != nil {
+		hook(t.stepInfo())
+	}
+	return true
+}
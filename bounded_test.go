@@ -0,0 +1,187 @@
+package voxelraytrace
+
+import (
+	"errors"
+	"github.com/go-gl/mathgl/mgl64"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+// filterRegion returns the positions passed that lie inside the region spanning from min to max, in order.
+func filterRegion(positions []BlockPos, min, max BlockPos) []BlockPos {
+	var inside []BlockPos
+	for _, pos := range positions {
+		if insideRegion(pos, min, max) {
+			inside = append(inside, pos)
+		}
+	}
+	return inside
+}
+
+func TestWithBoundsEdgeEntry(t *testing.T) {
+	min, max := BlockPos{-2, -1, -3}, BlockPos{2, 3, 1}
+	tests := []struct {
+		name       string
+		start, end mgl64.Vec3
+		want       []BlockPos
+	}{
+		{
+			name:  "enters through an edge",
+			start: mgl64.Vec3{-2.5, 1, 4.5}, end: mgl64.Vec3{4.5, 1, -0.5},
+			want: []BlockPos{{0, 1, 1}, {1, 1, 1}, {2, 1, 1}, {2, 1, 0}},
+		},
+		{
+			name:  "touches the box at its end",
+			start: mgl64.Vec3{1.5, -3.75, 4.5}, end: mgl64.Vec3{-1.75, 0.5, 2},
+		},
+		{
+			name:  "starts inside",
+			start: mgl64.Vec3{0.5, 0.5, 0.5}, end: mgl64.Vec3{0.5, 0.5, -8},
+			want: []BlockPos{{0, 0, 0}, {0, 0, -1}, {0, 0, -2}, {0, 0, -3}},
+		},
+		{
+			name:  "misses the box",
+			start: mgl64.Vec3{5.5, 0.5, 0.5}, end: mgl64.Vec3{5.5, 8, 0.5},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := BetweenPointsInt(test.start, test.end, WithBounds(min, max))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+			all, _ := BetweenPointsInt(test.start, test.end)
+			if want := filterRegion(all, min, max); !reflect.DeepEqual(got, want) {
+				t.Errorf("got %v, filtered trace is %v", got, want)
+			}
+		})
+	}
+}
+
+func TestWithBoundsMatchesFilteredTrace(t *testing.T) {
+	min, max := BlockPos{-2, -1, -3}, BlockPos{2, 3, 1}
+	rng := rand.New(rand.NewSource(1))
+	for _, opts := range [][]Option{nil, {WithCeilOwnership()}, {WithBoundaryTowardDirection()}} {
+		for i := 0; i < 20000; i++ {
+			start, end := randomPoint(rng, 40), randomPoint(rng, 6)
+			if i%2 == 0 {
+				start, end = end, start
+			}
+			if start == end {
+				continue
+			}
+			got, err := BetweenPointsInt(start, end, append(opts, WithBounds(min, max))...)
+			if err != nil {
+				t.Fatalf("trace %v -> %v: unexpected error: %v", start, end, err)
+			}
+			all, _ := BetweenPointsInt(start, end, opts...)
+			if want := filterRegion(all, min, max); !reflect.DeepEqual(got, want) {
+				t.Fatalf("trace %v -> %v: got %v, filtered trace is %v", start, end, got, want)
+			}
+		}
+	}
+}
+
+func TestFirstSolidHitWithBounds(t *testing.T) {
+	min, max := BlockPos{-2, -1, -3}, BlockPos{2, 3, 1}
+	rng := rand.New(rand.NewSource(2))
+	g := NewSparseGrid()
+	o := NewOctree(BlockPos{-8, -8, -8}, 4)
+	for i := 0; i < 120; i++ {
+		x, y, z := rng.Intn(16)-8, rng.Intn(16)-8, rng.Intn(16)-8
+		g.Set(x, y, z, true)
+		o.Set(x, y, z, true)
+	}
+	for i := 0; i < 20000; i++ {
+		start, end := randomPoint(rng, 7), randomPoint(rng, 7)
+		if start == end {
+			continue
+		}
+		all, _ := BetweenPointsInt(start, end)
+		var (
+			want   BlockPos
+			wantOk bool
+		)
+		for _, pos := range filterRegion(all, min, max) {
+			if g.Solid(pos[0], pos[1], pos[2]) {
+				want, wantOk = pos, true
+				break
+			}
+		}
+		hit, ok, err := FirstSolidHit(g, start, end, WithBounds(min, max))
+		if err != nil || ok != wantOk || ok && hit.Pos != want {
+			t.Fatalf("trace %v -> %v: got %v, %v, %v, want %v, %v", start, end, hit.Pos, ok, err, want, wantOk)
+		}
+		hit, ok, err = o.FirstSolidHit(start, end, WithBounds(min, max))
+		if err != nil || ok != wantOk || ok && hit.Pos != want {
+			t.Fatalf("octree trace %v -> %v: got %v, %v, %v, want %v, %v", start, end, hit.Pos, ok, err, want, wantOk)
+		}
+	}
+}
+
+// randomPoint returns a random point with coordinates between -scale and scale. A third of the coordinates are
+// rounded to a multiple of a quarter, so that rays often start, end or travel exactly on voxel boundaries.
+func randomPoint(rng *rand.Rand, scale float64) mgl64.Vec3 {
+	var p mgl64.Vec3
+	for i := range p {
+		p[i] = rng.Float64()*2*scale - scale
+		if rng.Intn(3) == 0 {
+			p[i] = float64(int(p[i]*4)) / 4
+		}
+	}
+	return p
+}
+
+// walledGrid is a BoundedGrid of which every voxel outside the bounds is solid, which must never be hit.
+type walledGrid struct {
+	*ArrayGrid
+	min, max BlockPos
+}
+
+// Solid reports if the voxel at the position passed is solid, which all voxels outside the bounds are.
+func (g walledGrid) Solid(x, y, z int) bool {
+	return !insideRegion(BlockPos{x, y, z}, g.min, g.max) || g.ArrayGrid.Solid(x, y, z)
+}
+
+// Bounds returns the bounds of the grid.
+func (g walledGrid) Bounds() (min, max BlockPos) {
+	return g.min, g.max
+}
+
+func TestBoundedGrid(t *testing.T) {
+	min, max := BlockPos{0, 0, 0}, BlockPos{7, 7, 7}
+	g := walledGrid{ArrayGrid: NewArrayGrid(min, 8, 8, 8), min: min, max: max}
+	g.Set(5, 2, 2, true)
+
+	t.Run("start outside", func(t *testing.T) {
+		start := mgl64.Vec3{-1.5, 2.5, 2.5}
+		_, _, err := FirstSolidHit(g, start, mgl64.Vec3{9.5, 2.5, 2.5})
+		if !errors.Is(err, ErrOutOfBounds) || err != (OutOfBoundsError{Pos: BlockPos{-2, 2, 2}}) {
+			t.Errorf("got %v, want the start voxel out of bounds", err)
+		}
+		if _, err := SolidHits(g, start, mgl64.Vec3{9.5, 2.5, 2.5}); !errors.Is(err, ErrOutOfBounds) {
+			t.Errorf("got %v from SolidHits, want %v", err, ErrOutOfBounds)
+		}
+	})
+	t.Run("exit mid-ray", func(t *testing.T) {
+		// The ray leaves the grid through its top, above which every voxel is solid, before reaching voxel (5, 2, 2).
+		hit, ok, err := FirstSolidHit(g, mgl64.Vec3{1.5, 6.5, 2.5}, mgl64.Vec3{5.5, 12.5, 2.5})
+		if err != nil || ok {
+			t.Errorf("got %v, %v, %v, want no hit", hit, ok, err)
+		}
+		hits, err := SolidHits(g, mgl64.Vec3{0.5, 2.5, 2.5}, mgl64.Vec3{12.5, 2.5, 2.5})
+		if want := []BlockPos{{5, 2, 2}}; err != nil || !reflect.DeepEqual(hitPositions(hits), want) {
+			t.Errorf("got %v, %v, want only %v", hitPositions(hits), err, want)
+		}
+	})
+	t.Run("inside", func(t *testing.T) {
+		hit, ok, err := FirstSolidHit(g, mgl64.Vec3{0.5, 2.5, 2.5}, mgl64.Vec3{7.5, 2.5, 2.5})
+		if err != nil || !ok || hit.Pos != (BlockPos{5, 2, 2}) {
+			t.Errorf("got %v, %v, %v, want a hit at %v", hit, ok, err, BlockPos{5, 2, 2})
+		}
+	})
+}
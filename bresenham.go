@@ -0,0 +1,46 @@
+package voxelraytrace
+
+// Bresenham returns the positions of the voxels on the line between the voxels a and b, both inclusive, computed
+// using the 3D variant of Bresenham's line algorithm. Unlike BetweenPointsInt, which returns every voxel the ray
+// passes through, the line holds exactly one voxel for every step along the axis on which a and b are furthest apart,
+// so consecutive voxels may only share an edge or corner. This is the connectivity wanted for drawing voxel lines.
+// Only integer arithmetic is used.
+func Bresenham(a, b BlockPos) []BlockPos {
+	var d, step [3]int
+	major := 0
+	for i := 0; i < 3; i++ {
+		d[i], step[i] = absInt(b[i]-a[i]), 1
+		if b[i] < a[i] {
+			step[i] = -1
+		}
+		if d[i] > d[major] {
+			major = i
+		}
+	}
+	n := d[major]
+	positions := make([]BlockPos, 0, n+1)
+	// The error terms of the minor axes start at half of a step along the major axis, so that the line is rounded to
+	// the nearest voxel rather than down.
+	var errs [3]int
+	for i := range errs {
+		errs[i] = 2*d[i] - n
+	}
+	pos := a
+	for {
+		positions = append(positions, pos)
+		if len(positions) > n {
+			return positions
+		}
+		for i := 0; i < 3; i++ {
+			if i == major {
+				pos[i] += step[i]
+				continue
+			}
+			if errs[i] > 0 {
+				pos[i] += step[i]
+				errs[i] -= 2 * n
+			}
+			errs[i] += 2 * d[i]
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package voxelraytrace
+
+import (
+	"errors"
+	"github.com/go-gl/mathgl/mgl64"
+	"math"
+)
+
+// ScreenRay creates the Ray passing through the point on the screen at the normalised device coordinates x and y,
+// both from -1 to 1 with (-1, -1) being the bottom left corner, for a camera with the view and projection matrices
+// passed, such as those created using mgl64.LookAtV and mgl64.Perspective. The Ray starts on the near plane of the
+// projection and travels for the maxDistance, so it may be traced using Ray.Cast or Ray.FirstHit to pick the voxel
+// under the cursor. An error is returned if the matrices cannot be inverted or if maxDistance is not positive.
+func ScreenRay(view, projection mgl64.Mat4, x, y, maxDistance float64) (Ray, error) {
+	m := projection.Mul4(view)
+	if m.Det() == 0 {
+		return Ray{}, errors.New("view projection matrix is not invertible")
+	}
+	inv := m.Inv()
+	near, far := inv.Mul4x1(mgl64.Vec4{x, y, -1, 1}), inv.Mul4x1(mgl64.Vec4{x, y, 1, 1})
+	if near[3] == 0 || far[3] == 0 {
+		return Ray{}, errors.New("view projection matrix is not invertible")
+	}
+	origin := near.Vec3().Mul(1 / near[3])
+	return NewRay(origin, far.Vec3().Mul(1/far[3]).Sub(origin), maxDistance)
+}
+
+// CameraRay creates the Ray passing through the point on the screen at the normalised device coordinates x and y,
+// both from -1 to 1 with (-1, -1) being the bottom left corner, for a camera at the eye position passed, looking in
+// the direction of the yaw and pitch in degrees passed, as used by DirectionFromRotation. fovY is the vertical field
+// of view of the camera in degrees and aspect the width of the screen divided by its height. The Ray starts at the
+// eye position and travels for the maxDistance. The centre of the screen, (0, 0), gives the same direction as
+// DirectionFromRotation. An error is returned if maxDistance is not positive.
+func CameraRay(eyePos mgl64.Vec3, yaw, pitch, fovY, aspect, x, y, maxDistance float64) (Ray, error) {
+	forward := DirectionFromRotation(yaw, pitch)
+	// The right vector only depends on the yaw, so that it remains defined when looking straight up or down.
+	yawRad := mgl64.DegToRad(yaw)
+	right := mgl64.Vec3{-math.Cos(yawRad), 0, -math.Sin(yawRad)}
+	up := right.Cross(forward)
+
+	halfHeight := math.Tan(mgl64.DegToRad(fovY) / 2)
+	dir := forward.Add(right.Mul(x * halfHeight * aspect)).Add(up.Mul(y * halfHeight))
+	return NewRay(eyePos, dir, maxDistance)
+}
@@ -0,0 +1,72 @@
+package voxelraytrace
+
+import (
+	"github.com/go-gl/mathgl/mgl64"
+	"math"
+)
+
+// ChordLength returns the length of the part of the segment between the start and end coordinates that lies inside
+// the voxel passed, spanning from voxel to voxel+(1, 1, 1). Unlike BetweenPoints, it computes the length directly
+// using a ray-box intersection, so the cost does not depend on how far along the ray the voxel is. If the segment
+// does not pass through the voxel, 0 is returned.
+func ChordLength(start, end, voxel mgl64.Vec3) (float64, error) {
+	diff := end.Sub(start)
+	if diff.LenSqr() <= 0 {
+		return 0, ErrZeroDirection
+	}
+	tEntry, tExit, ok := intersectBox(start, diff, voxel, voxel.Add(mgl64.Vec3{1, 1, 1}))
+	if !ok {
+		return 0, nil
+	}
+	tEntry, tExit = math.Max(tEntry, 0), math.Min(tExit, 1)
+	if tEntry >= tExit {
+		return 0, nil
+	}
+	return (tExit - tEntry) * diff.Len(), nil
+}
+
+// intersectBox intersects the line origin+dir*t with the box spanning from min to max using the slab method. It
+// returns the values of t at which the line enters and leaves the box. If the line does not intersect the box, false
+// is returned.
+func intersectBox(origin, dir, min, max mgl64.Vec3) (tEntry, tExit float64, ok bool) {
+	tEntry, tExit = math.Inf(-1), math.Inf(1)
+	for i := 0; i < 3; i++ {
+		if dir[i] == 0 {
+			if origin[i] < min[i] || origin[i] > max[i] {
+				return 0, 0, false
+			}
+			continue
+		}
+		t1, t2 := (min[i]-origin[i])/dir[i], (max[i]-origin[i])/dir[i]
+		if t1 > t2 {
+			t1, t2 = t2, t1
+		}
+		tEntry, tExit = math.Max(tEntry, t1), math.Min(tExit, t2)
+	}
+	return tEntry, tExit, tEntry <= tExit
+}
+
+// IntersectVoxelAABB intersects the ray starting at rayOrigin in the direction rayDir with the unit voxel spanning
+// from voxelPos to voxelPos+(1, 1, 1) using the slab method, without traversing any voxels. It returns the distances
+// along the ray, in multiples of rayDir, at which the ray enters and leaves the voxel. A negative tEntry means that
+// the origin lies inside the voxel. If the ray misses the voxel or the voxel lies entirely behind the origin, hit is
+// false. If rayDir is normalised, tExit-tEntry is the length of the chord through the voxel.
+func IntersectVoxelAABB(rayOrigin, rayDir, voxelPos mgl64.Vec3) (tEntry, tExit float64, hit bool) {
+	tEntry, tExit = math.Inf(-1), math.Inf(1)
+	for i := 0; i < 3; i++ {
+		rel := voxelPos[i] - rayOrigin[i]
+		if rayDir[i] == 0 {
+			if rel > 0 || rel < -1 {
+				return 0, 0, false
+			}
+			continue
+		}
+		inv := 1 / rayDir[i]
+		t1, t2 := rel*inv, (rel+1)*inv
+		if t1 > t2 {
+			t1, t2 = t2, t1
+		}
+		tEntry, tExit = math.Max(tEntry, t1), math.Min(tExit, t2)
+	}
+	return tEntry, tExit, tEntry <= tExit && tExit >= 0
+}
@@ -0,0 +1,62 @@
+package voxelraytrace
+
+import (
+	"github.com/go-gl/mathgl/mgl64"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestChordLength(t *testing.T) {
+	tests := []struct {
+		name              string
+		start, end, voxel mgl64.Vec3
+		want              float64
+	}{
+		{name: "axis aligned", start: mgl64.Vec3{0.5, 0.5, 0.5}, end: mgl64.Vec3{10.5, 0.5, 0.5}, voxel: mgl64.Vec3{7, 0, 0}, want: 1},
+		{name: "axis aligned start", start: mgl64.Vec3{0.5, 0.5, 0.5}, end: mgl64.Vec3{10.5, 0.5, 0.5}, voxel: mgl64.Vec3{0, 0, 0}, want: 0.5},
+		{name: "axis aligned end", start: mgl64.Vec3{0.5, 0.5, 0.5}, end: mgl64.Vec3{10.25, 0.5, 0.5}, voxel: mgl64.Vec3{10, 0, 0}, want: 0.25},
+		{name: "axis aligned miss", start: mgl64.Vec3{0.5, 0.5, 0.5}, end: mgl64.Vec3{10.5, 0.5, 0.5}, voxel: mgl64.Vec3{7, 1, 0}},
+		{name: "beyond end", start: mgl64.Vec3{0.5, 0.5, 0.5}, end: mgl64.Vec3{4.5, 0.5, 0.5}, voxel: mgl64.Vec3{7, 0, 0}},
+		{name: "diagonal", start: mgl64.Vec3{0, 0, 0}, end: mgl64.Vec3{8, 8, 8}, voxel: mgl64.Vec3{5, 5, 5}, want: math.Sqrt(3)},
+		{name: "diagonal face", start: mgl64.Vec3{0, 0.5, 0}, end: mgl64.Vec3{8, 0.5, 8}, voxel: mgl64.Vec3{3, 0, 3}, want: math.Sqrt2},
+		{name: "corner clip", start: mgl64.Vec3{0, 0.9, 0.5}, end: mgl64.Vec3{0.2, 1.1, 0.5}, voxel: mgl64.Vec3{0, 0, 0}, want: math.Sqrt2 * 0.1},
+		{name: "corner touch", start: mgl64.Vec3{0, 2, 0.5}, end: mgl64.Vec3{2, 0, 0.5}, voxel: mgl64.Vec3{0, 0, 0}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := ChordLength(test.start, test.end, test.voxel)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if math.Abs(got-test.want) > 1e-9 {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+	if _, err := ChordLength(mgl64.Vec3{1, 2, 3}, mgl64.Vec3{1, 2, 3}, mgl64.Vec3{1, 2, 3}); err != ErrZeroDirection {
+		t.Errorf("got %v for a zero length segment, want %v", err, ErrZeroDirection)
+	}
+}
+
+func TestChordLengthSumsToLength(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 2000; i++ {
+		start, end := randomPoint(rng, 8), randomPoint(rng, 8)
+		positions, err := BetweenPoints(start, end)
+		if err != nil {
+			continue
+		}
+		var sum float64
+		for _, voxel := range positions {
+			l, err := ChordLength(start, end, voxel)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			sum += l
+		}
+		if want := end.Sub(start).Len(); math.Abs(sum-want) > 1e-9 {
+			t.Fatalf("trace %v -> %v: chord lengths sum to %v, want %v", start, end, sum, want)
+		}
+	}
+}
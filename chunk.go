@@ -0,0 +1,60 @@
+package voxelraytrace
+
+import (
+	"errors"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// ChunksAlongRay performs a ray trace between the start and end coordinates on a grid of chunks, each chunkSize
+// voxels wide on every axis. It returns the chunk coordinates, being the voxel coordinates floor-divided by the
+// chunkSize, of all chunks the ray passes through, in the order they are passed through.
+func ChunksAlongRay(start, end mgl64.Vec3, chunkSize int) ([]mgl64.Vec3, error) {
+	if chunkSize <= 0 {
+		return nil, errors.New("chunk size must be positive")
+	}
+	size := float64(chunkSize)
+	return BetweenPoints(
+		mgl64.Vec3{start[0] / size, start[1] / size, start[2] / size},
+		mgl64.Vec3{end[0] / size, end[1] / size, end[2] / size},
+	)
+}
+
+// UniqueChunksAlongRay performs a ray trace like ChunksAlongRay, but removes any chunk coordinates that were already
+// passed through earlier in the trace, so that every chunk is only returned once.
+func UniqueChunksAlongRay(start, end mgl64.Vec3, chunkSize int) ([]mgl64.Vec3, error) {
+	chunks, err := ChunksAlongRay(start, end, chunkSize)
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[mgl64.Vec3]struct{}, len(chunks))
+	unique := chunks[:0]
+	for _, chunk := range chunks {
+		if _, ok := seen[chunk]; ok {
+			continue
+		}
+		seen[chunk] = struct{}{}
+		unique = append(unique, chunk)
+	}
+	return unique, nil
+}
+
+// SectionPos is the position of a section of 16x16x16 voxels, being the voxel coordinates floor-divided by 16.
+type SectionPos [3]int
+
+// Sections performs a ray trace between the start and end coordinates on a grid of sections of 16x16x16 voxels and
+// returns the positions of the sections it passes through, in the order they are passed through, such as to load or
+// lock the chunks needed before tracing the voxels themselves. The trace is the same as that of BetweenPointsInt
+// with WithCellSize(16, 16, 16), so the sections returned are exactly those holding the voxels returned by
+// BetweenPointsInt for the same ray, in the same order. Options limiting the voxels passed through, such as
+// WithXRange, are in section coordinates.
+func Sections(start, end mgl64.Vec3, opts ...Option) ([]SectionPos, error) {
+	positions, err := BetweenPointsInt(start, end, append(opts[:len(opts):len(opts)], WithCellSize(16, 16, 16))...)
+	if positions == nil {
+		return nil, err
+	}
+	sections := make([]SectionPos, len(positions))
+	for i, pos := range positions {
+		sections[i] = SectionPos(pos)
+	}
+	return sections, err
+}
@@ -0,0 +1,45 @@
+package voxelraytrace
+
+// ChunkedGrid is a Grid that knows which chunks of 16x16x16 voxels hold no solid voxels at all, such as a world that
+// keeps track of empty chunk sections. FirstSolidHit and SolidHits skip over every empty chunk the ray enters in one
+// go, rather than checking every voxel in it, which makes long rays through air much cheaper, while the hits found
+// are the same. Voxels skipped do not count towards WithMaxVoxels and are not passed to the hook set using
+// WithStepHook, while WithMaxManhattan disables the skipping.
+type ChunkedGrid interface {
+	Grid
+	// ChunkEmpty returns true if none of the voxels in the chunk at the chunk coordinates passed, which are the voxel
+	// coordinates floor-divided by 16, are solid.
+	ChunkEmpty(cx, cy, cz int) bool
+}
+
+// chunkSkipper skips a tracer over the empty chunks of a ChunkedGrid, keeping whether the chunk last checked is
+// empty, so that ChunkEmpty is only called once the ray enters another chunk.
+type chunkSkipper struct {
+	g       ChunkedGrid
+	chunk   SectionPos
+	checked bool
+	empty   bool
+}
+
+// newChunkSkipper creates a chunkSkipper for the Grid passed. If it does not implement ChunkedGrid, the chunkSkipper
+// never skips anything.
+func newChunkSkipper(g Grid) chunkSkipper {
+	cg, _ := g.(ChunkedGrid)
+	return chunkSkipper{g: cg}
+}
+
+// skip moves the tracer through the chunk holding the voxel it is at, if that chunk is empty, leaving it in the last
+// voxel of the chunk passed through, as done by tracer.skipEmpty.
+func (s *chunkSkipper) skip(t *tracer) {
+	if s.g == nil || t.wrapped {
+		return
+	}
+	chunk := SectionPos{t.x >> 4, t.y >> 4, t.z >> 4}
+	if !s.checked || chunk != s.chunk {
+		s.chunk, s.checked, s.empty = chunk, true, s.g.ChunkEmpty(chunk[0], chunk[1], chunk[2])
+	}
+	if s.empty {
+		min := BlockPos{chunk[0] << 4, chunk[1] << 4, chunk[2] << 4}
+		t.skipEmpty(min, min.Add(BlockPos{15, 15, 15}))
+	}
+}
@@ -0,0 +1,69 @@
+package voxelraytrace
+
+import (
+	"github.com/go-gl/mathgl/mgl64"
+	"math"
+)
+
+// ClosestVoxelToPoint performs a ray trace between the start and end coordinates and returns the voxel passed
+// through of which the centre is closest to the target point, along with the distance between the two. If multiple
+// voxels are equally close, the one passed through first is returned. The voxels are compared as they are passed
+// through, without collecting them.
+func ClosestVoxelToPoint(start, end, target mgl64.Vec3, opts ...Option) (pos BlockPos, dist float64, err error) {
+	t, err := newTracer(start, end, newConfig(opts))
+	if err != nil {
+		return BlockPos{}, 0, err
+	}
+	pos, distSqr := t.pos(), t.world(t.rawPos().Vec3Centre()).Sub(target).LenSqr()
+	for t.next() {
+		if d := t.world(t.rawPos().Vec3Centre()).Sub(target).LenSqr(); d < distSqr {
+			pos, distSqr = t.pos(), d
+		}
+	}
+	return pos, math.Sqrt(distSqr), t.err
+}
+
+// DistanceToFirst performs a ray trace between the start and end coordinates and returns the distance from the start
+// to the point at which the ray enters the first voxel for which pred returns true. pred is passed voxels in the
+// vector representation returned by BetweenPoints. If the ray starts inside a matching voxel, the distance is 0. If
+// no voxel matches, false is returned. The trace stops at the first match and does not allocate.
+func DistanceToFirst(start, end mgl64.Vec3, pred func(mgl64.Vec3) bool, opts ...Option) (float64, bool, error) {
+	t, err := newTracer(start, end, newConfig(opts))
+	if err != nil {
+		return 0, false, err
+	}
+	for {
+		if pred(t.pos().Vec3Min()) {
+			return t.t, true, nil
+		}
+		if !t.next() {
+			return 0, false, t.err
+		}
+	}
+}
+
+// InDirectionDistanceToFirst performs a ray trace from the start position in the given direction, for a distance of
+// the maxDistance, and returns the distance to the first voxel for which pred returns true like DistanceToFirst.
+func InDirectionDistanceToFirst(start, directionVector mgl64.Vec3, maxDistance float64, pred func(mgl64.Vec3) bool, opts ...Option) (float64, bool, error) {
+	return DistanceToFirst(start, along(start, directionVector, maxDistance), pred, opts...)
+}
+
+// InDirectionUntil performs a ray trace from the start position in the given direction, for a distance of the
+// maxDistance, and returns the first voxel for which pred returns true, along with the distance from the start to the
+// point at which the ray entered it. The voxel the ray starts in is passed to pred too, in which case the distance
+// is 0. If no voxel matches, found is false and err is nil, unless the trace was stopped early by an Option. The
+// trace does not allocate.
+func InDirectionUntil(start, directionVector mgl64.Vec3, maxDistance float64, pred func(mgl64.Vec3) bool, opts ...Option) (voxel mgl64.Vec3, dist float64, found bool, err error) {
+	t, err := newTracer(start, along(start, directionVector, maxDistance), newConfig(opts))
+	if err != nil {
+		return mgl64.Vec3{}, 0, false, err
+	}
+	for {
+		if v := t.pos().Vec3Min(); pred(v) {
+			return v, t.t, true, nil
+		}
+		if !t.next() {
+			return mgl64.Vec3{}, 0, false, t.err
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package voxelraytrace
+
+import (
+	"github.com/go-gl/mathgl/mgl64"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestClosestVoxelToPointMatchesPath(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 5000; i++ {
+		start, end, target := randomPoint(rng, 8), randomPoint(rng, 8), randomPoint(rng, 8)
+		positions, err := BetweenPointsInt(start, end)
+		if err != nil {
+			continue
+		}
+		want, wantDist := positions[0], math.Inf(1)
+		for _, pos := range positions {
+			if d := pos.Vec3Centre().Sub(target).Len(); d < wantDist {
+				want, wantDist = pos, d
+			}
+		}
+		got, dist, err := ClosestVoxelToPoint(start, end, target)
+		if err != nil || got != want || math.Abs(dist-wantDist) > 1e-9 {
+			t.Fatalf("trace %v -> %v to %v: got %v, %v, %v, want %v, %v", start, end, target, got, dist, err, want, wantDist)
+		}
+	}
+}
+
+func TestClosestVoxelToPoint(t *testing.T) {
+	tests := []struct {
+		name               string
+		start, end, target mgl64.Vec3
+		want               BlockPos
+		dist               float64
+	}{
+		{name: "on path", start: mgl64.Vec3{0.5, 0.5, 0.5}, end: mgl64.Vec3{10.5, 0.5, 0.5}, target: mgl64.Vec3{4.5, 0.5, 0.5}, want: BlockPos{4, 0, 0}},
+		{name: "beside path", start: mgl64.Vec3{0.5, 0.5, 0.5}, end: mgl64.Vec3{10.5, 0.5, 0.5}, target: mgl64.Vec3{6.5, 3.5, 0.5}, want: BlockPos{6, 0, 0}, dist: 3},
+		{name: "beyond end", start: mgl64.Vec3{0.5, 0.5, 0.5}, end: mgl64.Vec3{10.5, 0.5, 0.5}, target: mgl64.Vec3{14.5, 0.5, 0.5}, want: BlockPos{10, 0, 0}, dist: 4},
+		// The target is as close to the centres of voxels 4 and 5, so the voxel passed through first is returned.
+		{name: "tie forwards", start: mgl64.Vec3{0.5, 0.5, 0.5}, end: mgl64.Vec3{10.5, 0.5, 0.5}, target: mgl64.Vec3{5, 2.5, 0.5}, want: BlockPos{4, 0, 0}, dist: math.Sqrt(4.25)},
+		{name: "tie backwards", start: mgl64.Vec3{10.5, 0.5, 0.5}, end: mgl64.Vec3{0.5, 0.5, 0.5}, target: mgl64.Vec3{5, 2.5, 0.5}, want: BlockPos{5, 0, 0}, dist: math.Sqrt(4.25)},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, dist, err := ClosestVoxelToPoint(test.start, test.end, test.target)
+			if err != nil || got != test.want || math.Abs(dist-test.dist) > 1e-9 {
+				t.Errorf("got %v, %v, %v, want %v, %v", got, dist, err, test.want, test.dist)
+			}
+		})
+	}
+	if _, _, err := ClosestVoxelToPoint(mgl64.Vec3{1, 2, 3}, mgl64.Vec3{1, 2, 3}, mgl64.Vec3{}); err != ErrZeroDirection {
+		t.Errorf("got %v for a zero length ray, want %v", err, ErrZeroDirection)
+	}
+	start, end, target := mgl64.Vec3{0.2, 0.3, 0.1}, mgl64.Vec3{30.9, 20.8, 10.7}, mgl64.Vec3{5, 9, 2}
+	if n := testing.AllocsPerRun(100, func() { _, _, _ = ClosestVoxelToPoint(start, end, target) }); n != 0 {
+		t.Errorf("got %v allocations, want 0", n)
+	}
+}
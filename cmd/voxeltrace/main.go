@@ -0,0 +1,158 @@
+// Command voxeltrace traces a ray through a voxel grid and prints every voxel passed through, along with the face
+// through which it was entered and the distance along the ray at which that happened. It is meant to be used to
+// reproduce and report unexpected traversals.
+//
+// Usage:
+//
+//	voxeltrace -from x,y,z -to x,y,z [-format text|json|csv|obj]
+//	voxeltrace -from x,y,z -dir x,y,z -dist d [-format text|json|csv|obj]
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/go-gl/mathgl/mgl64"
+	"github.com/justtaldevelops/voxelraytrace"
+	"github.com/justtaldevelops/voxelraytrace/tracedebug"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+func main() {
+	from := flag.String("from", "", "start of the ray as x,y,z")
+	to := flag.String("to", "", "end of the ray as x,y,z")
+	dir := flag.String("dir", "", "direction of the ray as x,y,z, used with -dist instead of -to")
+	dist := flag.Float64("dist", 0, "length of the ray in the direction of -dir, which need not be normalised")
+	format := flag.String("format", "text", "output format: text, json, csv or obj")
+	maxVoxels := flag.Int("max-voxels", 0, "maximum amount of voxels to trace, or 0 for no limit")
+	flag.Parse()
+
+	if err := run(os.Stdout, *from, *to, *dir, *dist, *format, *maxVoxels); err != nil {
+		// Errors of the command itself already start with their label, which is not repeated.
+		msg := err.Error()
+		if name := errorName(err); !strings.HasPrefix(msg, name+":") {
+			msg = name + ": " + msg
+		}
+		fmt.Fprintf(os.Stderr, "voxeltrace: %v\n", msg)
+		os.Exit(1)
+	}
+}
+
+// errInvalidInput is returned for flags that are missing or cannot be parsed.
+var errInvalidInput = errors.New("invalid input")
+
+// run performs the trace described by the flags and writes the result to w in the format passed.
+func run(w io.Writer, from, to, dir string, dist float64, format string, maxVoxels int) error {
+	start, err := parseVec3("-from", from)
+	if err != nil {
+		return err
+	}
+	var end mgl64.Vec3
+	switch {
+	case to != "" && dir != "":
+		return fmt.Errorf("%w: -to and -dir may not both be set", errInvalidInput)
+	case to != "":
+		if end, err = parseVec3("-to", to); err != nil {
+			return err
+		}
+	case dir != "":
+		d, err := parseVec3("-dir", dir)
+		if err != nil {
+			return err
+		}
+		if !(dist > 0) {
+			return fmt.Errorf("%w: -dist must be positive", errInvalidInput)
+		}
+		// The end is computed by the Ray, which normalises the direction and rounds the same way on every platform,
+		// like the traces of the package, so that the same flags give the same output everywhere.
+		ray, err := voxelraytrace.NewRay(start, d, dist)
+		if err != nil {
+			return err
+		}
+		end = ray.End()
+	default:
+		return fmt.Errorf("%w: either -to or -dir must be set", errInvalidInput)
+	}
+
+	var opts []voxelraytrace.Option
+	if maxVoxels > 0 {
+		opts = append(opts, voxelraytrace.WithMaxVoxels(maxVoxels))
+	}
+	trace, traceErr := tracedebug.Record(start, end, opts...)
+	if trace.Steps == nil {
+		return traceErr
+	}
+	switch format {
+	case "text":
+		writeText(w, trace.Steps)
+	case "json":
+		if err := trace.WriteJSON(w); err != nil {
+			return err
+		}
+	case "csv":
+		writeCSV(w, trace.Steps)
+	case "obj":
+		if err := trace.WriteOBJ(w); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("%w: unknown format %q", errInvalidInput, format)
+	}
+	return traceErr
+}
+
+// parseVec3 parses a vector in the form x,y,z passed to the flag with the name passed.
+func parseVec3(name, s string) (mgl64.Vec3, error) {
+	parts := strings.Split(s, ",")
+	if len(parts) != 3 {
+		return mgl64.Vec3{}, fmt.Errorf("%w: %v must be of the form x,y,z, got %q", errInvalidInput, name, s)
+	}
+	var v mgl64.Vec3
+	for i, part := range parts {
+		f, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return mgl64.Vec3{}, fmt.Errorf("%w: %v: %v", errInvalidInput, name, err)
+		}
+		v[i] = f
+	}
+	return v, nil
+}
+
+// errorName returns the name of the sentinel error of the package matched by the error passed, so that it may be
+// looked up in the documentation of the package. Errors of the command itself, such as for invalid flags, are given a
+// plain label instead.
+func errorName(err error) string {
+	switch {
+	case errors.Is(err, errInvalidInput):
+		return "invalid input"
+	case errors.Is(err, voxelraytrace.ErrMaxVoxelsExceeded):
+		return "ErrMaxVoxelsExceeded"
+	case errors.Is(err, voxelraytrace.ErrTimeBudgetExceeded):
+		return "ErrTimeBudgetExceeded"
+	case errors.Is(err, voxelraytrace.ErrOutOfBounds):
+		return "ErrOutOfBounds"
+	case errors.Is(err, voxelraytrace.ErrZeroDirection):
+		return "ErrZeroDirection"
+	case errors.Is(err, voxelraytrace.ErrNonFiniteInput):
+		return "ErrNonFiniteInput"
+	}
+	return "error"
+}
+
+// writeText writes the steps passed as a human readable table.
+func writeText(w io.Writer, steps []voxelraytrace.StepInfo) {
+	for _, s := range steps {
+		fmt.Fprintf(w, "%d %d %d\tface=%v\tt=%v\n", s.Voxel[0], s.Voxel[1], s.Voxel[2], s.EntryFace, s.TEntry)
+	}
+}
+
+// writeCSV writes the steps passed as CSV with a header row.
+func writeCSV(w io.Writer, steps []voxelraytrace.StepInfo) {
+	fmt.Fprintln(w, "x,y,z,face,t")
+	for _, s := range steps {
+		fmt.Fprintf(w, "%d,%d,%d,%v,%v\n", s.Voxel[0], s.Voxel[1], s.Voxel[2], s.EntryFace, s.TEntry)
+	}
+}
@@ -0,0 +1,64 @@
+package voxelraytrace
+
+import (
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// VoxelCount performs a ray trace between the start and end coordinates and returns the amount of voxels passed
+// through, which is the length of the slice that BetweenPoints returns, without collecting the voxels. If the trace
+// is stopped early by one of the Options passed, the amount of voxels passed through so far is returned along with
+// the error.
+func VoxelCount(start, end mgl64.Vec3, opts ...Option) (int, error) {
+	conf := newConfig(opts)
+	t, err := newTracer(start, end, conf)
+	if err != nil {
+		return 0, err
+	}
+	if !t.enter(conf) {
+		return 0, t.err
+	}
+	if _, steps, ok := t.aligned(); ok {
+		return steps + 1, nil
+	}
+	n := 0
+	if conf.supercover {
+		t.supercover(func(BlockPos) {
+			n++
+		})
+		return n, t.err
+	}
+	for n = 1; t.next(); n++ {
+	}
+	return n, t.err
+}
+
+// IntersectsAny performs a ray trace between the start and end coordinates and checks if solid returns true for any
+// of the voxels passed through, which are the same as those returned by BetweenPoints and are passed in the same
+// representation. Unless WithSupercover is passed, the trace stops at the first voxel for which solid returns true
+// and does not allocate. If the trace is stopped early by one of the Options passed before such a voxel was found,
+// false is returned along with the error.
+func IntersectsAny(start, end mgl64.Vec3, solid func(v mgl64.Vec3) bool, opts ...Option) (bool, error) {
+	conf := newConfig(opts)
+	t, err := newTracer(start, end, conf)
+	if err != nil {
+		return false, err
+	}
+	if !t.enter(conf) {
+		return false, t.err
+	}
+	if conf.supercover {
+		found := false
+		t.supercover(func(pos BlockPos) {
+			found = found || solid(pos.Vec3Min())
+		})
+		return found, t.err
+	}
+	for {
+		if solid(t.pos().Vec3Min()) {
+			return true, nil
+		}
+		if !t.next() {
+			return false, t.err
+		}
+	}
+}
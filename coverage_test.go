@@ -0,0 +1,108 @@
+package voxelraytrace
+
+import (
+	"github.com/go-gl/mathgl/mgl64"
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+// monteCarloCoverage estimates the fraction of the voxel passed inside the capsule of the radius passed around the
+// segment between the start and end, using the amount of random points passed.
+func monteCarloCoverage(rng *rand.Rand, pos BlockPos, start, end mgl64.Vec3, radius float64, points int) float64 {
+	inside := 0
+	for i := 0; i < points; i++ {
+		p := pos.Vec3Min().Add(mgl64.Vec3{rng.Float64(), rng.Float64(), rng.Float64()})
+		if distanceToSegment(p, start, end) <= radius {
+			inside++
+		}
+	}
+	return float64(inside) / float64(points)
+}
+
+func TestCoverageMatchesMonteCarlo(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 4; i++ {
+		start, end := randomPoint(rng, 4), randomPoint(rng, 4)
+		radius := 0.2 + rng.Float64()*1.3
+		voxels, err := CoverageBetweenPoints(start, end, radius, WithCoverageSamples(8))
+		if err != nil {
+			t.Fatalf("trace %v -> %v: unexpected error: %v", start, end, err)
+		}
+		weights := make(map[BlockPos]float64)
+		for _, v := range voxels {
+			if v.Weight <= 0 || v.Weight > 1 {
+				t.Fatalf("trace %v -> %v: weight %v of %v out of range", start, end, v.Weight, v.Pos)
+			}
+			weights[v.Pos] = v.Weight
+		}
+		// Every voxel near the capsule is compared, so that voxels missing from the result are found as well.
+		r := int(math.Ceil(radius)) + 1
+		min, max := BlockPosFromVec3(start), BlockPosFromVec3(end)
+		for a := 0; a < 3; a++ {
+			if min[a] > max[a] {
+				min[a], max[a] = max[a], min[a]
+			}
+		}
+		for x := min[0] - r; x <= max[0]+r; x++ {
+			for y := min[1] - r; y <= max[1]+r; y++ {
+				for z := min[2] - r; z <= max[2]+r; z++ {
+					pos := BlockPos{x, y, z}
+					want := monteCarloCoverage(rng, pos, start, end, radius, 2000)
+					// The error of 512 samples on a regular grid and of the Monte Carlo estimate together stay well
+					// below 0.06.
+					if got := weights[pos]; math.Abs(got-want) > 0.06 {
+						t.Fatalf("trace %v -> %v with radius %v: got weight %v for %v, want about %v", start, end, radius, got, pos, want)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestCoverageRadiusZero(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	for i := 0; i < 1000; i++ {
+		start, end := randomPoint(rng, 8), randomPoint(rng, 8)
+		voxels, err := CoverageBetweenPoints(start, end, 0)
+		if err != nil {
+			continue
+		}
+		// The weights are the lengths of the parts of the ray inside the voxels, divided by the square root of 3.
+		var sum float64
+		for _, v := range voxels {
+			l, _ := ChordLength(start, end, v.Pos.Vec3Min())
+			if math.Abs(v.Weight*math.Sqrt(3)-l) > 1e-9 {
+				t.Fatalf("trace %v -> %v: got weight %v for %v, want %v", start, end, v.Weight, v.Pos, l/math.Sqrt(3))
+			}
+			sum += v.Weight
+		}
+		if want := end.Sub(start).Len(); math.Abs(sum*math.Sqrt(3)-want) > 1e-9 {
+			t.Fatalf("trace %v -> %v: weights sum to %v, want %v", start, end, sum*math.Sqrt(3), want)
+		}
+	}
+}
+
+func TestCoverageBetweenPoints(t *testing.T) {
+	start, end := mgl64.Vec3{0.5, 0.5, 0.5}, mgl64.Vec3{6.5, 0.5, 0.5}
+	a, err := CoverageBetweenPoints(start, end, 0.8)
+	b, _ := CoverageBetweenPoints(start, end, 0.8)
+	if err != nil || !reflect.DeepEqual(a, b) {
+		t.Fatalf("got %v, %v, then %v, want the same weights twice", a, err, b)
+	}
+	// The ray runs through the centres of the voxels along it, which lie completely inside the capsule.
+	for _, v := range a {
+		if v.Pos[1] == 0 && v.Pos[2] == 0 && v.Pos[0] >= 1 && v.Pos[0] <= 5 && v.Weight != 1 {
+			t.Errorf("got weight %v for %v on the axis of the capsule, want 1", v.Weight, v.Pos)
+		}
+	}
+	for _, radius := range []float64{-1, math.NaN()} {
+		if _, err := CoverageBetweenPoints(start, end, radius); err == nil {
+			t.Errorf("expected an error for a radius of %v", radius)
+		}
+	}
+	if _, err := CoverageBetweenPoints(start, start, 1); err != ErrZeroDirection {
+		t.Errorf("got %v for a zero length ray, want %v", err, ErrZeroDirection)
+	}
+}
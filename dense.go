@@ -0,0 +1,78 @@
+package voxelraytrace
+
+import (
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// DenseVoxelGrid is a Grid backed by a flat array of booleans, spanning from (0, 0, 0) to (W, H, D). It is suited
+// for small, enclosed spaces. Voxels outside the grid are never solid. A DenseVoxelGrid is not safe for concurrent
+// use if any of the goroutines using it modify it.
+type DenseVoxelGrid struct {
+	data    []bool
+	W, H, D int
+}
+
+// NewDenseVoxelGrid creates a DenseVoxelGrid with the width, height and depth passed, with all voxels empty.
+func NewDenseVoxelGrid(w, h, d int) *DenseVoxelGrid {
+	return &DenseVoxelGrid{data: make([]bool, w*h*d), W: w, H: h, D: d}
+}
+
+// Set sets the voxel at the coordinates passed to solid or empty. Coordinates outside the grid are ignored.
+func (g *DenseVoxelGrid) Set(x, y, z int, v bool) {
+	if g.inside(x, y, z) {
+		g.data[g.index(x, y, z)] = v
+	}
+}
+
+// Get returns true if the voxel at the coordinates passed is solid. Coordinates outside the grid are never solid.
+func (g *DenseVoxelGrid) Get(x, y, z int) bool {
+	return g.inside(x, y, z) && g.data[g.index(x, y, z)]
+}
+
+// Contains returns true if the voxel containing the vector passed is solid.
+func (g *DenseVoxelGrid) Contains(v mgl64.Vec3) bool {
+	pos := BlockPosFromVec3(v)
+	return g.Get(pos[0], pos[1], pos[2])
+}
+
+// Solid returns true if the voxel at the coordinates passed is solid. It is the same as Get.
+func (g *DenseVoxelGrid) Solid(x, y, z int) bool {
+	return g.Get(x, y, z)
+}
+
+// Fill sets all voxels of the grid to solid or empty.
+func (g *DenseVoxelGrid) Fill(v bool) {
+	for i := range g.data {
+		g.data[i] = v
+	}
+}
+
+// Dims returns the width, height and depth of the grid.
+func (g *DenseVoxelGrid) Dims() (w, h, d int) {
+	return g.W, g.H, g.D
+}
+
+// Bounds returns the world space extent of the grid.
+func (g *DenseVoxelGrid) Bounds() (min, max mgl64.Vec3) {
+	return mgl64.Vec3{}, mgl64.Vec3{float64(g.W), float64(g.H), float64(g.D)}
+}
+
+// TraceFirst performs a ray trace between the start and end coordinates and returns the first solid voxel of the
+// grid passed through. If no solid voxel was passed through, false is returned.
+func (g *DenseVoxelGrid) TraceFirst(start, end mgl64.Vec3) (mgl64.Vec3, bool, error) {
+	hit, ok, err := FirstSolidHit(g, start, end)
+	if !ok || err != nil {
+		return mgl64.Vec3{}, false, err
+	}
+	return hit.Pos.Vec3Min(), true, nil
+}
+
+// inside checks if the coordinates passed are inside the grid.
+func (g *DenseVoxelGrid) inside(x, y, z int) bool {
+	return x >= 0 && y >= 0 && z >= 0 && x < g.W && y < g.H && z < g.D
+}
+
+// index returns the index in the data slice of the voxel at the coordinates passed.
+func (g *DenseVoxelGrid) index(x, y, z int) int {
+	return (y*g.D+z)*g.W + x
+}
@@ -0,0 +1,31 @@
+package voxelraytrace
+
+import (
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// The Go compiler may fuse a multiplication followed by an addition into a single fused multiply-add instruction on
+// architectures that have one, such as arm64, but not on others, such as amd64. As the fused instruction rounds only
+// once, this makes the result differ in the last bit between platforms, which is enough to move a ray onto the other
+// side of a voxel boundary. An explicit conversion of a product to float64 rounds it and prevents the fusion, so the
+// arithmetic of the tracer is written that way, through the functions below.
+
+// along returns the point at the distance passed from p in the direction passed, with the same rounding on every
+// platform.
+func along(p, dir mgl64.Vec3, dist float64) mgl64.Vec3 {
+	return mgl64.Vec3{p[0] + float64(dir[0]*dist), p[1] + float64(dir[1]*dist), p[2] + float64(dir[2]*dist)}
+}
+
+// sumSquares returns x*x + y*y + z*z, summed in that order, with the same rounding on every platform.
+func sumSquares(x, y, z float64) float64 {
+	return float64(x*x) + float64(y*y) + float64(z*z)
+}
+
+// cross returns the cross product of the vectors passed, with the same rounding on every platform.
+func cross(a, b mgl64.Vec3) mgl64.Vec3 {
+	return mgl64.Vec3{
+		float64(a[1]*b[2]) - float64(a[2]*b[1]),
+		float64(a[2]*b[0]) - float64(a[0]*b[2]),
+		float64(a[0]*b[1]) - float64(a[1]*b[0]),
+	}
+}
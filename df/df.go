@@ -0,0 +1,97 @@
+// Package df provides helpers for using voxelraytrace with the types of the Dragonfly server software. It lives in
+// a separate module, so that the core package does not depend on Dragonfly.
+package df
+
+import (
+	"github.com/df-mc/dragonfly/server/block/cube"
+	"github.com/df-mc/dragonfly/server/world"
+	"github.com/go-gl/mathgl/mgl64"
+	"github.com/justtaldevelops/voxelraytrace"
+)
+
+// BetweenPointsCube performs a ray trace between the start and end coordinates like voxelraytrace.BetweenPoints,
+// but returns the voxels passed through as cube.Pos.
+func BetweenPointsCube(start, end mgl64.Vec3) ([]cube.Pos, error) {
+	blocks, err := voxelraytrace.BetweenPointsInt(start, end)
+	if err != nil {
+		return nil, err
+	}
+	positions := make([]cube.Pos, len(blocks))
+	for i, b := range blocks {
+		positions[i] = cube.Pos(b)
+	}
+	return positions, nil
+}
+
+// Grid adapts a world.BlockSource, such as a *world.Tx, to a voxelraytrace.Grid. A block is solid if its model has
+// at least one bounding box.
+type Grid struct {
+	Source world.BlockSource
+	// Liquids specifies if liquid blocks, such as water and lava, are solid as well, so that a ray stops at the
+	// surface of a liquid, as done when using a bucket. Liquids have no bounding box, so rays pass through them
+	// otherwise.
+	Liquids bool
+}
+
+// Solid returns true if the block at the coordinates passed has at least one bounding box, or if it is a liquid and
+// Liquids is true.
+func (g Grid) Solid(x, y, z int) bool {
+	pos := cube.Pos{x, y, z}
+	b := g.Source.Block(pos)
+	if _, ok := b.(world.Liquid); ok && g.Liquids {
+		return true
+	}
+	return len(b.Model().BBox(pos, g.Source)) > 0
+}
+
+// FirstSolidHitWorld performs a ray trace between the start and end coordinates like voxelraytrace.FirstSolidHit,
+// using the blocks of the world.BlockSource passed to find the first solid block. Liquids are passed through. The
+// face of the HitResult may be converted using CubeFace.
+func FirstSolidHitWorld(src world.BlockSource, start, end mgl64.Vec3, opts ...voxelraytrace.Option) (voxelraytrace.HitResult, bool, error) {
+	return voxelraytrace.FirstSolidHit(Grid{Source: src}, start, end, opts...)
+}
+
+// FirstLiquidOrSolidHitWorld performs a ray trace between the start and end coordinates like FirstSolidHitWorld, but
+// also stops at the first liquid block, such as to find the block a player fills a bucket from.
+func FirstLiquidOrSolidHitWorld(src world.BlockSource, start, end mgl64.Vec3, opts ...voxelraytrace.Option) (voxelraytrace.HitResult, bool, error) {
+	return voxelraytrace.FirstSolidHit(Grid{Source: src, Liquids: true}, start, end, opts...)
+}
+
+// CubeFace converts a voxelraytrace.Face to a cube.Face. False is returned if the face is voxelraytrace.FaceNone,
+// which has no cube.Face equivalent.
+func CubeFace(f voxelraytrace.Face) (cube.Face, bool) {
+	switch f {
+	case voxelraytrace.FaceDown:
+		return cube.FaceDown, true
+	case voxelraytrace.FaceUp:
+		return cube.FaceUp, true
+	case voxelraytrace.FaceNorth:
+		return cube.FaceNorth, true
+	case voxelraytrace.FaceSouth:
+		return cube.FaceSouth, true
+	case voxelraytrace.FaceWest:
+		return cube.FaceWest, true
+	case voxelraytrace.FaceEast:
+		return cube.FaceEast, true
+	}
+	return 0, false
+}
+
+// Face converts a cube.Face to a voxelraytrace.Face.
+func Face(f cube.Face) voxelraytrace.Face {
+	switch f {
+	case cube.FaceDown:
+		return voxelraytrace.FaceDown
+	case cube.FaceUp:
+		return voxelraytrace.FaceUp
+	case cube.FaceNorth:
+		return voxelraytrace.FaceNorth
+	case cube.FaceSouth:
+		return voxelraytrace.FaceSouth
+	case cube.FaceWest:
+		return voxelraytrace.FaceWest
+	case cube.FaceEast:
+		return voxelraytrace.FaceEast
+	}
+	return voxelraytrace.FaceNone
+}
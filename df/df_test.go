@@ -0,0 +1,91 @@
+package df
+
+import (
+	"github.com/df-mc/dragonfly/server/block"
+	"github.com/df-mc/dragonfly/server/block/cube"
+	"github.com/df-mc/dragonfly/server/world"
+	"github.com/go-gl/mathgl/mgl64"
+	"github.com/justtaldevelops/voxelraytrace"
+	"reflect"
+	"testing"
+)
+
+// stubSource is a world.BlockSource holding the blocks set in it, with all other blocks being air.
+type stubSource map[cube.Pos]world.Block
+
+// Block returns the block at the position passed, or air if no block was set there.
+func (s stubSource) Block(pos cube.Pos) world.Block {
+	if b, ok := s[pos]; ok {
+		return b
+	}
+	return block.Air{}
+}
+
+func TestBetweenPointsCube(t *testing.T) {
+	start, end := mgl64.Vec3{0.5, 64.2, -3.7}, mgl64.Vec3{9.1, 60.5, 4.25}
+	got, err := BetweenPointsCube(start, end)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want, _ := voxelraytrace.BetweenPointsInt(start, end)
+	if len(got) != len(want) {
+		t.Fatalf("got %v voxels, want %v", len(got), len(want))
+	}
+	for i := range got {
+		if got[i] != cube.Pos(want[i]) {
+			t.Fatalf("voxel %v: got %v, want %v", i, got[i], want[i])
+		}
+	}
+	if _, err := BetweenPointsCube(start, start); err != voxelraytrace.ErrZeroDirection {
+		t.Errorf("got error %v, want %v", err, voxelraytrace.ErrZeroDirection)
+	}
+}
+
+func TestFirstSolidHitWorld(t *testing.T) {
+	src := stubSource{
+		{2, 0, 0}: block.Water{Still: true, Depth: 8},
+		{4, 0, 0}: block.Stone{},
+	}
+	start, end := mgl64.Vec3{0.5, 0.5, 0.5}, mgl64.Vec3{8.5, 0.5, 0.5}
+
+	hit, ok, err := FirstSolidHitWorld(src, start, end)
+	if err != nil || !ok {
+		t.Fatalf("got %v, %v, want a hit", ok, err)
+	}
+	want, _, _ := voxelraytrace.FirstSolidHit(stubGrid{{4, 0, 0}: true}, start, end)
+	if !reflect.DeepEqual(hit, want) {
+		t.Errorf("got %+v, want %+v", hit, want)
+	}
+	if f, _ := CubeFace(hit.Face); f != cube.FaceWest {
+		t.Errorf("got face %v, want %v", f, cube.FaceWest)
+	}
+
+	hit, ok, err = FirstLiquidOrSolidHitWorld(src, start, end)
+	if err != nil || !ok || hit.Pos != (voxelraytrace.BlockPos{2, 0, 0}) {
+		t.Errorf("got %v, %v, %v, want a hit on the water at [2 0 0]", hit.Pos, ok, err)
+	}
+
+	if _, ok, err := FirstSolidHitWorld(src, start, mgl64.Vec3{0.5, 8.5, 0.5}); ok || err != nil {
+		t.Errorf("got %v, %v, want a miss", ok, err)
+	}
+}
+
+func TestFaceConversion(t *testing.T) {
+	for _, f := range cube.Faces() {
+		got, ok := CubeFace(Face(f))
+		if !ok || got != f {
+			t.Errorf("face %v: converted back to %v, %v", f, got, ok)
+		}
+	}
+	if _, ok := CubeFace(voxelraytrace.FaceNone); ok {
+		t.Error("FaceNone was converted to a cube.Face")
+	}
+}
+
+// stubGrid is a voxelraytrace.Grid holding the solid voxels set in it.
+type stubGrid map[voxelraytrace.BlockPos]bool
+
+// Solid returns true if the voxel at the coordinates passed was set to solid.
+func (g stubGrid) Solid(x, y, z int) bool {
+	return g[voxelraytrace.BlockPos{x, y, z}]
+}
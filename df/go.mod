@@ -0,0 +1,25 @@
+module github.com/justtaldevelops/voxelraytrace/df
+
+go 1.23.3
+
+require (
+	github.com/df-mc/dragonfly v0.10.0
+	github.com/go-gl/mathgl v1.2.0
+	github.com/justtaldevelops/voxelraytrace v0.0.0
+)
+
+require (
+	github.com/brentp/intintmap v0.0.0-20190211203843-30dc0ade9af9 // indirect
+	github.com/df-mc/goleveldb v1.1.9 // indirect
+	github.com/df-mc/worldupgrader v1.0.18 // indirect
+	github.com/golang/snappy v0.0.4 // indirect
+	github.com/google/uuid v1.6.0 // indirect
+	github.com/klauspost/compress v1.17.11 // indirect
+	github.com/sandertv/gophertunnel v1.43.0 // indirect
+	github.com/segmentio/fasthash v1.0.3 // indirect
+	golang.org/x/exp v0.0.0-20241217172543-b2144cdd0a67 // indirect
+	golang.org/x/net v0.33.0 // indirect
+	golang.org/x/text v0.21.0 // indirect
+)
+
+replace github.com/justtaldevelops/voxelraytrace => ../
@@ -0,0 +1,17 @@
+// Package voxelraytrace implements ray traces through grids of voxels, finding the voxels a ray passes through and
+// the first solid voxels it hits.
+//
+// Vectors are passed and returned as mgl64.Vec3, which is defined as [3]float64, so code that does not otherwise use
+// mathgl may convert its own [3]float64 coordinates to and from mgl64.Vec3 directly, without copying them.
+//
+// # Determinism
+//
+// The voxels passed through by a ray are the same on every platform supported by Go, such as amd64 and arm64, as long
+// as the ray is described by its start and end, or by a start, a direction vector and a distance. This holds for
+// BetweenPoints, BetweenPointsInt, InDirection, FirstSolidHit, SolidHits, Traverser, TraceUnit and the methods of Ray
+// apart from Transform, and for the distances and points of the hits they return. The tracer only uses addition,
+// subtraction, multiplication, division and square roots, which round exactly as specified by IEEE 754 everywhere,
+// and never lets the compiler fuse a multiplication and an addition into a single instruction.
+// Functions that compute their rays from angles or matrices, such as FOVScan, FromRotation and Ray.Transform, rely on
+// the platform for those computations, so their rays may differ in the last bit between platforms.
+package voxelraytrace
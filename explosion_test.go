@@ -0,0 +1,170 @@
+package voxelraytrace
+
+import (
+	"github.com/go-gl/mathgl/mgl64"
+	"math"
+	"reflect"
+	"testing"
+)
+
+// vanillaExplosion is a line by line port of the ray sampling of vanilla explosions, computing every value with the
+// same types and in the same order, used as the reference that ExplosionRays is checked against. factor is the random
+// factor that the power of every ray is multiplied by.
+func vanillaExplosion(x, y, z float64, size float32, factor float32, resistance func(pos BlockPos) float64) map[BlockPos]struct{} {
+	set := make(map[BlockPos]struct{})
+	for j := 0; j < 16; j++ {
+		for k := 0; k < 16; k++ {
+			for l := 0; l < 16; l++ {
+				if j != 0 && j != 15 && k != 0 && k != 15 && l != 0 && l != 15 {
+					continue
+				}
+				d0 := float64(float32(j)/15.0*2.0 - 1.0)
+				d1 := float64(float32(k)/15.0*2.0 - 1.0)
+				d2 := float64(float32(l)/15.0*2.0 - 1.0)
+				d3 := math.Sqrt(d0*d0 + d1*d1 + d2*d2)
+				d0 /= d3
+				d1 /= d3
+				d2 /= d3
+				f := size * factor
+				d4, d6, d8 := x, y, z
+				for ; f > 0; f -= 0.22500001 {
+					pos := BlockPos{int(math.Floor(d4)), int(math.Floor(d6)), int(math.Floor(d8))}
+					if r := resistance(pos); r >= 0 {
+						f -= (float32(r) + 0.3) * 0.3
+						if f > 0 {
+							set[pos] = struct{}{}
+						}
+					}
+					d4 += d0 * float64(float32(0.3))
+					d6 += d1 * float64(float32(0.3))
+					d8 += d2 * float64(float32(0.3))
+				}
+			}
+		}
+	}
+	return set
+}
+
+// explosionWorlds are worlds of uniform resistance, below a floor or inside a ball, that explosions are tested in.
+var explosionWorlds = []struct {
+	name       string
+	resistance func(pos BlockPos) float64
+}{
+	{name: "air", resistance: func(BlockPos) float64 { return -1 }},
+	{name: "stone", resistance: func(BlockPos) float64 { return 6 }},
+	{name: "dirt", resistance: func(BlockPos) float64 { return 0.5 }},
+	{name: "dirt floor", resistance: func(pos BlockPos) float64 {
+		if pos[1] < 64 {
+			return 0.5
+		}
+		return -1
+	}},
+	{name: "glass ball", resistance: func(pos BlockPos) float64 {
+		if d := pos.Vec3Centre().Sub(mgl64.Vec3{0.5, 64.5, 0.5}).Len(); d > 3 && d < 5 {
+			return 0.3
+		}
+		return -1
+	}},
+}
+
+func TestExplosionRaysMatchesVanilla(t *testing.T) {
+	centers := []mgl64.Vec3{{0.5, 64.5, 0.5}, {0.3, 64, -7.9}, {100.01, 63.99, -2.5}}
+	for _, world := range explosionWorlds {
+		for _, power := range []float64{1, 4, 6.5} {
+			for _, center := range centers {
+				got := ExplosionRays(center, power, world.resistance)
+				want := vanillaExplosion(center[0], center[1], center[2], float32(power), 1, world.resistance)
+				if len(got) != len(want) {
+					t.Fatalf("%v, power %v at %v: got %v voxels, want %v", world.name, power, center, len(got), len(want))
+				}
+				for _, pos := range got {
+					if _, ok := want[pos]; !ok {
+						t.Fatalf("%v, power %v at %v: %v destroyed, but not by vanilla", world.name, power, center, pos)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestExplosionRaysJittered(t *testing.T) {
+	center, factor := mgl64.Vec3{0.5, 64.5, 0.5}, 1.1
+	resistance := explosionWorlds[3].resistance
+	got := ExplosionRaysJittered(center, 4, resistance, func(mgl64.Vec3) float64 { return factor })
+	want := vanillaExplosion(center[0], center[1], center[2], 4, float32(factor), resistance)
+	if len(got) != len(want) {
+		t.Fatalf("got %v voxels, want %v", len(got), len(want))
+	}
+	for _, pos := range got {
+		if _, ok := want[pos]; !ok {
+			t.Fatalf("%v destroyed, but not by vanilla", pos)
+		}
+	}
+}
+
+func TestExplosionRaysCrater(t *testing.T) {
+	// Nothing is destroyed without blocks, and TNT only destroys the voxel it is in when surrounded by stone.
+	if got := ExplosionRays(mgl64.Vec3{0.5, 64.5, 0.5}, 4, explosionWorlds[0].resistance); len(got) != 0 {
+		t.Errorf("air: got %v, want no destroyed voxels", got)
+	}
+	if got := ExplosionRays(mgl64.Vec3{0.5, 64.5, 0.5}, 4, explosionWorlds[1].resistance); !reflect.DeepEqual(got, []BlockPos{{0, 64, 0}}) {
+		t.Errorf("stone: got %v, want only [0 64 0]", got)
+	}
+	// TNT resting on a dirt floor digs a crater below it that is symmetric in X and Z.
+	got := ExplosionRays(mgl64.Vec3{0.5, 64, 0.5}, 4, explosionWorlds[3].resistance)
+	if len(got) == 0 {
+		t.Fatal("no voxels destroyed")
+	}
+	crater := make(map[BlockPos]struct{}, len(got))
+	for _, pos := range got {
+		if pos[1] >= 64 {
+			t.Fatalf("%v destroyed above the floor", pos)
+		}
+		crater[pos] = struct{}{}
+	}
+	for _, pos := range got {
+		for _, mirrored := range []BlockPos{{-pos[0], pos[1], pos[2]}, {pos[0], pos[1], -pos[2]}, {pos[2], pos[1], pos[0]}} {
+			if _, ok := crater[mirrored]; !ok {
+				t.Fatalf("crater is not symmetric: %v destroyed, but %v not", pos, mirrored)
+			}
+		}
+	}
+}
+
+func TestExplosionRaysDeterministic(t *testing.T) {
+	center := mgl64.Vec3{0.3, 64, -7.9}
+	a := ExplosionRays(center, 4, explosionWorlds[2].resistance)
+	b := ExplosionRays(center, 4, explosionWorlds[2].resistance)
+	if !reflect.DeepEqual(a, b) {
+		t.Fatal("explosions with the same input destroyed different voxels")
+	}
+}
+
+func TestExplosionRaysDirections(t *testing.T) {
+	var got []mgl64.Vec3
+	ExplosionRaysJittered(mgl64.Vec3{}, 1, func(BlockPos) float64 { return -1 }, func(dir mgl64.Vec3) float64 {
+		got = append(got, dir)
+		return 1
+	})
+	if len(got) != 16*16*16-14*14*14 {
+		t.Fatalf("got %v rays, want %v", len(got), 16*16*16-14*14*14)
+	}
+	i := 0
+	for j := 0; j < 16; j++ {
+		for k := 0; k < 16; k++ {
+			for l := 0; l < 16; l++ {
+				if j != 0 && j != 15 && k != 0 && k != 15 && l != 0 && l != 15 {
+					continue
+				}
+				d0 := float64(float32(j)/15.0*2.0 - 1.0)
+				d1 := float64(float32(k)/15.0*2.0 - 1.0)
+				d2 := float64(float32(l)/15.0*2.0 - 1.0)
+				d3 := math.Sqrt(d0*d0 + d1*d1 + d2*d2)
+				if want := (mgl64.Vec3{d0 / d3, d1 / d3, d2 / d3}); got[i] != want {
+					t.Fatalf("ray %v: got direction %v, want %v", i, got[i], want)
+				}
+				i++
+			}
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package voxelraytrace
+
+import (
+	"fmt"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// Face represents one of the six faces of a voxel.
+type Face uint8
+
+const (
+	// FaceNone is the Face reported for the voxel a ray starts in, as the ray did not enter it through any face.
+	FaceNone Face = iota
+	// FaceDown is the face of a voxel pointing towards negative Y.
+	FaceDown
+	// FaceUp is the face of a voxel pointing towards positive Y.
+	FaceUp
+	// FaceNorth is the face of a voxel pointing towards negative Z.
+	FaceNorth
+	// FaceSouth is the face of a voxel pointing towards positive Z.
+	FaceSouth
+	// FaceWest is the face of a voxel pointing towards negative X.
+	FaceWest
+	// FaceEast is the face of a voxel pointing towards positive X.
+	FaceEast
+)
+
+// FaceID is an alias of Face, used where a face is identified independently of any extra data about it, such as in
+// IntersectionFace.
+type FaceID = Face
+
+const (
+	// FaceNegX is the face of a voxel pointing towards negative X. It is the same as FaceWest.
+	FaceNegX = FaceWest
+	// FacePosX is the face of a voxel pointing towards positive X. It is the same as FaceEast.
+	FacePosX = FaceEast
+	// FaceNegY is the face of a voxel pointing towards negative Y. It is the same as FaceDown.
+	FaceNegY = FaceDown
+	// FacePosY is the face of a voxel pointing towards positive Y. It is the same as FaceUp.
+	FacePosY = FaceUp
+	// FaceNegZ is the face of a voxel pointing towards negative Z. It is the same as FaceNorth.
+	FaceNegZ = FaceNorth
+	// FacePosZ is the face of a voxel pointing towards positive Z. It is the same as FaceSouth.
+	FacePosZ = FaceSouth
+)
+
+// Offset returns the offset that must be added to the position of a voxel to get the position of the voxel on the
+// other side of the face. FaceNone has an offset of zero.
+func (f Face) Offset() BlockPos {
+	switch f {
+	case FaceDown:
+		return BlockPos{0, -1, 0}
+	case FaceUp:
+		return BlockPos{0, 1, 0}
+	case FaceNorth:
+		return BlockPos{0, 0, -1}
+	case FaceSouth:
+		return BlockPos{0, 0, 1}
+	case FaceWest:
+		return BlockPos{-1, 0, 0}
+	case FaceEast:
+		return BlockPos{1, 0, 0}
+	}
+	return BlockPos{}
+}
+
+// Opposite returns the face on the opposite side of the voxel. The opposite of FaceNone is FaceNone.
+func (f Face) Opposite() Face {
+	switch f {
+	case FaceDown:
+		return FaceUp
+	case FaceUp:
+		return FaceDown
+	case FaceNorth:
+		return FaceSouth
+	case FaceSouth:
+		return FaceNorth
+	case FaceWest:
+		return FaceEast
+	case FaceEast:
+		return FaceWest
+	}
+	return FaceNone
+}
+
+// axis returns the axis the face points along and whether it points towards positive coordinates. FaceNone returns
+// AxisNone.
+func (f Face) axis() (Axis, bool) {
+	switch f {
+	case FaceDown, FaceUp:
+		return AxisY, f == FaceUp
+	case FaceNorth, FaceSouth:
+		return AxisZ, f == FaceSouth
+	case FaceWest, FaceEast:
+		return AxisX, f == FaceEast
+	}
+	return AxisNone, false
+}
+
+// String returns the name of the face.
+func (f Face) String() string {
+	switch f {
+	case FaceDown:
+		return "down"
+	case FaceUp:
+		return "up"
+	case FaceNorth:
+		return "north"
+	case FaceSouth:
+		return "south"
+	case FaceWest:
+		return "west"
+	case FaceEast:
+		return "east"
+	}
+	return "none"
+}
+
+// FaceNormal returns the outward unit normal of the face passed, such as (1, 0, 0) for FacePosX. FaceNormal panics if
+// FaceNone or an unknown face is passed.
+func FaceNormal(f FaceID) mgl64.Vec3 {
+	mustBeFace(f)
+	return f.Offset().Vec3Min()
+}
+
+// FaceOpposite returns the face on the opposite side of the voxel to the face passed. FaceOpposite panics if FaceNone
+// or an unknown face is passed.
+func FaceOpposite(f FaceID) FaceID {
+	mustBeFace(f)
+	return f.Opposite()
+}
+
+// FaceAxis returns the axis the face passed points along, regardless of its direction. FaceAxis panics if FaceNone or
+// an unknown face is passed.
+func FaceAxis(f FaceID) Axis {
+	mustBeFace(f)
+	axis, _ := f.axis()
+	return axis
+}
+
+// mustBeFace panics if the face passed is not one of the six faces of a voxel.
+func mustBeFace(f FaceID) {
+	if f == FaceNone || f > FaceEast {
+		panic(fmt.Sprintf("voxelraytrace: face %v (%d) is not a valid geometric face", f, uint8(f)))
+	}
+}
+
+// entryFace returns the face through which a voxel is entered when stepping in the direction of the step passed on
+// an axis, given the faces pointing towards the negative and positive side of that axis.
+func entryFace(step int, neg, pos Face) Face {
+	if step > 0 {
+		return neg
+	}
+	return pos
+}
+
+// axisEntryFace returns the face through which a voxel is entered when stepping in the direction of the step passed
+// on the axis of the Y-up convention passed, with 0 being the X axis, 1 the Y axis and 2 the Z axis.
+func axisEntryFace(step, axis int) Face {
+	switch axis {
+	case 0:
+		return entryFace(step, FaceWest, FaceEast)
+	case 1:
+		return entryFace(step, FaceDown, FaceUp)
+	}
+	return entryFace(step, FaceNorth, FaceSouth)
+}
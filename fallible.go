@@ -0,0 +1,94 @@
+package voxelraytrace
+
+import (
+	"errors"
+	"fmt"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// ErrUnloaded may be returned by a FallibleGrid for voxels that are not loaded, such as those in a chunk that a server
+// has not loaded yet.
+var ErrUnloaded = errors.New("voxel not loaded")
+
+// FallibleGrid is a Grid that cannot always tell if a voxel is solid, for example because the chunk holding it is not
+// loaded. The functions consuming a Grid that support it, FirstSolidHit, SolidHits, LineOfSightTolerant and
+// LOSMatrix, use SolidErr rather than Solid to look up voxels, and stop at the first voxel for which it returns an
+// error, returning the results found before that voxel along with a VoxelError.
+type FallibleGrid interface {
+	Grid
+	// SolidErr returns true if the voxel at the coordinates passed should stop a ray. If this cannot be told, an error
+	// is returned, which should be or wrap ErrUnloaded if the voxel is not loaded.
+	SolidErr(x, y, z int) (bool, error)
+}
+
+// VoxelError is returned when a trace is stopped because a FallibleGrid failed to tell if a voxel is solid. It wraps
+// the error returned by the FallibleGrid, so errors.Is(err, ErrUnloaded) reports if the voxel was not loaded.
+type VoxelError struct {
+	// Pos is the position of the voxel that could not be looked up.
+	Pos BlockPos
+	// Distance is the distance along the ray at which it entered the voxel. Once the voxel can be looked up, the trace
+	// may be resumed from it by passing Distance to WithMinDistance.
+	Distance float64
+	// Err is the error returned by the FallibleGrid.
+	Err error
+}
+
+// Error returns a message holding the position of the voxel and the error returned for it.
+func (e VoxelError) Error() string {
+	return fmt.Sprintf("voxel %v: %v", e.Pos, e.Err)
+}
+
+// Unwrap returns the error returned by the FallibleGrid.
+func (e VoxelError) Unwrap() error {
+	return e.Err
+}
+
+// voxelReader looks up voxels in a Grid, using SolidErr if it is a FallibleGrid.
+type voxelReader struct {
+	g        Grid
+	fallible FallibleGrid
+	// pass returns true for solid voxels that are treated as not solid, if set.
+	pass func(voxel mgl64.Vec3) bool
+}
+
+// newVoxelReader creates a voxelReader for the Grid passed. Voxels of a RegionGrid are looked up a region at a time.
+// Solid voxels for which pass returns true are treated as not solid, unless pass is nil.
+func newVoxelReader(g Grid, pass func(voxel mgl64.Vec3) bool) voxelReader {
+	if fg, ok := g.(FallibleGrid); ok {
+		return voxelReader{g: g, fallible: fg, pass: pass}
+	}
+	return voxelReader{g: cachedGrid(g), pass: pass}
+}
+
+// solid checks if the voxel that the tracer passed is at is solid. If the Grid fails to tell, the error of the tracer
+// is set to a VoxelError and ok is false.
+func (r voxelReader) solid(t *tracer) (solid, ok bool) {
+	if r.fallible == nil {
+		pos := t.pos()
+		return r.g.Solid(pos[0], pos[1], pos[2]) && !r.passed(pos), true
+	}
+	solid, err := r.at(t.pos(), t.t)
+	if err != nil {
+		t.err = err
+		return false, false
+	}
+	return solid, true
+}
+
+// at checks if the voxel at the position passed, entered by a ray at the distance passed, is solid. If the Grid fails
+// to tell, a VoxelError is returned.
+func (r voxelReader) at(pos BlockPos, dist float64) (bool, error) {
+	if r.fallible == nil {
+		return r.g.Solid(pos[0], pos[1], pos[2]) && !r.passed(pos), nil
+	}
+	solid, err := r.fallible.SolidErr(pos[0], pos[1], pos[2])
+	if err != nil {
+		return false, VoxelError{Pos: pos, Distance: dist, Err: err}
+	}
+	return solid && !r.passed(pos), nil
+}
+
+// passed checks if the solid voxel at the position passed is passed through.
+func (r voxelReader) passed(pos BlockPos) bool {
+	return r.pass != nil && r.pass(pos.Vec3Min())
+}
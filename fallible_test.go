@@ -0,0 +1,74 @@
+package voxelraytrace
+
+import (
+	"errors"
+	"fmt"
+	"github.com/go-gl/mathgl/mgl64"
+	"reflect"
+	"testing"
+)
+
+// planeGrid is a FallibleGrid of which the voxels at or beyond an X coordinate are not loaded until loaded is set.
+type planeGrid struct {
+	*SparseGrid
+	plane  int
+	loaded bool
+}
+
+// SolidErr returns if the voxel at the position passed is solid, or ErrUnloaded if it lies beyond the plane and is not
+// loaded.
+func (g *planeGrid) SolidErr(x, y, z int) (bool, error) {
+	if x >= g.plane && !g.loaded {
+		return false, fmt.Errorf("chunk at X %v: %w", x>>4, ErrUnloaded)
+	}
+	return g.Solid(x, y, z), nil
+}
+
+func TestFallibleGridFirstSolidHit(t *testing.T) {
+	g := &planeGrid{SparseGrid: wallGrid(8), plane: 5}
+	start, end := mgl64.Vec3{0.5, 0.5, 0.5}, mgl64.Vec3{12.5, 1.5, 0.5}
+	hit, ok, err := FirstSolidHit(g, start, end)
+	var voxelErr VoxelError
+	if ok || !errors.Is(err, ErrUnloaded) || !errors.As(err, &voxelErr) {
+		t.Fatalf("got %v, %v, %v, want no hit and an unloaded voxel", hit, ok, err)
+	}
+	if voxelErr.Pos != (BlockPos{5, 0, 0}) {
+		t.Errorf("got unloaded voxel %v, want %v", voxelErr.Pos, BlockPos{5, 0, 0})
+	}
+
+	// Once the voxels are loaded, the trace is resumed from the voxel it stopped at, giving the same hit as a trace
+	// through the loaded grid.
+	g.loaded = true
+	want, _, _ := FirstSolidHit(g, start, end)
+	hit, ok, err = FirstSolidHit(g, start, end, WithMinDistance(voxelErr.Distance))
+	if err != nil || !ok || hit != want || hit.Pos[0] != 8 {
+		t.Errorf("got %v, %v, %v after resuming, want %v", hit, ok, err, want)
+	}
+}
+
+func TestFallibleGridSolidHits(t *testing.T) {
+	g := &planeGrid{SparseGrid: wallGrid(2, 4, 8), plane: 5}
+	start, end := mgl64.Vec3{0.5, 0.5, 0.5}, mgl64.Vec3{12.5, 0.5, 0.5}
+	// The hits before the unloaded voxels are returned along with the error.
+	hits, err := SolidHits(g, start, end)
+	var voxelErr VoxelError
+	if !errors.As(err, &voxelErr) || !errors.Is(err, ErrUnloaded) {
+		t.Fatalf("got %v, want an unloaded voxel", err)
+	}
+	if got, want := hitPositions(hits), []BlockPos{{2, 0, 0}, {4, 0, 0}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v before the unloaded voxels", got, want)
+	}
+
+	g.loaded = true
+	rest, err := SolidHits(g, start, end, WithMinDistance(voxelErr.Distance))
+	all, _ := SolidHits(g, start, end)
+	if err != nil || !reflect.DeepEqual(append(hits, rest...), all) {
+		t.Errorf("got %v, %v after resuming, want the rest of %v", rest, err, all)
+	}
+
+	g.loaded = false
+	visible, blockers, err := LineOfSightTolerant(g, start, end, 5)
+	if visible || !errors.Is(err, ErrUnloaded) || !reflect.DeepEqual(blockers, []BlockPos{{2, 0, 0}, {4, 0, 0}}) {
+		t.Errorf("got %v, %v, %v, want the blockers before the unloaded voxels and an error", visible, blockers, err)
+	}
+}
@@ -0,0 +1,34 @@
+package voxelraytrace
+
+import (
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// FilterVoxels returns a new slice holding only the voxels passed for which pred returns true, in the same order. The
+// voxels passed are left unchanged. Nil is returned if no voxel matches.
+func FilterVoxels(voxels []mgl64.Vec3, pred func(mgl64.Vec3) bool) []mgl64.Vec3 {
+	var filtered []mgl64.Vec3
+	for _, v := range voxels {
+		if pred(v) {
+			filtered = append(filtered, v)
+		}
+	}
+	return filtered
+}
+
+// FilterVoxelsInPlace removes the voxels for which pred returns false from the slice passed without allocating, by
+// moving the remaining voxels to the front of it, and returns the shortened slice. The order of the remaining voxels
+// is kept. The elements between the new and the old length of the slice are zeroed.
+func FilterVoxelsInPlace(voxels []mgl64.Vec3, pred func(mgl64.Vec3) bool) []mgl64.Vec3 {
+	n := 0
+	for _, v := range voxels {
+		if pred(v) {
+			voxels[n] = v
+			n++
+		}
+	}
+	for i := n; i < len(voxels); i++ {
+		voxels[i] = mgl64.Vec3{}
+	}
+	return voxels[:n]
+}
@@ -0,0 +1,54 @@
+package voxelraytrace
+
+import (
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// FOVRay holds the result of a single ray of a FOVScan.
+type FOVRay struct {
+	// Yaw is the yaw of the ray in degrees.
+	Yaw float64
+	// Blocked specifies if the ray hit a solid voxel within the view distance. Voxel and Distance are only set if
+	// this is true.
+	Blocked bool
+	// Voxel is the position of the solid voxel hit by the ray.
+	Voxel BlockPos
+	// Distance is the distance from the origin to the point at which the ray entered Voxel.
+	Distance float64
+}
+
+// FOVScan casts a horizontal fan of rays from the origin against the Grid passed, such as for the field of view of a
+// guard, and returns the first solid voxel hit by every ray within the view distance. The rays are spread evenly over
+// yawSpread degrees centred around yawCenter, including both edges, and all have the pitch passed. Angles are in
+// degrees and follow the Minecraft convention: a yaw of 0 faces positive Z, a yaw of 90 faces negative X and a
+// positive pitch faces down. A single ray is cast at yawCenter if rayCount is 1, and nil is returned if it is 0 or
+// less. Rays starting outside of the bounds of a BoundedGrid are reported as not blocked.
+func FOVScan(g Grid, origin mgl64.Vec3, yawCenter, yawSpread, pitch float64, rayCount int, viewDist float64) []FOVRay {
+	if rayCount <= 0 {
+		return nil
+	}
+	rays := make([]FOVRay, rayCount)
+	cached, conf := cachedGrid(g), newConfig(nil)
+	for i := range rays {
+		yaw := yawCenter
+		if rayCount > 1 {
+			yaw = yawCenter - yawSpread/2 + yawSpread*float64(i)/float64(rayCount-1)
+		}
+		rays[i].Yaw = yaw
+
+		t := newUnitTracer(origin, DirectionFromRotation(yaw, pitch), viewDist, conf)
+		if t.bound(g) != nil {
+			continue
+		}
+		for {
+			if cached.Solid(t.x, t.y, t.z) {
+				rays[i].Blocked, rays[i].Voxel, rays[i].Distance = true, t.pos(), t.t
+				break
+			}
+			if !t.next() {
+				break
+			}
+		}
+	}
+	return rays
+}
@@ -0,0 +1,116 @@
+package voxelraytrace
+
+import (
+	"encoding/json"
+	"flag"
+	"github.com/go-gl/mathgl/mgl64"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// update makes TestGoldenPaths write the paths traced on the current platform to the golden file, rather than
+// comparing them. The file should only be updated when the voxels returned are changed on purpose.
+var update = flag.Bool("update", false, "update the golden files in testdata")
+
+// goldenRay is a ray of the golden file along with the voxels it passes through. Rays with a zero Distance are traced
+// using BetweenPointsInt from Start to End, and others using a Traverser from Start along Dir for the Distance.
+type goldenRay struct {
+	Start, End, Dir mgl64.Vec3
+	Distance        float64
+	Path            []BlockPos
+}
+
+// goldenRays returns the rays of the golden file, without their paths: rays with and without rounded coordinates,
+// long rays and rays far away from the origin, where the rounding of the coordinates matters most.
+func goldenRays() []goldenRay {
+	rng := rand.New(rand.NewSource(1))
+	var rays []goldenRay
+	for i := 0; i < 100; i++ {
+		rays = append(rays, goldenRay{Start: randomPoint(rng, 8), End: randomPoint(rng, 8)})
+	}
+	for i := 0; i < 20; i++ {
+		start, dir, length := randomUnitRay(rng)
+		rays = append(rays, goldenRay{Start: start, End: start.Add(dir.Mul(length * 4))})
+	}
+	far := mgl64.Vec3{1e6, -3e5, 29999984}
+	for i := 0; i < 20; i++ {
+		rays = append(rays, goldenRay{Start: far.Add(randomPoint(rng, 4)), End: far.Add(randomPoint(rng, 4))})
+	}
+	for i := 0; i < 40; i++ {
+		start := randomPoint(rng, 8)
+		if i%2 == 0 {
+			start = start.Add(far)
+		}
+		dir := mgl64.Vec3{rng.Float64()*2 - 1, rng.Float64()*2 - 1, rng.Float64()*2 - 1}
+		rays = append(rays, goldenRay{Start: start, Dir: dir.Mul(rng.Float64() * 3), Distance: rng.Float64() * 12})
+	}
+	return rays
+}
+
+// trace traces the golden ray and returns the voxels it passes through.
+func (r goldenRay) trace() ([]BlockPos, error) {
+	if r.Distance == 0 {
+		return BetweenPointsInt(r.Start, r.End)
+	}
+	tr, err := NewTraverserInDirection(r.Start, r.Dir, r.Distance)
+	if err != nil {
+		return nil, err
+	}
+	return drain(tr), tr.Err()
+}
+
+func TestGoldenPaths(t *testing.T) {
+	name := filepath.Join("testdata", "golden_paths.json")
+	rays := goldenRays()
+	for i, r := range rays {
+		path, err := r.trace()
+		if err != nil {
+			t.Fatalf("ray %v: unexpected error: %v", i, err)
+		}
+		rays[i].Path = path
+	}
+	if *update {
+		// Every ray is written on a line of its own, which keeps the file small but the diffs readable.
+		data := []byte("[\n")
+		for i, r := range rays {
+			line, err := json.Marshal(r)
+			if err != nil {
+				t.Fatalf("unexpected error encoding ray %v: %v", i, err)
+			}
+			if data = append(data, line...); i < len(rays)-1 {
+				data = append(data, ',')
+			}
+			data = append(data, '\n')
+		}
+		if err := os.WriteFile(name, append(data, "]\n"...), 0644); err != nil {
+			t.Fatalf("unexpected error writing %v: %v", name, err)
+		}
+		return
+	}
+
+	data, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatalf("unexpected error reading %v: %v", name, err)
+	}
+	var golden []goldenRay
+	if err := json.Unmarshal(data, &golden); err != nil {
+		t.Fatalf("unexpected error decoding %v: %v", name, err)
+	}
+	if len(golden) != len(rays) {
+		t.Fatalf("%v holds %v rays, want %v", name, len(golden), len(rays))
+	}
+	// The rays are traced from the file, so that a platform decoding the same rays but tracing them differently is
+	// caught, as well as one generating different rays.
+	for i, want := range golden {
+		got, err := want.trace()
+		if err != nil || !reflect.DeepEqual(got, want.Path) {
+			t.Errorf("ray %v %+v: got %v, %v, want %v", i, want, got, err, want.Path)
+		}
+		if want.Start != rays[i].Start || want.End != rays[i].End || want.Dir != rays[i].Dir {
+			t.Errorf("ray %v: got %v, %v, %v from %v, want %v, %v, %v", i, want.Start, want.End, want.Dir, name, rays[i].Start, rays[i].End, rays[i].Dir)
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package voxelraytrace
+
+import (
+	"errors"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// Grid is a source of voxel solidity used by the hit-testing functions of the package. Implementations decide what
+// solid means for their world, for example any block with a full collision box.
+type Grid interface {
+	// Solid returns true if the voxel at the coordinates passed should stop a ray.
+	Solid(x, y, z int) bool
+}
+
+// HitResult holds the result of a ray hitting a solid voxel in a Grid.
+type HitResult struct {
+	// Pos is the position of the voxel that was hit.
+	Pos BlockPos
+	// Point is the exact point at which the ray entered the voxel that was hit.
+	Point mgl64.Vec3
+	// Face is the face of the voxel through which the ray entered it. If the ray started inside the voxel, Face is
+	// FaceNone.
+	Face Face
+	// Distance is the distance from the start of the ray to Point.
+	Distance float64
+	// StartedInside specifies if the ray started inside the voxel that was hit, for example because the start point
+	// is inside a wall. In this case, Face is FaceNone, Point is the start of the ray and Distance is 0.
+	StartedInside bool
+}
+
+// EntryFace returns the face through which the ray entered the voxel hit as an IntersectionFace, holding the normal
+// of the face along with it. If the ray started inside the voxel, NoneIntersectionFace is returned.
+func (h HitResult) EntryFace() IntersectionFace {
+	return FaceFor(h.Face)
+}
+
+// FirstSolidHit performs a ray trace between the start and end coordinates and returns the first voxel that is solid
+// in the Grid passed. The voxels are visited in the same order as they are returned by BetweenPoints. If no solid
+// voxel was found, false is returned, along with the error that stopped the trace early, if any. If the voxel the
+// ray starts in is solid, it is returned with HitResult.StartedInside set, unless WithIgnoreStartingSolid is passed.
+func FirstSolidHit(g Grid, start, end mgl64.Vec3, opts ...Option) (HitResult, bool, error) {
+	conf := newConfig(opts)
+	t, err := newTracer(start, end, conf)
+	if err != nil {
+		return HitResult{}, false, err
+	}
+	if !t.enter(conf) {
+		return HitResult{}, false, t.err
+	}
+	if err := t.bound(g); err != nil {
+		return HitResult{}, false, err
+	}
+	r := newVoxelReader(g, conf.passThrough)
+	if conf.ignoreStartingSolid && !t.skipSolid(r) {
+		return HitResult{}, false, t.err
+	}
+	chunks := newChunkSkipper(g)
+	for {
+		solid, ok := r.solid(&t)
+		if !ok {
+			return HitResult{}, false, t.err
+		}
+		if solid {
+			return t.hit(), true, nil
+		}
+		chunks.skip(&t)
+		if !t.next() {
+			return HitResult{}, false, t.err
+		}
+	}
+}
+
+// SolidHits performs a ray trace between the start and end coordinates and returns all voxels that are solid in the
+// Grid passed, in the order they are passed through. WithPierce may be passed to stop the ray after a specific
+// amount of hits, WithMergeContiguous to count thick walls as a single hit and WithIgnoreStartingSolid to skip the
+// solid voxels the ray starts in. If the trace is stopped early, the hits found so far are returned along with the
+// error.
+func SolidHits(g Grid, start, end mgl64.Vec3, opts ...Option) ([]HitResult, error) {
+	conf := newConfig(opts)
+	t, err := newTracer(start, end, conf)
+	if err != nil {
+		return nil, err
+	}
+	if !t.enter(conf) {
+		return nil, t.err
+	}
+	if err := t.bound(g); err != nil {
+		return nil, err
+	}
+	r := newVoxelReader(g, conf.passThrough)
+	if conf.ignoreStartingSolid && !t.skipSolid(r) {
+		return nil, t.err
+	}
+	var (
+		hits      []HitResult
+		prevSolid bool
+	)
+	chunks := newChunkSkipper(g)
+	for {
+		solid, ok := r.solid(&t)
+		if !ok {
+			return hits, t.err
+		}
+		if solid && !(conf.mergeContiguous && prevSolid) {
+			hits = append(hits, t.hit())
+			if conf.pierce >= 0 && len(hits) > conf.pierce {
+				return hits, nil
+			}
+		}
+		prevSolid = solid
+		if !solid {
+			chunks.skip(&t)
+		}
+		if !t.next() {
+			return hits, t.err
+		}
+	}
+}
+
+// LineOfSightTolerant performs a ray trace between the from and to coordinates and checks if there is a line of
+// sight between them, allowing up to maxBlocking solid voxels in the Grid passed to obstruct it. Every solid voxel is
+// counted separately, so a wall two voxels thick counts as two blockers, unless WithMergeContiguous is passed. The
+// blockers found are returned in the order they are passed through, which includes the one that broke the line of
+// sight if visible is false. A maxBlocking of 0 behaves like OccupancyMap.LineOfSight.
+func LineOfSightTolerant(g Grid, from, to mgl64.Vec3, maxBlocking int, opts ...Option) (visible bool, blockers []BlockPos, err error) {
+	if maxBlocking < 0 {
+		return false, nil, errors.New("max blocking voxels must not be negative")
+	}
+	hits, err := SolidHits(g, from, to, append(opts[:len(opts):len(opts)], WithPierce(maxBlocking))...)
+	for _, hit := range hits {
+		blockers = append(blockers, hit.Pos)
+	}
+	if err != nil {
+		return false, blockers, err
+	}
+	return len(hits) <= maxBlocking, blockers, nil
+}
+
+// HasLineOfSight performs a ray trace between the start and end coordinates and checks if none of the voxels passed
+// through are blocked according to the function passed, which is called with the voxels in the vector representation
+// returned by BetweenPoints. The trace stops at the first blocked voxel, and no path is collected. If the start and
+// end are the same, only the voxel containing them is checked. Like FirstSolidHit, voxels outside the bounds set using
+// WithBounds and before the distance set using WithMinDistance are not checked, so that the line of sight is blocked
+// exactly if FirstSolidHit with the same Options finds a voxel that is blocked. A trace stopped early by one of the
+// other Options passed counts as not having line of sight.
+func HasLineOfSight(start, end mgl64.Vec3, blocked func(voxel mgl64.Vec3) bool, opts ...Option) bool {
+	conf := newConfig(opts)
+	if start == end {
+		pos := VoxelAt(start, opts...)
+		if conf.bounded && !insideRegion(pos, conf.bounds.Min, conf.bounds.Max) || conf.minDistance > 0 {
+			return true
+		}
+		return !blocked(pos.Vec3Min())
+	}
+	t, err := newTracer(start, end, conf)
+	if err != nil {
+		return false
+	}
+	if !t.enter(conf) {
+		return t.err == nil
+	}
+	for {
+		if blocked(t.pos().Vec3Min()) {
+			return false
+		}
+		if !t.next() {
+			return t.err == nil
+		}
+	}
+}
+
+// hit returns a HitResult for the voxel that the tracer is currently at.
+func (t *tracer) hit() HitResult {
+	return HitResult{
+		Pos:           t.pos(),
+		Point:         t.point(t.t),
+		Face:          t.face,
+		Distance:      t.t,
+		StartedInside: t.face == FaceNone,
+	}
+}
+
+// skipSolid moves the tracer past the run of voxels that are solid in the Grid of the voxelReader passed, starting at
+// the voxel it is currently at. False is returned if the ray ends before reaching a voxel that is not solid.
+func (t *tracer) skipSolid(r voxelReader) bool {
+	for {
+		solid, ok := r.solid(t)
+		if !ok {
+			return false
+		}
+		if !solid {
+			return true
+		}
+		if !t.next() {
+			return false
+		}
+	}
+}
@@ -0,0 +1,200 @@
+package voxelraytrace
+
+import (
+	"github.com/go-gl/mathgl/mgl64"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+// wallGrid returns a SparseGrid with walls across the X axis at the X coordinates passed.
+func wallGrid(xs ...int) *SparseGrid {
+	g := NewSparseGrid()
+	for _, x := range xs {
+		for y := -2; y <= 2; y++ {
+			for z := -2; z <= 2; z++ {
+				g.Set(x, y, z, true)
+			}
+		}
+	}
+	return g
+}
+
+// hitPositions returns the positions of the voxels hit of the HitResults passed.
+func hitPositions(hits []HitResult) []BlockPos {
+	positions := make([]BlockPos, 0, len(hits))
+	for _, hit := range hits {
+		positions = append(positions, hit.Pos)
+	}
+	return positions
+}
+
+func TestSolidHitsPierce(t *testing.T) {
+	start, end := mgl64.Vec3{0.5, 0.5, 0.5}, mgl64.Vec3{20.5, 0.5, 0.5}
+	tests := []struct {
+		name string
+		g    Grid
+		opts []Option
+		want []BlockPos
+	}{
+		{
+			name: "separated walls",
+			g:    wallGrid(3, 6, 9, 12),
+			opts: []Option{WithPierce(2)},
+			want: []BlockPos{{3, 0, 0}, {6, 0, 0}, {9, 0, 0}},
+		},
+		{
+			name: "no pierce",
+			g:    wallGrid(3, 6, 9, 12),
+			opts: []Option{WithPierce(0)},
+			want: []BlockPos{{3, 0, 0}},
+		},
+		{
+			name: "unlimited",
+			g:    wallGrid(3, 6, 9, 12),
+			want: []BlockPos{{3, 0, 0}, {6, 0, 0}, {9, 0, 0}, {12, 0, 0}},
+		},
+		{
+			name: "thick wall",
+			g:    wallGrid(3, 4, 5, 9),
+			opts: []Option{WithPierce(2)},
+			want: []BlockPos{{3, 0, 0}, {4, 0, 0}, {5, 0, 0}},
+		},
+		{
+			name: "thick wall merged",
+			g:    wallGrid(3, 4, 5, 9, 12),
+			opts: []Option{WithPierce(1), WithMergeContiguous()},
+			want: []BlockPos{{3, 0, 0}, {9, 0, 0}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			hits, err := SolidHits(test.g, start, end, test.opts...)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := hitPositions(hits); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+			for _, hit := range hits {
+				if hit.Face != FaceWest || hit.Point[0] != float64(hit.Pos[0]) {
+					t.Errorf("hit %v entered through %v at %v, want the west face", hit.Pos, hit.Face, hit.Point)
+				}
+			}
+		})
+	}
+}
+
+func TestFirstSolidHitMatchesSolidHits(t *testing.T) {
+	g := wallGrid(3, 6)
+	start, end := mgl64.Vec3{0.5, 0.5, 0.5}, mgl64.Vec3{20.5, 1.5, -0.5}
+	hit, ok, err := FirstSolidHit(g, start, end)
+	if err != nil || !ok {
+		t.Fatalf("got %v, %v, want a hit", ok, err)
+	}
+	hits, _ := SolidHits(g, start, end, WithPierce(0))
+	if len(hits) != 1 || hits[0] != hit {
+		t.Errorf("got %v, want only %v", hits, hit)
+	}
+}
+
+func TestFirstSolidHitStartedInside(t *testing.T) {
+	tests := []struct {
+		name string
+		g    Grid
+		want BlockPos
+	}{
+		// The ray starts in voxel 0, which is solid, followed by a gap and another wall.
+		{name: "one deep", g: wallGrid(0, 3), want: BlockPos{3, 0, 0}},
+		{name: "several deep", g: wallGrid(0, 1, 2, 3, 6), want: BlockPos{6, 0, 0}},
+	}
+	start, end := mgl64.Vec3{0.5, 0.5, 0.5}, mgl64.Vec3{10.5, 0.5, 0.5}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			hit, ok, err := FirstSolidHit(test.g, start, end)
+			want := HitResult{Pos: BlockPos{0, 0, 0}, Point: start, Face: FaceNone, StartedInside: true}
+			if err != nil || !ok || hit != want {
+				t.Errorf("got %v, %v, %v, want %v", hit, ok, err, want)
+			}
+
+			hit, ok, err = FirstSolidHit(test.g, start, end, WithIgnoreStartingSolid())
+			if err != nil || !ok || hit.Pos != test.want || hit.StartedInside || hit.Face != FaceWest {
+				t.Errorf("got %v, %v, %v ignoring the starting solid voxels, want a hit on the west face of %v", hit, ok, err, test.want)
+			}
+			hits, err := SolidHits(test.g, start, end, WithIgnoreStartingSolid())
+			if err != nil || len(hits) != 1 || hits[0] != hit {
+				t.Errorf("got %v, %v from SolidHits, want only %v", hits, err, hit)
+			}
+		})
+	}
+
+	// A ray that never leaves the solid voxels it starts in hits nothing when ignoring them.
+	if hit, ok, err := FirstSolidHit(wallGrid(0, 1, 2), start, mgl64.Vec3{2.5, 0.5, 0.5}, WithIgnoreStartingSolid()); err != nil || ok {
+		t.Errorf("got %v, %v, %v, want no hit", hit, ok, err)
+	}
+}
+
+func TestHasLineOfSight(t *testing.T) {
+	g := wallGrid(6)
+	blocked := func(v mgl64.Vec3) bool { return g.Solid(int(v[0]), int(v[1]), int(v[2])) }
+	start, end := mgl64.Vec3{0.5, 0.5, 0.5}, mgl64.Vec3{10.5, 0.5, 0.5}
+	tests := []struct {
+		name string
+		opts []Option
+		want bool
+	}{
+		{name: "blocked", want: false},
+		{name: "bounds before wall", opts: []Option{WithBounds(BlockPos{-8, -8, -8}, BlockPos{5, 8, 8})}, want: true},
+		{name: "bounds beyond wall", opts: []Option{WithBounds(BlockPos{7, -8, -8}, BlockPos{16, 8, 8})}, want: true},
+		{name: "bounds around wall", opts: []Option{WithBounds(BlockPos{4, -8, -8}, BlockPos{8, 8, 8})}, want: false},
+		{name: "bounds missed", opts: []Option{WithBounds(BlockPos{0, 4, 0}, BlockPos{8, 8, 8})}, want: true},
+		{name: "min distance before wall", opts: []Option{WithMinDistance(3)}, want: false},
+		{name: "min distance beyond wall", opts: []Option{WithMinDistance(7)}, want: true},
+		{name: "max voxels", opts: []Option{WithMaxVoxels(3)}, want: false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := HasLineOfSight(start, end, blocked, test.opts...); got != test.want {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+
+	inside := mgl64.Vec3{6.5, 0.5, 0.5}
+	if HasLineOfSight(inside, inside, blocked) {
+		t.Error("got line of sight inside the wall")
+	}
+	if !HasLineOfSight(inside, inside, blocked, WithBounds(BlockPos{0, 0, 0}, BlockPos{5, 5, 5})) {
+		t.Error("got no line of sight inside the wall outside the bounds")
+	}
+}
+
+func TestHasLineOfSightMatchesFirstSolidHit(t *testing.T) {
+	rng := rand.New(rand.NewSource(3))
+	g := randomGrid(rng, 8, 40)
+	blocked := func(v mgl64.Vec3) bool {
+		pos := BlockPosFromVec3(v)
+		return g.Solid(pos[0], pos[1], pos[2])
+	}
+	optSets := [][]Option{
+		nil,
+		{WithBounds(BlockPos{1, 2, 0}, BlockPos{6, 5, 7})},
+		{WithMinDistance(2.5)},
+		{WithBounds(BlockPos{1, 2, 0}, BlockPos{6, 5, 7}), WithMinDistance(1.5)},
+		{WithCeilOwnership(), WithBounds(BlockPos{0, 0, 0}, BlockPos{4, 4, 4})},
+	}
+	for i := 0; i < 20000; i++ {
+		start, end := randomPoint(rng, 5).Add(mgl64.Vec3{4, 4, 4}), randomPoint(rng, 5).Add(mgl64.Vec3{4, 4, 4})
+		if start == end {
+			continue
+		}
+		opts := optSets[i%len(optSets)]
+		hit, ok, err := FirstSolidHit(g, start, end, opts...)
+		if err != nil {
+			t.Fatalf("trace %v -> %v: unexpected error: %v", start, end, err)
+		}
+		if got := HasLineOfSight(start, end, blocked, opts...); got == ok {
+			t.Fatalf("trace %v -> %v with %v options: got line of sight %v, FirstSolidHit hit %v", start, end, len(opts), got, hit.Pos)
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package voxelraytrace
+
+import (
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// GridConfig describes a grid of voxels that are not necessarily unit sized or aligned to the world origin.
+type GridConfig struct {
+	// Origin is the world space position of the minimum corner of the voxel at (0, 0, 0).
+	Origin mgl64.Vec3
+	// VoxelSize is the length of the edges of a voxel in world space.
+	VoxelSize float64
+}
+
+// VoxelFaceCenter returns the centre of the face passed of the voxel passed, in the vector representation returned
+// by BetweenPoints. For FacePosX, this is (voxel.X+1, voxel.Y+0.5, voxel.Z+0.5). VoxelFaceCenter panics if FaceNone
+// or an unknown face is passed.
+func VoxelFaceCenter(voxel mgl64.Vec3, face FaceID) mgl64.Vec3 {
+	return voxel.Add(faceCenterOffset(face))
+}
+
+// VoxelFaceCenterGrid returns the world space centre of the face passed of the voxel passed in the grid described by
+// the GridConfig. VoxelFaceCenterGrid panics if FaceNone or an unknown face is passed.
+func VoxelFaceCenterGrid(voxel mgl64.Vec3, face FaceID, cfg GridConfig) mgl64.Vec3 {
+	return cfg.Origin.Add(voxel.Add(faceCenterOffset(face)).Mul(cfg.VoxelSize))
+}
+
+// faceCenterOffset returns the offset from the minimum corner of a unit voxel to the centre of the face passed.
+func faceCenterOffset(face FaceID) mgl64.Vec3 {
+	return FaceNormal(face).Mul(0.5).Add(mgl64.Vec3{0.5, 0.5, 0.5})
+}
@@ -0,0 +1,115 @@
+package voxelraytrace
+
+// gridBox holds the bounds of a fixed-size grid, spanning w, h and d voxels from its origin on the X, Y and Z axes.
+type gridBox struct {
+	origin  BlockPos
+	w, h, d int
+}
+
+// inside checks if the coordinates passed lie inside the box.
+func (b gridBox) inside(x, y, z int) bool {
+	x, y, z = x-b.origin[0], y-b.origin[1], z-b.origin[2]
+	return x >= 0 && y >= 0 && z >= 0 && x < b.w && y < b.h && z < b.d
+}
+
+// index returns the index of the voxel at the coordinates passed in a flat array holding all voxels of the box.
+func (b gridBox) index(x, y, z int) int {
+	x, y, z = x-b.origin[0], y-b.origin[1], z-b.origin[2]
+	return (y*b.d+z)*b.w + x
+}
+
+// Bounds returns the positions of the voxels at the minimum and maximum corners of the grid, both inclusive.
+func (b gridBox) Bounds() (min, max BlockPos) {
+	return b.origin, b.origin.Add(BlockPos{b.w - 1, b.h - 1, b.d - 1})
+}
+
+// ArrayGrid is a Grid backed by a flat array of booleans, spanning w, h and d voxels from its origin. Voxels outside
+// the grid are never solid. An ArrayGrid is not safe for concurrent use if any of the goroutines using it modify it.
+type ArrayGrid struct {
+	gridBox
+	data []bool
+}
+
+// NewArrayGrid creates an ArrayGrid with all voxels empty, spanning w, h and d voxels from the origin passed.
+func NewArrayGrid(origin BlockPos, w, h, d int) *ArrayGrid {
+	return &ArrayGrid{gridBox: gridBox{origin: origin, w: w, h: h, d: d}, data: make([]bool, w*h*d)}
+}
+
+// Set sets the voxel at the coordinates passed to solid or empty. Coordinates outside the grid are ignored.
+func (g *ArrayGrid) Set(x, y, z int, solid bool) {
+	if g.inside(x, y, z) {
+		g.data[g.index(x, y, z)] = solid
+	}
+}
+
+// Solid returns true if the voxel at the coordinates passed is solid. Coordinates outside the grid are never solid.
+func (g *ArrayGrid) Solid(x, y, z int) bool {
+	return g.inside(x, y, z) && g.data[g.index(x, y, z)]
+}
+
+// BitsetGrid is a Grid backed by a bitset, spanning w, h and d voxels from its origin. It takes an eighth of the
+// memory of an ArrayGrid of the same size. Voxels outside the grid are never solid. A BitsetGrid is not safe for
+// concurrent use if any of the goroutines using it modify it.
+type BitsetGrid struct {
+	gridBox
+	bits []uint64
+}
+
+// NewBitsetGrid creates a BitsetGrid with all voxels empty, spanning w, h and d voxels from the origin passed.
+func NewBitsetGrid(origin BlockPos, w, h, d int) *BitsetGrid {
+	return &BitsetGrid{gridBox: gridBox{origin: origin, w: w, h: h, d: d}, bits: make([]uint64, (w*h*d+63)/64)}
+}
+
+// Set sets the voxel at the coordinates passed to solid or empty. Coordinates outside the grid are ignored.
+func (g *BitsetGrid) Set(x, y, z int, solid bool) {
+	if !g.inside(x, y, z) {
+		return
+	}
+	i := g.index(x, y, z)
+	if solid {
+		g.bits[i>>6] |= 1 << (uint(i) & 63)
+	} else {
+		g.bits[i>>6] &^= 1 << (uint(i) & 63)
+	}
+}
+
+// Solid returns true if the voxel at the coordinates passed is solid. Coordinates outside the grid are never solid.
+func (g *BitsetGrid) Solid(x, y, z int) bool {
+	if !g.inside(x, y, z) {
+		return false
+	}
+	i := g.index(x, y, z)
+	return g.bits[i>>6]&(1<<(uint(i)&63)) != 0
+}
+
+// SparseGrid is an unbounded Grid backed by a map holding the positions of all solid voxels. It is suited for large
+// worlds with few solid voxels. A SparseGrid is not safe for concurrent use if any of the goroutines using it modify
+// it.
+type SparseGrid struct {
+	solid map[BlockPos]struct{}
+}
+
+// NewSparseGrid creates an empty SparseGrid.
+func NewSparseGrid() *SparseGrid {
+	return &SparseGrid{solid: make(map[BlockPos]struct{})}
+}
+
+// Set sets the voxel at the coordinates passed to solid or empty.
+func (g *SparseGrid) Set(x, y, z int, solid bool) {
+	if solid {
+		g.solid[BlockPos{x, y, z}] = struct{}{}
+	} else {
+		delete(g.solid, BlockPos{x, y, z})
+	}
+}
+
+// Solid returns true if the voxel at the coordinates passed is solid.
+func (g *SparseGrid) Solid(x, y, z int) bool {
+	_, ok := g.solid[BlockPos{x, y, z}]
+	return ok
+}
+
+// Len returns the amount of solid voxels in the grid.
+func (g *SparseGrid) Len() int {
+	return len(g.solid)
+}
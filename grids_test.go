@@ -0,0 +1,111 @@
+package voxelraytrace
+
+import (
+	"github.com/go-gl/mathgl/mgl64"
+	"math/rand"
+	"testing"
+)
+
+// settableGrid is a Grid of which the voxels may be set, implemented by all reference grids of the package.
+type settableGrid interface {
+	Grid
+	Set(x, y, z int, solid bool)
+}
+
+// referenceGrids returns an ArrayGrid, a BitsetGrid and a SparseGrid with the same voxels set to solid, spanning
+// size voxels on every axis from the origin passed. The SparseGrid is unbounded.
+func referenceGrids(rng *rand.Rand, origin BlockPos, size, solid int) map[string]settableGrid {
+	grids := map[string]settableGrid{
+		"array":  NewArrayGrid(origin, size, size, size),
+		"bitset": NewBitsetGrid(origin, size, size, size),
+		"sparse": NewSparseGrid(),
+	}
+	for i := 0; i < solid; i++ {
+		x, y, z := origin[0]+rng.Intn(size), origin[1]+rng.Intn(size), origin[2]+rng.Intn(size)
+		for _, g := range grids {
+			g.Set(x, y, z, true)
+		}
+	}
+	return grids
+}
+
+func TestReferenceGridsSet(t *testing.T) {
+	origin := BlockPos{-4, 60, 3}
+	for name, g := range referenceGrids(rand.New(rand.NewSource(1)), origin, 5, 0) {
+		t.Run(name, func(t *testing.T) {
+			g.Set(-4, 60, 3, true)
+			g.Set(0, 64, 7, true)
+			g.Set(-2, 61, 4, true)
+			g.Set(-2, 61, 4, false)
+			for _, pos := range []BlockPos{{-4, 60, 3}, {0, 64, 7}} {
+				if !g.Solid(pos[0], pos[1], pos[2]) {
+					t.Errorf("%v is not solid", pos)
+				}
+			}
+			for _, pos := range []BlockPos{{-2, 61, 4}, {-3, 60, 3}, {0, 64, 6}} {
+				if g.Solid(pos[0], pos[1], pos[2]) {
+					t.Errorf("%v is solid", pos)
+				}
+			}
+		})
+	}
+}
+
+func TestFixedGridsOutOfBounds(t *testing.T) {
+	for name, g := range map[string]settableGrid{"array": NewArrayGrid(BlockPos{}, 4, 4, 4), "bitset": NewBitsetGrid(BlockPos{}, 4, 4, 4)} {
+		t.Run(name, func(t *testing.T) {
+			// Setting voxels outside the grid is ignored rather than wrapping around into the voxels inside it.
+			for _, pos := range []BlockPos{{-1, 0, 0}, {4, 0, 0}, {0, 4, 0}, {0, 0, -1}, {4, 3, 3}} {
+				g.Set(pos[0], pos[1], pos[2], true)
+				if g.Solid(pos[0], pos[1], pos[2]) {
+					t.Errorf("%v outside the grid is solid", pos)
+				}
+			}
+			for x := 0; x < 4; x++ {
+				for y := 0; y < 4; y++ {
+					for z := 0; z < 4; z++ {
+						if g.Solid(x, y, z) {
+							t.Fatalf("setting voxels outside the grid made %v solid", BlockPos{x, y, z})
+						}
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestReferenceGridsFirstSolidHit(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	grids := referenceGrids(rng, BlockPos{-8, -8, -8}, 16, 300)
+	// The rays start inside the bounds of the ArrayGrid and BitsetGrid, as they may not be traced from outside them.
+	for i := 0; i < 2000; i++ {
+		start, end := randomPoint(rng, 8), randomPoint(rng, 8)
+		if start == end {
+			continue
+		}
+		want, wantOK, _ := FirstSolidHit(grids["sparse"], start, end)
+		for _, name := range []string{"array", "bitset"} {
+			got, ok, err := FirstSolidHit(grids[name], start, end)
+			if err != nil || ok != wantOK || got != want {
+				t.Fatalf("%v: trace %v -> %v: got %v, %v, %v, want %v, %v", name, start, end, got, ok, err, want, wantOK)
+			}
+		}
+	}
+}
+
+func BenchmarkFirstSolidHitGrids(b *testing.B) {
+	grids := referenceGrids(rand.New(rand.NewSource(1)), BlockPos{}, 64, 0)
+	// A single solid voxel in the far corner makes every ray traverse the whole grid.
+	for _, g := range grids {
+		g.Set(63, 63, 63, true)
+	}
+	start, end := mgl64.Vec3{0.2, 0.3, 0.1}, mgl64.Vec3{63.9, 63.8, 63.7}
+	for _, name := range []string{"array", "bitset", "sparse"} {
+		g := grids[name]
+		b.Run(name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				_, _, _ = FirstSolidHit(g, start, end)
+			}
+		})
+	}
+}
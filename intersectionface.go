@@ -0,0 +1,55 @@
+package voxelraytrace
+
+import (
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// IntersectionFace holds a face through which a ray entered a voxel, along with the outward unit normal of that face,
+// so that callers need not compute the normal from the face every time. The IntersectionFace of a hit is returned by
+// HitResult.EntryFace. HitResult itself keeps its Face field, as existing callers compare it directly against the Face
+// constants, and an IntersectionFace would make every HitResult larger only to repeat what the face already tells.
+type IntersectionFace struct {
+	// ID is the face through which the voxel was entered.
+	ID FaceID
+	// Normal is the outward unit normal of the face, pointing back towards the side the ray came from. It is zero for
+	// FaceNone.
+	Normal mgl64.Vec3
+}
+
+// NoneIntersectionFace is the IntersectionFace of the voxel a ray starts in, which is not entered through any face.
+var NoneIntersectionFace = IntersectionFace{ID: FaceNone}
+
+// FaceFor returns the IntersectionFace for the face passed.
+func FaceFor(id FaceID) IntersectionFace {
+	return IntersectionFace{ID: id, Normal: id.Offset().Vec3Min()}
+}
+
+// FromVoxelStep returns the IntersectionFace through which a voxel is entered when stepping along the axis passed,
+// towards positive coordinates if stepSign is positive and towards negative coordinates if it is negative. A zero
+// stepSign or AxisNone returns NoneIntersectionFace.
+func FromVoxelStep(axis Axis, stepSign float64) IntersectionFace {
+	if stepSign == 0 {
+		return NoneIntersectionFace
+	}
+	step := int(compareTo(stepSign, 0))
+	switch axis {
+	case AxisX:
+		return FaceFor(entryFace(step, FaceNegX, FacePosX))
+	case AxisY:
+		return FaceFor(entryFace(step, FaceNegY, FacePosY))
+	case AxisZ:
+		return FaceFor(entryFace(step, FaceNegZ, FacePosZ))
+	}
+	return NoneIntersectionFace
+}
+
+// Opposite returns the IntersectionFace on the opposite side of the voxel.
+func (f IntersectionFace) Opposite() IntersectionFace {
+	return FaceFor(f.ID.Opposite())
+}
+
+// Axis returns the axis the face points along and whether its normal points towards positive coordinates.
+// NoneIntersectionFace returns AxisNone.
+func (f IntersectionFace) Axis() (Axis, bool) {
+	return f.ID.axis()
+}
@@ -0,0 +1,75 @@
+package voxelraytrace
+
+import (
+	"github.com/go-gl/mathgl/mgl64"
+	"testing"
+)
+
+func TestIntersectionFace(t *testing.T) {
+	tests := []struct {
+		id       FaceID
+		normal   mgl64.Vec3
+		axis     Axis
+		positive bool
+	}{
+		{id: FaceNegX, normal: mgl64.Vec3{-1, 0, 0}, axis: AxisX},
+		{id: FacePosX, normal: mgl64.Vec3{1, 0, 0}, axis: AxisX, positive: true},
+		{id: FaceNegY, normal: mgl64.Vec3{0, -1, 0}, axis: AxisY},
+		{id: FacePosY, normal: mgl64.Vec3{0, 1, 0}, axis: AxisY, positive: true},
+		{id: FaceNegZ, normal: mgl64.Vec3{0, 0, -1}, axis: AxisZ},
+		{id: FacePosZ, normal: mgl64.Vec3{0, 0, 1}, axis: AxisZ, positive: true},
+		{id: FaceNone, axis: AxisNone},
+	}
+	for _, test := range tests {
+		f := FaceFor(test.id)
+		if f.ID != test.id || f.Normal != test.normal {
+			t.Errorf("%v: got %v, want normal %v", test.id, f, test.normal)
+		}
+		if axis, positive := f.Axis(); axis != test.axis || positive != test.positive {
+			t.Errorf("%v: got axis %v, %v, want %v, %v", test.id, axis, positive, test.axis, test.positive)
+		}
+		if got := f.Opposite(); got.ID != test.id.Opposite() || got.Normal != test.normal.Mul(-1) {
+			t.Errorf("%v: got opposite %v", test.id, got)
+		}
+	}
+	if FaceFor(FaceNone) != NoneIntersectionFace {
+		t.Errorf("got %v for FaceNone, want %v", FaceFor(FaceNone), NoneIntersectionFace)
+	}
+}
+
+func TestFromVoxelStep(t *testing.T) {
+	// Stepping towards positive coordinates enters a voxel through its face pointing towards negative coordinates.
+	tests := []struct {
+		axis Axis
+		step float64
+		want FaceID
+	}{
+		{axis: AxisX, step: 1, want: FaceNegX},
+		{axis: AxisX, step: -0.5, want: FacePosX},
+		{axis: AxisY, step: 2, want: FaceNegY},
+		{axis: AxisY, step: -1, want: FacePosY},
+		{axis: AxisZ, step: 1, want: FaceNegZ},
+		{axis: AxisZ, step: -1, want: FacePosZ},
+		{axis: AxisX, step: 0, want: FaceNone},
+		{axis: AxisNone, step: 1, want: FaceNone},
+	}
+	for _, test := range tests {
+		if got := FromVoxelStep(test.axis, test.step); got != FaceFor(test.want) {
+			t.Errorf("%v, %v: got %v, want %v", test.axis, test.step, got, FaceFor(test.want))
+		}
+	}
+}
+
+func TestHitResultEntryFace(t *testing.T) {
+	hit, ok, err := FirstSolidHit(wallGrid(3), mgl64.Vec3{0.5, 0.5, 0.5}, mgl64.Vec3{8.5, 0.5, 0.5})
+	if err != nil || !ok {
+		t.Fatalf("got %v, %v, want a hit", ok, err)
+	}
+	if got := hit.EntryFace(); got.ID != hit.Face || got.Normal != (mgl64.Vec3{-1, 0, 0}) {
+		t.Errorf("got %v, want the west face with a normal pointing back along the ray", got)
+	}
+	inside := HitResult{Face: FaceNone, StartedInside: true}
+	if got := inside.EntryFace(); got != NoneIntersectionFace {
+		t.Errorf("got %v for a ray starting inside the voxel, want %v", got, NoneIntersectionFace)
+	}
+}
@@ -0,0 +1,81 @@
+package voxelraytrace
+
+import (
+	"github.com/go-gl/mathgl/mgl64"
+	"math"
+)
+
+// canonicalLine is a segment traced by a ray, stored in a canonical orientation that does not depend on the direction
+// in which the segment is traced. Voxel boundary crossings are computed directly from it, rather than accumulated
+// step by step, so that tracing a segment from either end computes exactly the same crossings and thus passes
+// through the same voxels, in reverse order.
+type canonicalLine struct {
+	// origin and far are the endpoints of the segment in grid space. origin is the endpoint that comes first when
+	// comparing the X, then Y, then Z coordinates of the Y-up convention, unless the line was created for a ray with
+	// a direction vector.
+	origin, far mgl64.Vec3
+	// dir is the normalised direction from origin to far, divided by the cell size, and length the world space
+	// distance between them.
+	dir    mgl64.Vec3
+	length float64
+	// reversed specifies if the ray travels from far to origin.
+	reversed bool
+	// directed specifies if the line was created for a ray with a direction vector, in which case origin is the
+	// start of the ray and the line may be made longer by moving far.
+	directed bool
+}
+
+// newCanonicalLine returns the canonicalLine for a ray travelling from the start to the end coordinates, which are
+// compared and combined in the axisOrder passed.
+func newCanonicalLine(start, end mgl64.Vec3, order axisOrder) canonicalLine {
+	reversed := order.less(end, start)
+	if reversed {
+		start, end = end, start
+	}
+	diff := end.Sub(start)
+	return canonicalLine{origin: start, far: end, dir: order.normalize(diff), length: order.length(diff), reversed: reversed}
+}
+
+// toCells returns the line with its endpoints and direction divided by the cell size of the config passed, so that
+// its voxel boundaries lie on integer coordinates. The length is kept, so that distances along the line remain in
+// world space.
+func (l canonicalLine) toCells(conf config) canonicalLine {
+	l.origin, l.far, l.dir = conf.toCells(l.origin), conf.toCells(l.far), conf.toCells(l.dir)
+	return l
+}
+
+// direction returns the normalised direction in which the ray travels along the line.
+func (l canonicalLine) direction() mgl64.Vec3 {
+	if l.reversed {
+		return l.dir.Mul(-1)
+	}
+	return l.dir
+}
+
+// crossing returns the distance along the ray at which it leaves the voxel at the coordinate passed on an axis, when
+// travelling with the step passed on that axis. Boundaries at the endpoints of the line are crossed at exactly the
+// start or end of the ray. The order key returned orders crossings the same way as the distance, but is computed
+// exactly from the canonical position of the crossing on the line, so that two crossings only compare equal when
+// they would do so for a ray travelling in the opposite direction.
+func (l canonicalLine) crossing(axis, coord, step int) (dist, key float64) {
+	if step == 0 {
+		return math.Inf(1), math.Inf(1)
+	}
+	boundary := float64(coord)
+	if step > 0 {
+		boundary++
+	}
+	var c float64
+	switch boundary {
+	case l.origin[axis]:
+		c = 0
+	case l.far[axis]:
+		c = l.length
+	default:
+		c = (boundary - l.origin[axis]) / l.dir[axis]
+	}
+	if l.reversed {
+		return l.length - c, -c
+	}
+	return c, c
+}
@@ -0,0 +1,190 @@
+package voxelraytrace
+
+import (
+	"sync"
+)
+
+// losKey is the key of a line of sight cached by a LOSCache.
+type losKey [2]BlockPos
+
+// losEntry is a line of sight cached by a LOSCache.
+type losEntry struct {
+	visible bool
+	// path holds the voxels that the result depends on: every voxel passed through if the line of sight is clear, or
+	// the voxels up to and including the first solid voxel if it is not.
+	path []BlockPos
+	// seq is the sequence number of the entry, used to tell apart entries for the same key in the eviction queue.
+	seq uint64
+}
+
+// losRef refers to an entry in the eviction queue of a LOSCache.
+type losRef struct {
+	key losKey
+	seq uint64
+}
+
+// LOSCache caches the results of line of sight checks between voxels in a Grid, for callers that repeatedly check
+// the same pairs of voxels while the Grid rarely changes. Every cached result remembers the voxels it depends on, so
+// that Invalidate can evict exactly the results affected by a change to a voxel. Once the maximum amount of entries
+// is reached, the oldest entry is evicted first. A LOSCache is safe for concurrent use, as long as the Grid it wraps
+// is safe for concurrent reads. Cache hits only take a read lock.
+type LOSCache struct {
+	g          Grid
+	maxEntries int
+
+	mu      sync.RWMutex
+	entries map[losKey]*losEntry
+	// index holds, for every voxel, the keys of the entries whose path passes through it.
+	index map[BlockPos]map[losKey]struct{}
+	// queue holds the entries in the order they were added. It may contain references to entries that were since
+	// removed, which are skipped.
+	queue []losRef
+	seq   uint64
+	// generation is incremented by every invalidation, so that results traced concurrently with an invalidation are
+	// not stored.
+	generation uint64
+}
+
+// NewLOSCache creates a LOSCache for the Grid passed, holding at most maxEntries results. If maxEntries is 0 or
+// less, the amount of entries is not limited.
+func NewLOSCache(g Grid, maxEntries int) *LOSCache {
+	return &LOSCache{
+		g:          g,
+		maxEntries: maxEntries,
+		entries:    make(map[losKey]*losEntry),
+		index:      make(map[BlockPos]map[losKey]struct{}),
+	}
+}
+
+// Visible checks if there is a line of sight between the centres of the from and to voxels, returning a cached
+// result if there is one. Like OccupancyMap.LineOfSight, there is only a line of sight if none of the voxels passed
+// through are solid, including the from and to voxels themselves.
+func (c *LOSCache) Visible(from, to BlockPos) bool {
+	key := losKey{from, to}
+	c.mu.RLock()
+	e, ok := c.entries[key]
+	generation := c.generation
+	c.mu.RUnlock()
+	if ok {
+		return e.visible
+	}
+
+	visible, path := c.trace(from, to)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.generation != generation {
+		// The Grid changed while tracing, so the result may already be outdated.
+		return visible
+	}
+	if _, ok := c.entries[key]; !ok {
+		c.add(key, visible, path)
+	}
+	return visible
+}
+
+// Invalidate evicts every cached result that depends on the voxel passed. It must be called whenever the solidity
+// of a voxel in the Grid changes.
+func (c *LOSCache) Invalidate(pos BlockPos) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.generation++
+	for key := range c.index[pos] {
+		c.remove(key)
+	}
+}
+
+// Clear evicts all cached results.
+func (c *LOSCache) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.generation++
+	c.entries = make(map[losKey]*losEntry)
+	c.index = make(map[BlockPos]map[losKey]struct{})
+	c.queue = nil
+}
+
+// Len returns the amount of results currently cached.
+func (c *LOSCache) Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.entries)
+}
+
+// trace checks if there is a line of sight between the centres of the from and to voxels, and returns the voxels
+// that the result depends on.
+func (c *LOSCache) trace(from, to BlockPos) (visible bool, path []BlockPos) {
+	if from == to {
+		return !c.g.Solid(from[0], from[1], from[2]), []BlockPos{from}
+	}
+	t, err := newTracer(from.Vec3Centre(), to.Vec3Centre(), newConfig(nil))
+	if err != nil {
+		// The centres of two different voxels are never the same, so this cannot happen.
+		panic("voxelraytrace: " + err.Error())
+	}
+	for {
+		path = append(path, t.pos())
+		if c.g.Solid(t.x, t.y, t.z) {
+			return false, path
+		}
+		if !t.next() {
+			return true, path
+		}
+	}
+}
+
+// add adds an entry for the key passed, evicting the oldest entries if the cache is full. It must be called with the
+// write lock held.
+func (c *LOSCache) add(key losKey, visible bool, path []BlockPos) {
+	for c.maxEntries > 0 && len(c.entries) >= c.maxEntries && len(c.queue) > 0 {
+		ref := c.queue[0]
+		c.queue = c.queue[1:]
+		if e, ok := c.entries[ref.key]; ok && e.seq == ref.seq {
+			c.remove(ref.key)
+		}
+	}
+	if len(c.queue) > 2*len(c.entries)+16 {
+		c.compact()
+	}
+
+	c.seq++
+	c.entries[key] = &losEntry{visible: visible, path: path, seq: c.seq}
+	c.queue = append(c.queue, losRef{key: key, seq: c.seq})
+	for _, pos := range path {
+		keys, ok := c.index[pos]
+		if !ok {
+			keys = make(map[losKey]struct{})
+			c.index[pos] = keys
+		}
+		keys[key] = struct{}{}
+	}
+}
+
+// remove removes the entry with the key passed from the cache. It must be called with the write lock held.
+func (c *LOSCache) remove(key losKey) {
+	e, ok := c.entries[key]
+	if !ok {
+		return
+	}
+	delete(c.entries, key)
+	for _, pos := range e.path {
+		if keys, ok := c.index[pos]; ok {
+			delete(keys, key)
+			if len(keys) == 0 {
+				delete(c.index, pos)
+			}
+		}
+	}
+}
+
+// compact removes the references to entries that no longer exist from the eviction queue. It must be called with the
+// write lock held.
+func (c *LOSCache) compact() {
+	queue := make([]losRef, 0, len(c.entries))
+	for _, ref := range c.queue {
+		if e, ok := c.entries[ref.key]; ok && e.seq == ref.seq {
+			queue = append(queue, ref)
+		}
+	}
+	c.queue = queue
+}
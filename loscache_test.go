@@ -0,0 +1,86 @@
+package voxelraytrace
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestLOSCacheInvalidate(t *testing.T) {
+	g := NewSparseGrid()
+	c := NewLOSCache(g, 0)
+	from, to := BlockPos{0, 0, 0}, BlockPos{8, 0, 0}
+	if !c.Visible(from, to) {
+		t.Fatal("no line of sight in an empty grid")
+	}
+
+	// Without invalidating the voxel, the cached result is returned even though the voxel became solid.
+	g.Set(4, 0, 0, true)
+	if !c.Visible(from, to) {
+		t.Fatal("cached line of sight was not returned")
+	}
+	// Invalidating a voxel the line of sight does not pass through keeps the result.
+	c.Invalidate(BlockPos{4, 1, 0})
+	if c.Len() != 1 {
+		t.Fatalf("invalidating an unrelated voxel evicted the result")
+	}
+	c.Invalidate(BlockPos{4, 0, 0})
+	if c.Len() != 0 || c.Visible(from, to) {
+		t.Fatal("line of sight through a voxel that became solid is still visible after invalidating it")
+	}
+
+	// A blocked line of sight only depends on the voxels up to the first solid one, so a voxel beyond it does not
+	// evict it, but removing the blocking voxel does.
+	g.Set(4, 0, 0, false)
+	c.Invalidate(BlockPos{6, 0, 0})
+	if c.Visible(from, to) {
+		t.Fatal("blocked line of sight was evicted by a voxel beyond the blocking voxel")
+	}
+	c.Invalidate(BlockPos{4, 0, 0})
+	if !c.Visible(from, to) {
+		t.Fatal("line of sight is still blocked after removing the blocking voxel")
+	}
+}
+
+func TestLOSCacheMaxEntries(t *testing.T) {
+	c := NewLOSCache(NewSparseGrid(), 4)
+	for x := 1; x <= 10; x++ {
+		c.Visible(BlockPos{}, BlockPos{x, 0, 0})
+		if c.Len() > 4 {
+			t.Fatalf("got %v entries, want at most 4", c.Len())
+		}
+	}
+	// The oldest entries are evicted first, so only the last four remain.
+	c.Invalidate(BlockPos{6, 0, 0})
+	if c.Len() != 0 {
+		t.Errorf("got %v entries passing through %v after invalidating it, want 0", c.Len(), BlockPos{6, 0, 0})
+	}
+	c.Visible(BlockPos{}, BlockPos{1, 0, 0})
+	c.Visible(BlockPos{}, BlockPos{2, 0, 0})
+	c.Clear()
+	if c.Len() != 0 {
+		t.Errorf("got %v entries after clearing the cache, want 0", c.Len())
+	}
+}
+
+func TestLOSCacheConcurrent(t *testing.T) {
+	g := wallGrid(4)
+	c := NewLOSCache(g, 16)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				from, to := BlockPos{0, j % 3, 0}, BlockPos{8, (i + j) % 3, 0}
+				if c.Visible(from, to) {
+					t.Errorf("line of sight %v -> %v through a wall is visible", from, to)
+					return
+				}
+				if j%50 == 0 {
+					c.Invalidate(BlockPos{2, 0, 0})
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,102 @@
+package voxelraytrace
+
+import (
+	"github.com/go-gl/mathgl/mgl64"
+	"runtime"
+	"sync"
+)
+
+// LOSMatrix computes the line of sight between every pair of the points passed, such as for building a visibility
+// graph of waypoints, and returns it as a matrix in which matrix[i][j] is true if the points i and j can see each
+// other. Two points can see each other if none of the voxels passed through by the ray between them are solid, like
+// OccupancyMap.LineOfSight. As traces are symmetric, the matrix is as well, and every pair of points is only traced
+// once. Every point can see itself, while two different points at the same position can see each other if the voxel
+// containing them is not solid.
+// The pairs are traced by one goroutine per CPU, so the Grid passed must be safe for concurrent use by readers. If
+// any of the points lies outside the bounds of a BoundedGrid, an OutOfBoundsError is returned. If any trace fails,
+// such as when a FallibleGrid fails to look up a voxel, the error is returned.
+func LOSMatrix(g Grid, points []mgl64.Vec3) ([][]bool, error) {
+	if bg, ok := g.(BoundedGrid); ok {
+		// Rays only fail when starting outside the bounds, which depends on the order of the points, so all points
+		// are checked first.
+		min, max := bg.Bounds()
+		for _, p := range points {
+			if pos := BlockPosFromVec3(p); !insideRegion(pos, min, max) {
+				return nil, OutOfBoundsError{Pos: pos}
+			}
+		}
+	}
+	n := len(points)
+	cells, matrix := make([]bool, n*n), make([][]bool, n)
+	for i := range matrix {
+		matrix[i] = cells[i*n : (i+1)*n : (i+1)*n]
+		matrix[i][i] = true
+	}
+	workers := runtime.GOMAXPROCS(0)
+	if workers > n {
+		workers = n
+	}
+
+	// Every row i holds the pairs of point i with the points after it, so the rows are handed out in order, which
+	// starts with the rows holding the most pairs.
+	rows, rowErrs := make(chan int), make([]error, n)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// Every goroutine has its own cache of the regions of a RegionGrid.
+			r := newVoxelReader(g, nil)
+			for i := range rows {
+				for j := i + 1; j < n; j++ {
+					visible, err := lineOfSight(g, r, points[i], points[j])
+					if err != nil {
+						rowErrs[i] = err
+						break
+					}
+					matrix[i][j], matrix[j][i] = visible, visible
+				}
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		rows <- i
+	}
+	close(rows)
+	wg.Wait()
+
+	for _, err := range rowErrs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return matrix, nil
+}
+
+// lineOfSight checks if none of the voxels passed through by the ray between the points a and b are solid. The bounds
+// of the Grid g are respected, while the voxels are looked up using the voxelReader passed.
+func lineOfSight(g Grid, r voxelReader, a, b mgl64.Vec3) (bool, error) {
+	if a == b {
+		solid, err := r.at(BlockPosFromVec3(a), 0)
+		return !solid && err == nil, err
+	}
+	t, err := newTracer(a, b, newConfig(nil))
+	if err != nil {
+		return false, err
+	}
+	if err := t.bound(g); err != nil {
+		return false, err
+	}
+	for {
+		solid, ok := r.solid(&t)
+		if !ok {
+			return false, t.err
+		}
+		if solid {
+			return false, nil
+		}
+		if !t.next() {
+			return true, nil
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package voxelraytrace
+
+import (
+	"errors"
+	"github.com/go-gl/mathgl/mgl64"
+	"math/rand"
+	"testing"
+)
+
+// naiveLineOfSight checks if none of the voxels passed through by a ray between the points passed are solid, by
+// tracing the ray using BetweenPoints.
+func naiveLineOfSight(g Grid, a, b mgl64.Vec3) bool {
+	if a == b {
+		pos := BlockPosFromVec3(a)
+		return !g.Solid(pos[0], pos[1], pos[2])
+	}
+	positions, _ := BetweenPoints(a, b)
+	for _, p := range positions {
+		if g.Solid(int(p[0]), int(p[1]), int(p[2])) {
+			return false
+		}
+	}
+	return true
+}
+
+// losPoints returns a grid of random solid voxels and n random points in it, some of which lie in solid voxels.
+func losPoints(rng *rand.Rand, n int) (*SparseGrid, []mgl64.Vec3) {
+	g := NewSparseGrid()
+	for i := 0; i < 300; i++ {
+		g.Set(rng.Intn(32)-16, rng.Intn(32)-16, rng.Intn(32)-16, true)
+	}
+	points := make([]mgl64.Vec3, n)
+	for i := range points {
+		points[i] = randomPoint(rng, 16)
+	}
+	return g, points
+}
+
+func TestLOSMatrixMatchesNaive(t *testing.T) {
+	g, points := losPoints(rand.New(rand.NewSource(1)), 60)
+	// A point at the same position as another one.
+	points = append(points, points[3])
+	matrix, err := LOSMatrix(g, points)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for i := range points {
+		if !matrix[i][i] {
+			t.Errorf("point %v cannot see itself", i)
+		}
+		for j := range points {
+			if i == j {
+				continue
+			}
+			if want := naiveLineOfSight(g, points[i], points[j]); matrix[i][j] != want {
+				t.Fatalf("points %v and %v: got %v, want %v", points[i], points[j], matrix[i][j], want)
+			}
+		}
+	}
+}
+
+func TestLOSMatrix(t *testing.T) {
+	if matrix, err := LOSMatrix(NewSparseGrid(), nil); err != nil || len(matrix) != 0 {
+		t.Errorf("got %v, %v for no points, want an empty matrix", matrix, err)
+	}
+	g := NewArrayGrid(BlockPos{}, 8, 8, 8)
+	// The point outside the grid is reported wherever it is, even though it is only the start of a ray if it is not
+	// the last point.
+	for _, points := range [][]mgl64.Vec3{
+		{{10.5, 1.5, 1.5}, {1.5, 1.5, 1.5}, {4.5, 4.5, 4.5}},
+		{{1.5, 1.5, 1.5}, {4.5, 4.5, 4.5}, {10.5, 1.5, 1.5}},
+	} {
+		_, err := LOSMatrix(g, points)
+		if want := (OutOfBoundsError{Pos: BlockPos{10, 1, 1}}); !errors.Is(err, ErrOutOfBounds) || err != want {
+			t.Errorf("got %v for a point outside the grid, want %v", err, want)
+		}
+	}
+}
+
+func BenchmarkLOSMatrix(b *testing.B) {
+	g, points := losPoints(rand.New(rand.NewSource(1)), 200)
+	b.Run("matrix", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_, _ = LOSMatrix(g, points)
+		}
+	})
+	b.Run("naive", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for _, p := range points {
+				for _, q := range points {
+					_ = naiveLineOfSight(g, p, q)
+				}
+			}
+		}
+	})
+}
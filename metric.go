@@ -0,0 +1,31 @@
+package voxelraytrace
+
+import (
+	"github.com/go-gl/mathgl/mgl64"
+	"math"
+)
+
+// VoxelManhattan returns the Manhattan distance between the voxels containing the points passed, which is the amount
+// of steps between neighbouring voxels sharing a face needed to move from one to the other. The points are floored
+// before the distance is computed.
+func VoxelManhattan(a, b mgl64.Vec3) float64 {
+	d := voxelDelta(a, b)
+	return d[0] + d[1] + d[2]
+}
+
+// VoxelChebyshev returns the Chebyshev distance between the voxels containing the points passed, which is the amount
+// of steps between neighbouring voxels sharing a face, edge or corner needed to move from one to the other. The
+// points are floored before the distance is computed.
+func VoxelChebyshev(a, b mgl64.Vec3) float64 {
+	d := voxelDelta(a, b)
+	return math.Max(d[0], math.Max(d[1], d[2]))
+}
+
+// voxelDelta returns the absolute difference between the voxels containing the points passed on every axis.
+func voxelDelta(a, b mgl64.Vec3) mgl64.Vec3 {
+	return mgl64.Vec3{
+		math.Abs(math.Floor(a[0]) - math.Floor(b[0])),
+		math.Abs(math.Floor(a[1]) - math.Floor(b[1])),
+		math.Abs(math.Floor(a[2]) - math.Floor(b[2])),
+	}
+}
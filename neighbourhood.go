@@ -0,0 +1,39 @@
+package voxelraytrace
+
+import (
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// VoxelNeighborhood26 returns the 26 voxels sharing a face, edge or corner with the voxel passed, as vectors in the
+// same representation as returned by BetweenPoints. The voxel passed is floored first, so that any point inside it
+// may be passed. The neighbours are ordered lexicographically by their offset (dx, dy, dz), with every offset ranging
+// from -1 to 1 and the offset (0, 0, 0) left out, so that the first neighbour is at (-1, -1, -1), the second at
+// (-1, -1, 0) and the last at (1, 1, 1).
+func VoxelNeighborhood26(voxel mgl64.Vec3) [26]mgl64.Vec3 {
+	pos := BlockPosFromVec3(voxel)
+	var neighbours [26]mgl64.Vec3
+	i := 0
+	for dx := -1; dx <= 1; dx++ {
+		for dy := -1; dy <= 1; dy++ {
+			for dz := -1; dz <= 1; dz++ {
+				if dx == 0 && dy == 0 && dz == 0 {
+					continue
+				}
+				neighbours[i] = pos.Add(BlockPos{dx, dy, dz}).Vec3Min()
+				i++
+			}
+		}
+	}
+	return neighbours
+}
+
+// VoxelNeighborhood6 returns the 6 voxels sharing a face with the voxel passed, as vectors in the same representation
+// as returned by BetweenPoints. The voxel passed is floored first. The neighbours are ordered like those returned by
+// BlockPos.Neighbours.
+func VoxelNeighborhood6(voxel mgl64.Vec3) [6]mgl64.Vec3 {
+	var neighbours [6]mgl64.Vec3
+	for i, pos := range BlockPosFromVec3(voxel).Neighbours() {
+		neighbours[i] = pos.Vec3Min()
+	}
+	return neighbours
+}
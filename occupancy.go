@@ -0,0 +1,193 @@
+package voxelraytrace
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"github.com/go-gl/mathgl/mgl64"
+	"sort"
+)
+
+// occupancyMagic is the magic at the start of an OccupancyMap encoded using MarshalBinary.
+const occupancyMagic = "VXOM"
+
+// occupancyVersion is the current version of the binary format of an OccupancyMap.
+const occupancyVersion = 1
+
+// OccupancyMap is a static, read-optimised set of occupied voxels, such as those of a precomputed voxel model. The
+// voxels are stored as a sorted list of positions, which keeps the map compact and lookups logarithmic. An
+// OccupancyMap implements Grid, with the occupied voxels being solid. It is safe for concurrent use, as long as
+// UnmarshalBinary is not called concurrently.
+type OccupancyMap struct {
+	positions []BlockPos
+}
+
+// NewOccupancyMapFromSlice creates an OccupancyMap with the voxels passed occupied. Duplicate voxels are removed.
+func NewOccupancyMapFromSlice(voxels []mgl64.Vec3) *OccupancyMap {
+	positions := make([]BlockPos, len(voxels))
+	for i, v := range voxels {
+		positions[i] = BlockPosFromVec3(v)
+	}
+	sortBlockPos(positions)
+	unique := positions[:0]
+	for i, pos := range positions {
+		if i == 0 || pos != positions[i-1] {
+			unique = append(unique, pos)
+		}
+	}
+	return &OccupancyMap{positions: unique}
+}
+
+// Contains returns true if the voxel containing the vector passed is occupied.
+func (m *OccupancyMap) Contains(v mgl64.Vec3) bool {
+	return m.contains(BlockPosFromVec3(v))
+}
+
+// Solid returns true if the voxel at the coordinates passed is occupied.
+func (m *OccupancyMap) Solid(x, y, z int) bool {
+	return m.contains(BlockPos{x, y, z})
+}
+
+// Len returns the amount of occupied voxels in the map.
+func (m *OccupancyMap) Len() int {
+	return len(m.positions)
+}
+
+// TraceFirst performs a ray trace between the start and end coordinates and returns the first occupied voxel passed
+// through. If no occupied voxel was passed through, false is returned.
+func (m *OccupancyMap) TraceFirst(start, end mgl64.Vec3) (mgl64.Vec3, bool, error) {
+	hit, ok, err := FirstSolidHit(m, start, end)
+	if !ok || err != nil {
+		return mgl64.Vec3{}, false, err
+	}
+	return hit.Pos.Vec3Min(), true, nil
+}
+
+// LineOfSight performs a ray trace between the start and end coordinates and returns true if none of the voxels
+// passed through are occupied.
+func (m *OccupancyMap) LineOfSight(start, end mgl64.Vec3) (bool, error) {
+	_, ok, err := FirstSolidHit(m, start, end)
+	if err != nil {
+		return false, err
+	}
+	return !ok, nil
+}
+
+// MarshalBinary encodes the OccupancyMap into a compact binary format. The format starts with a header of the
+// four byte magic "VXOM", a version byte and the amount of voxels as an unsigned varint. The header is followed by
+// the voxels in ascending X, Y, Z order, each written as the differences of its X, Y and Z coordinates with the
+// previous voxel (or zero for the first voxel) as signed varints.
+func (m *OccupancyMap) MarshalBinary() ([]byte, error) {
+	return encodePositions(m.positions), nil
+}
+
+// UnmarshalBinary decodes an OccupancyMap encoded using MarshalBinary, replacing the voxels currently in the map.
+func (m *OccupancyMap) UnmarshalBinary(data []byte) error {
+	positions, err := decodePositions(data)
+	if err != nil {
+		return fmt.Errorf("occupancy map: %w", err)
+	}
+	m.positions = positions
+	return nil
+}
+
+// encodePositions encodes a list of positions sorted in ascending X, Y, Z order into the format described in
+// OccupancyMap.MarshalBinary.
+func encodePositions(positions []BlockPos) []byte {
+	buf := make([]byte, 0, len(occupancyMagic)+1+binary.MaxVarintLen64+len(positions)*3)
+	buf = append(buf, occupancyMagic...)
+	buf = append(buf, occupancyVersion)
+	buf = appendUvarint(buf, uint64(len(positions)))
+
+	var prev BlockPos
+	for _, pos := range positions {
+		for i := 0; i < 3; i++ {
+			buf = appendVarint(buf, int64(pos[i]-prev[i]))
+		}
+		prev = pos
+	}
+	return buf
+}
+
+// decodePositions decodes a list of positions encoded using encodePositions. The positions are validated to be
+// unique and in ascending X, Y, Z order.
+func decodePositions(data []byte) ([]BlockPos, error) {
+	if !bytes.HasPrefix(data, []byte(occupancyMagic)) {
+		return nil, errors.New("invalid magic")
+	}
+	data = data[len(occupancyMagic):]
+	if len(data) == 0 {
+		return nil, errors.New("missing version")
+	}
+	if data[0] != occupancyVersion {
+		return nil, fmt.Errorf("unsupported version %v", data[0])
+	}
+	r := bytes.NewReader(data[1:])
+	n, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, fmt.Errorf("read voxel count: %w", err)
+	}
+	// Every voxel takes at least three bytes, so this guards against huge allocations for malformed data.
+	if n > uint64(r.Len()/3) {
+		return nil, errors.New("voxel count exceeds data length")
+	}
+
+	positions := make([]BlockPos, n)
+	var prev BlockPos
+	for i := range positions {
+		for j := 0; j < 3; j++ {
+			d, err := binary.ReadVarint(r)
+			if err != nil {
+				return nil, fmt.Errorf("read voxel %v: %w", i, err)
+			}
+			positions[i][j] = prev[j] + int(d)
+		}
+		if i > 0 && !lessBlockPos(prev, positions[i]) {
+			return nil, fmt.Errorf("voxel %v is not in ascending order", i)
+		}
+		prev = positions[i]
+	}
+	if r.Len() != 0 {
+		return nil, errors.New("trailing data")
+	}
+	return positions, nil
+}
+
+// contains checks if the position passed is in the map using a binary search.
+func (m *OccupancyMap) contains(pos BlockPos) bool {
+	i := sort.Search(len(m.positions), func(i int) bool {
+		return !lessBlockPos(m.positions[i], pos)
+	})
+	return i < len(m.positions) && m.positions[i] == pos
+}
+
+// sortBlockPos sorts the positions passed in ascending X, Y, Z order.
+func sortBlockPos(positions []BlockPos) {
+	sort.Slice(positions, func(i, j int) bool {
+		return lessBlockPos(positions[i], positions[j])
+	})
+}
+
+// lessBlockPos checks if a comes before b when ordering by X, then Y, then Z.
+func lessBlockPos(a, b BlockPos) bool {
+	if a[0] != b[0] {
+		return a[0] < b[0]
+	}
+	if a[1] != b[1] {
+		return a[1] < b[1]
+	}
+	return a[2] < b[2]
+}
+
+// appendUvarint appends the unsigned varint encoding of v to buf.
+func appendUvarint(buf []byte, v uint64) []byte {
+	var b [binary.MaxVarintLen64]byte
+	return append(buf, b[:binary.PutUvarint(b[:], v)]...)
+}
+
+// appendVarint appends the signed varint encoding of v to buf.
+func appendVarint(buf []byte, v int64) []byte {
+	var b [binary.MaxVarintLen64]byte
+	return append(buf, b[:binary.PutVarint(b[:], v)]...)
+}
@@ -0,0 +1,220 @@
+package voxelraytrace
+
+import (
+	"fmt"
+	"github.com/go-gl/mathgl/mgl64"
+	"math"
+)
+
+// Octree is a sparse voxel octree: a BoundedGrid spanning a cube of 2^depth voxels on every axis, in which every
+// octant without solid voxels is stored as a single empty node. Octree.FirstSolidHit uses these nodes to skip over
+// empty space without stepping through every voxel, which makes traces through mostly empty worlds much cheaper.
+// Voxels outside the cube are never solid. An Octree is not safe for concurrent use if any of the goroutines using it
+// modify it.
+type Octree struct {
+	origin BlockPos
+	depth  int
+	root   *octreeNode
+}
+
+// octreeNode is a node of an Octree. A nil child is an octant without any solid voxels, and a node at the lowest level
+// of the tree is a solid voxel. The children are indexed by the octant, with bit 0 set for the upper half on the X
+// axis, bit 1 for the Y axis and bit 2 for the Z axis.
+type octreeNode struct {
+	children [8]*octreeNode
+}
+
+// NewOctree creates an Octree with all voxels empty, spanning 2^depth voxels on every axis from the origin passed.
+// NewOctree panics if the depth is negative or larger than 30.
+func NewOctree(origin BlockPos, depth int) *Octree {
+	if depth < 0 || depth > 30 {
+		panic(fmt.Sprintf("voxelraytrace: octree depth %v out of range [0, 30]", depth))
+	}
+	return &Octree{origin: origin, depth: depth}
+}
+
+// Bounds returns the positions of the voxels at the minimum and maximum corners of the octree, both inclusive.
+func (o *Octree) Bounds() (min, max BlockPos) {
+	n := 1<<uint(o.depth) - 1
+	return o.origin, o.origin.Add(BlockPos{n, n, n})
+}
+
+// Set sets the voxel at the coordinates passed to solid or empty. Coordinates outside the octree are ignored. Nodes
+// left without solid voxels are removed, so that the empty space they held can be skipped.
+func (o *Octree) Set(x, y, z int, solid bool) {
+	if min, max := o.Bounds(); insideRegion(BlockPos{x, y, z}, min, max) {
+		o.root = o.root.set(BlockPos{x - o.origin[0], y - o.origin[1], z - o.origin[2]}, o.depth, solid)
+	}
+}
+
+// set sets the voxel at the position passed, relative to the minimum corner of the node, in the node at the level
+// passed, and returns the node that replaces it, which is nil if it no longer holds any solid voxels.
+func (n *octreeNode) set(rel BlockPos, level int, solid bool) *octreeNode {
+	if level == 0 {
+		if solid {
+			return &octreeNode{}
+		}
+		return nil
+	}
+	if n == nil {
+		if !solid {
+			return nil
+		}
+		n = &octreeNode{}
+	}
+	half, i := 1<<uint(level-1), 0
+	for axis := 0; axis < 3; axis++ {
+		if rel[axis] >= half {
+			i |= 1 << uint(axis)
+			rel[axis] -= half
+		}
+	}
+	n.children[i] = n.children[i].set(rel, level-1, solid)
+	for _, c := range n.children {
+		if c != nil {
+			return n
+		}
+	}
+	return nil
+}
+
+// Solid returns true if the voxel at the coordinates passed is solid. Coordinates outside the octree are never solid.
+func (o *Octree) Solid(x, y, z int) bool {
+	pos := BlockPos{x, y, z}
+	if min, max := o.Bounds(); !insideRegion(pos, min, max) {
+		return false
+	}
+	_, _, empty := o.emptyRegion(pos)
+	return !empty
+}
+
+// emptyRegion returns the minimum and maximum corners, both inclusive, of the largest octant without solid voxels
+// that holds the voxel at the position passed, which must lie inside the octree. If the voxel is solid, false is
+// returned.
+func (o *Octree) emptyRegion(pos BlockPos) (min, max BlockPos, empty bool) {
+	n, min, size := o.root, o.origin, 1<<uint(o.depth)
+	for n != nil {
+		if size == 1 {
+			return pos, pos, false
+		}
+		size >>= 1
+		i := 0
+		for axis := 0; axis < 3; axis++ {
+			if pos[axis]-min[axis] >= size {
+				i |= 1 << uint(axis)
+				min[axis] += size
+			}
+		}
+		n = n.children[i]
+	}
+	return min, min.Add(BlockPos{size - 1, size - 1, size - 1}), true
+}
+
+// FirstSolidHit performs a ray trace between the start and end coordinates and returns the first voxel that is solid
+// in the octree, like the FirstSolidHit function, with which the result is identical. Rather than stepping through
+// every voxel, the ray skips over every empty octant it enters in one go, so that the cost depends on the amount of
+// octants passed through rather than voxels. Voxels skipped do not count towards WithMaxVoxels, while
+// WithMaxManhattan disables the skipping.
+func (o *Octree) FirstSolidHit(start, end mgl64.Vec3, opts ...Option) (HitResult, bool, error) {
+	conf := newConfig(opts)
+	t, err := newTracer(start, end, conf)
+	if err != nil {
+		return HitResult{}, false, err
+	}
+	if !t.enter(conf) {
+		return HitResult{}, false, t.err
+	}
+	if err := t.bound(o); err != nil {
+		return HitResult{}, false, err
+	}
+	r := newVoxelReader(o, conf.passThrough)
+	if conf.ignoreStartingSolid && !t.skipSolid(r) {
+		return HitResult{}, false, t.err
+	}
+	for {
+		min, max, empty := o.emptyRegion(t.pos())
+		if !empty && !r.passed(t.pos()) {
+			return t.hit(), true, nil
+		}
+		if min != max {
+			t.skipEmpty(min, max)
+		}
+		if !t.next() {
+			return HitResult{}, false, t.err
+		}
+	}
+}
+
+// skipMargin is the distance before the point at which a ray leaves an empty box that skipEmpty moves a tracer to.
+const skipMargin = 1e-6
+
+// skipEmpty moves the tracer forward through the box of voxels between min and max, both inclusive, which holds the
+// voxel the tracer is at and no solid voxels, without stepping through the voxels in between. The tracer is left in
+// a voxel inside the box just before the ray leaves it, so that the crossing out of the box is still stepped by next,
+// with the usual tie breaks, as is the end of the ray if it ends inside the box.
+func (t *tracer) skipEmpty(min, max BlockPos) {
+	if t.maxManhattan >= 0 || t.wrapped {
+		return
+	}
+	// The box is limited to the ranges of the tracer, so that jumping never moves it past the end of a range.
+	for i, r := range t.ranges {
+		if r.set {
+			if min[i] < r.min {
+				min[i] = r.min
+			}
+			if max[i] > r.max {
+				max[i] = r.max
+			}
+		}
+	}
+	if !insideRegion(t.pos(), min, max) {
+		return
+	}
+	_, exit, ok := intersectBox(t.start, t.dir, min.Vec3Min(), max.Add(BlockPos{1, 1, 1}).Vec3Min())
+	if !ok {
+		return
+	}
+	dist := math.Min(exit, t.radius) - skipMargin
+	if dist <= t.t {
+		return
+	}
+	prev := *t
+	t.jump(dist)
+	// Rounding may move the point past the end of the box or off the path of the ray, in which case the voxels are
+	// stepped through instead.
+	if !insideRegion(t.pos(), min, max) || t.t < prev.t || !t.onPath(dist) {
+		*t = prev
+	}
+}
+
+// approach moves the tracer forward to the voxel that the ray is in just before the distance passed, as if the
+// voxels in between had been stepped through, so that the voxel at that distance is then reached using next with the
+// usual tie breaks. If rounding would move the tracer to a voxel off the path stepped through by next, it is left
+// where it is.
+func (t *tracer) approach(dist float64) {
+	dist -= skipMargin
+	if dist <= t.t {
+		return
+	}
+	prev := *t
+	t.jump(dist)
+	if t.t < prev.t || !t.onPath(dist) {
+		*t = prev
+	}
+}
+
+// onPath checks if the voxel that the tracer is at is the one that next steps through at the distance passed along
+// the ray. As the boundary crossings are computed the same way as done by next, this is the case if the ray enters
+// the voxel before the distance and leaves it after the distance on every axis it travels along.
+func (t *tracer) onPath(dist float64) bool {
+	for axis, coord := range t.rawPos() {
+		step := t.step(axis)
+		if step == 0 {
+			continue
+		}
+		if exit, _ := t.line.crossing(axis, coord, step); t.entry(axis, coord, step) >= dist || exit <= dist {
+			return false
+		}
+	}
+	return true
+}
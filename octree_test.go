@@ -0,0 +1,147 @@
+package voxelraytrace
+
+import (
+	"github.com/go-gl/mathgl/mgl64"
+	"math/rand"
+	"testing"
+)
+
+func TestOctreeSet(t *testing.T) {
+	o := NewOctree(BlockPos{-4, 60, 3}, 3)
+	if min, max := o.Bounds(); min != (BlockPos{-4, 60, 3}) || max != (BlockPos{3, 67, 10}) {
+		t.Fatalf("got bounds %v, %v, want %v, %v", min, max, BlockPos{-4, 60, 3}, BlockPos{3, 67, 10})
+	}
+	o.Set(-4, 60, 3, true)
+	o.Set(3, 67, 10, true)
+	o.Set(0, 63, 7, true)
+	o.Set(0, 63, 7, false)
+	// Setting voxels outside the octree is ignored.
+	o.Set(4, 67, 10, true)
+	o.Set(-5, 60, 3, true)
+	for _, pos := range []BlockPos{{-4, 60, 3}, {3, 67, 10}} {
+		if !o.Solid(pos[0], pos[1], pos[2]) {
+			t.Errorf("%v is not solid", pos)
+		}
+	}
+	for _, pos := range []BlockPos{{0, 63, 7}, {-3, 60, 3}, {4, 67, 10}, {-5, 60, 3}} {
+		if o.Solid(pos[0], pos[1], pos[2]) {
+			t.Errorf("%v is solid", pos)
+		}
+	}
+
+	// Clearing the last solid voxels removes every node, leaving the whole octree a single empty octant.
+	o.Set(-4, 60, 3, false)
+	o.Set(3, 67, 10, false)
+	if o.root != nil {
+		t.Errorf("got a root node after clearing every solid voxel")
+	}
+	if min, max, empty := o.emptyRegion(BlockPos{0, 63, 7}); !empty || min != (BlockPos{-4, 60, 3}) || max != (BlockPos{3, 67, 10}) {
+		t.Errorf("got empty region %v, %v, %v, want the whole octree", min, max, empty)
+	}
+}
+
+func TestOctreeEmptyRegion(t *testing.T) {
+	o := NewOctree(BlockPos{}, 4)
+	o.Set(0, 0, 0, true)
+	tests := []struct {
+		pos      BlockPos
+		min, max BlockPos
+		empty    bool
+	}{
+		{pos: BlockPos{0, 0, 0}, min: BlockPos{0, 0, 0}, max: BlockPos{0, 0, 0}},
+		{pos: BlockPos{1, 0, 0}, min: BlockPos{1, 0, 0}, max: BlockPos{1, 0, 0}, empty: true},
+		{pos: BlockPos{3, 2, 1}, min: BlockPos{2, 2, 0}, max: BlockPos{3, 3, 1}, empty: true},
+		{pos: BlockPos{5, 1, 6}, min: BlockPos{4, 0, 4}, max: BlockPos{7, 3, 7}, empty: true},
+		{pos: BlockPos{15, 8, 0}, min: BlockPos{8, 8, 0}, max: BlockPos{15, 15, 7}, empty: true},
+	}
+	for _, test := range tests {
+		min, max, empty := o.emptyRegion(test.pos)
+		if empty != test.empty || empty && (min != test.min || max != test.max) {
+			t.Errorf("%v: got %v, %v, %v, want %v, %v, %v", test.pos, min, max, empty, test.min, test.max, test.empty)
+		}
+	}
+}
+
+func TestNewOctreeDepth(t *testing.T) {
+	for _, depth := range []int{-1, 31} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("depth %v: NewOctree did not panic", depth)
+				}
+			}()
+			NewOctree(BlockPos{}, depth)
+		}()
+	}
+	o := NewOctree(BlockPos{5, 5, 5}, 0)
+	if min, max := o.Bounds(); min != max || min != (BlockPos{5, 5, 5}) {
+		t.Errorf("got bounds %v, %v for depth 0, want a single voxel", min, max)
+	}
+}
+
+func TestOctreeFirstSolidHitMatchesTrace(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for _, solid := range []int{0, 5, 40, 400} {
+		o := NewOctree(BlockPos{-16, -16, -16}, 5)
+		for i := 0; i < solid; i++ {
+			o.Set(rng.Intn(32)-16, rng.Intn(32)-16, rng.Intn(32)-16, true)
+		}
+		optSets := [][]Option{
+			nil,
+			{WithIgnoreStartingSolid()},
+			{WithMaxChebyshev(12)},
+			{WithCeilOwnership()},
+			{WithMinDistance(3)},
+			{WithBounds(BlockPos{-6, -3, -9}, BlockPos{7, 5, 2})},
+		}
+		for i := 0; i < 5000; i++ {
+			// The rays are compared against stepping through every voxel of the same octree, which must give exactly
+			// the same hit, including its point and face.
+			start, end := randomPoint(rng, 15), randomPoint(rng, 40)
+			if start == end {
+				continue
+			}
+			opts := optSets[i%len(optSets)]
+			want, wantOK, wantErr := FirstSolidHit(o, start, end, opts...)
+			got, ok, err := o.FirstSolidHit(start, end, opts...)
+			if err != wantErr || ok != wantOK || got != want {
+				t.Fatalf("%v solid, trace %v -> %v with %v options: got %v, %v, %v, want %v, %v, %v", solid, start, end, len(opts), got, ok, err, want, wantOK, wantErr)
+			}
+		}
+	}
+}
+
+func TestOctreeFirstSolidHitOutside(t *testing.T) {
+	o := NewOctree(BlockPos{}, 3)
+	o.Set(5, 1, 1, true)
+	if _, _, err := o.FirstSolidHit(mgl64.Vec3{-0.5, 1.5, 1.5}, mgl64.Vec3{7.5, 1.5, 1.5}); err != (OutOfBoundsError{Pos: BlockPos{-1, 1, 1}}) {
+		t.Errorf("got %v starting outside the octree, want an OutOfBoundsError", err)
+	}
+	// A ray leaving the octree stops at its edge rather than continuing into the voxels outside it.
+	hit, ok, err := o.FirstSolidHit(mgl64.Vec3{0.5, 2.5, 1.5}, mgl64.Vec3{30.5, 2.5, 1.5})
+	if err != nil || ok {
+		t.Errorf("got %v, %v, %v leaving the octree, want no hit", hit, ok, err)
+	}
+	hit, ok, err = o.FirstSolidHit(mgl64.Vec3{0.5, 1.5, 1.5}, mgl64.Vec3{30.5, 1.5, 1.5})
+	if err != nil || !ok || hit.Pos != (BlockPos{5, 1, 1}) || hit.Face != FaceWest || hit.Point != (mgl64.Vec3{5, 1.5, 1.5}) {
+		t.Errorf("got %v, %v, %v, want a hit on the west face of %v", hit, ok, err, BlockPos{5, 1, 1})
+	}
+}
+
+func BenchmarkOctreeFirstSolidHit(b *testing.B) {
+	// A single solid voxel in the far corner of a 256 voxel octree, with empty space everywhere else, as in the sky
+	// above terrain.
+	o := NewOctree(BlockPos{}, 8)
+	o.Set(255, 255, 255, true)
+	start, end := mgl64.Vec3{0.2, 0.3, 0.1}, mgl64.Vec3{255.9, 255.8, 255.7}
+	b.Run("octree", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_, _, _ = o.FirstSolidHit(start, end)
+		}
+	})
+	b.Run("voxels", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_, _, _ = FirstSolidHit(o, start, end)
+		}
+	})
+}
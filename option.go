@@ -0,0 +1,348 @@
+package voxelraytrace
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/go-gl/mathgl/mgl64"
+	"math"
+	"time"
+)
+
+var (
+	// ErrMaxVoxelsExceeded is returned when a trace is stopped because it would pass through more voxels than allowed
+	// using WithMaxVoxels.
+	ErrMaxVoxelsExceeded = errors.New("maximum amount of voxels exceeded")
+	// ErrTimeBudgetExceeded is returned when a trace is stopped because it took longer than allowed using
+	// WithTimeBudget.
+	ErrTimeBudgetExceeded = errors.New("time budget exceeded")
+)
+
+// Option is an option that may be passed to the functions of the package to change the way a ray trace is
+// performed. Options that do not apply to a function are ignored by it.
+type Option func(c *config)
+
+// config holds the configuration of a single ray trace, built from the Options passed.
+type config struct {
+	// pierce is the amount of solid voxels passed through before stopping, or -1 if the ray never stops early.
+	pierce          int
+	mergeContiguous bool
+	// ignoreStartingSolid specifies if solid voxels at the start of the ray are skipped by hit tests.
+	ignoreStartingSolid bool
+	// passThrough returns true for solid voxels that hit tests should pass through, if set.
+	passThrough func(voxel mgl64.Vec3) bool
+
+	// offset is the world space position of the origin of the grid, and cellSize the size of its voxels.
+	offset, cellSize mgl64.Vec3
+	// ceil specifies if voxel boundaries belong to the voxel below them.
+	ceil bool
+	// toward specifies if boundaries at the endpoints of a ray belong to the voxel the ray travels through.
+	toward bool
+	// nudge specifies if a start point on a voxel boundary is moved off it before tracing.
+	nudge bool
+	// order maps the axes of the Y-up convention to those of the convention used by the caller. The ranges are
+	// indexed by the axes of the Y-up convention.
+	order axisOrder
+
+	maxVoxels int
+	ranges    [3]axisRange
+	budget    time.Duration
+	ctx       context.Context
+	hook      func(StepInfo)
+
+	// maxChebyshev and maxManhattan are the largest grid distances from the voxel the ray starts in that a trace may
+	// reach, or -1 if not limited.
+	maxChebyshev, maxManhattan int
+	// minDistance is the distance along the ray before which no voxels are passed through.
+	minDistance float64
+	// coverageSamples is the amount of samples taken along every axis of a voxel by CoverageBetweenPoints.
+	coverageSamples int
+	// supercover specifies if all voxels touched at an edge or corner crossing are passed through.
+	supercover bool
+	// bounds is the box of voxels that a trace is clipped to, if bounded is true.
+	bounds  AABBInt
+	bounded bool
+	// wrap holds the size of the world on every axis that voxel coordinates wrap around, or 0 if they do not.
+	wrap [3]int
+}
+
+// newConfig creates a config with all the Options passed applied to it.
+func newConfig(opts []Option) config {
+	c := config{pierce: -1, order: yUp, maxChebyshev: -1, maxManhattan: -1, coverageSamples: 4, cellSize: mgl64.Vec3{1, 1, 1}}
+	if len(opts) == 0 {
+		// Passing c to an Option makes it escape to the heap, so a trace without Options only allocates if it
+		// returns its voxels.
+		return c
+	}
+	conf := new(config)
+	*conf = c
+	for _, opt := range opts {
+		opt(conf)
+	}
+	return *conf
+}
+
+// axisRange is a range of voxel coordinates on a single axis that a trace is limited to.
+type axisRange struct {
+	set      bool
+	min, max int
+}
+
+// toCells divides the vector passed by the cell size of the config.
+func (c config) toCells(v mgl64.Vec3) mgl64.Vec3 {
+	return mgl64.Vec3{v[0] / c.cellSize[0], v[1] / c.cellSize[1], v[2] / c.cellSize[2]}
+}
+
+// ranged checks if the trace is limited to a range on any axis.
+func (c config) ranged() bool {
+	return c.ranges[0].set || c.ranges[1].set || c.ranges[2].set
+}
+
+// WithPierce makes the ray pass through the first n solid voxels it hits instead of stopping at the first one, as
+// done by a piercing projectile. The ray stops at the solid voxel after that, so at most n+1 hits are reported. By
+// default, every solid voxel counts as a separate hit, even if it is part of the same wall as the voxel before it.
+// WithMergeContiguous may be used to change this.
+func WithPierce(n int) Option {
+	return func(c *config) {
+		c.pierce = n
+	}
+}
+
+// WithMergeContiguous makes a run of solid voxels directly following each other along the ray count as a single
+// hit, so that a thick wall is only pierced once. Only the first voxel of the run is reported.
+func WithMergeContiguous() Option {
+	return func(c *config) {
+		c.mergeContiguous = true
+	}
+}
+
+// WithMaxVoxels limits the amount of voxels a trace may pass through to n. If the ray would pass through more voxels,
+// the trace is stopped and ErrMaxVoxelsExceeded is returned along with the partial result. This may be used as a hard
+// cap on the work done by a single call, such as for rays of untrusted length.
+func WithMaxVoxels(n int) Option {
+	return func(c *config) {
+		c.maxVoxels = n
+	}
+}
+
+// WithTimeBudget limits the time a trace may take to the budget passed, so that a single expensive trace cannot
+// exceed the duration of a tick. The time is checked every 64 voxels. If the budget is exceeded, the trace is stopped
+// and ErrTimeBudgetExceeded is returned along with the partial result.
+func WithTimeBudget(budget time.Duration) Option {
+	return func(c *config) {
+		c.budget = budget
+	}
+}
+
+// WithContext makes a trace stop when the context passed is cancelled or reaches its deadline. The context is
+// checked every 64 voxels. If the trace is stopped, the error of the context is returned along with the partial
+// result.
+func WithContext(ctx context.Context) Option {
+	return func(c *config) {
+		c.ctx = ctx
+	}
+}
+
+// WithXRange limits a trace to the voxels with an X coordinate between min and max, both inclusive. The trace stops
+// when the ray leaves the range in the direction it is travelling, so that it can never return. A ray that starts
+// outside the range, but travels towards it, is not stopped. The ranges of the other axes may be set independently.
+func WithXRange(min, max int) Option {
+	return func(c *config) {
+		c.ranges[0] = axisRange{set: true, min: min, max: max}
+	}
+}
+
+// WithYRange limits a trace to the voxels with a Y coordinate between min and max, both inclusive, in the same way
+// as WithXRange.
+func WithYRange(min, max int) Option {
+	return func(c *config) {
+		c.ranges[1] = axisRange{set: true, min: min, max: max}
+	}
+}
+
+// WithZRange limits a trace to the voxels with a Z coordinate between min and max, both inclusive, in the same way
+// as WithXRange.
+func WithZRange(min, max int) Option {
+	return func(c *config) {
+		c.ranges[2] = axisRange{set: true, min: min, max: max}
+	}
+}
+
+// WithMaxChebyshev stops a trace when the ray would enter a voxel of which the Chebyshev distance from the voxel the
+// ray starts in exceeds n, so that the trace is limited to the cube of voxels reaching n voxels from the start in
+// every direction, like the range of many game rules. The trace stops silently, as if the ray ended there, and may be
+// combined with the length of the ray and other limits, in which case the first limit reached stops it. A negative n
+// removes the limit.
+func WithMaxChebyshev(n int) Option {
+	return func(c *config) {
+		c.maxChebyshev = n
+	}
+}
+
+// WithMaxManhattan stops a trace when the ray would enter a voxel of which the Manhattan distance from the voxel the
+// ray starts in exceeds n, in the same way as WithMaxChebyshev. As the ray moves one voxel further away on a single
+// axis with every step, this limits the trace to n+1 voxels. A negative n removes the limit.
+func WithMaxManhattan(n int) Option {
+	return func(c *config) {
+		c.maxManhattan = n
+	}
+}
+
+// WithMinDistance makes a trace ignore the first d units of the ray, such as to keep a camera ray from picking the
+// head of the player it is cast from. The first voxel passed through is the voxel containing the point at distance d
+// along the ray, and it is reported with the face and distance at which the ray entered it, as if the trace started at
+// the true start of the ray. If d is at least the length of the ray, no voxels are passed through at all. Limits such
+// as WithMaxChebyshev are still measured from the true start. WithMinDistance applies to Trace, BetweenPoints,
+// BetweenPointsInt, InDirection, FirstSolidHit, SolidHits and HasLineOfSight.
+func WithMinDistance(d float64) Option {
+	return func(c *config) {
+		c.minDistance = d
+	}
+}
+
+// WithBounds clips a trace to the box of voxels between min and max, both inclusive, such as the height limits and
+// border of a world, so that voxels outside of it are never passed through. A ray that starts outside the box is
+// moved forward to the point at which it enters it, and the trace stops silently, as if the ray ended there, when the
+// ray leaves the box. If the ray misses the box entirely, no voxels are passed through at all. WithBounds applies to
+// Trace, BetweenPoints, BetweenPointsInt, InDirection, FirstSolidHit, SolidHits and HasLineOfSight.
+func WithBounds(min, max BlockPos) Option {
+	return func(c *config) {
+		c.bounds, c.bounded = AABBInt{Min: min, Max: max}, true
+	}
+}
+
+// WithSupercover makes a trace pass through every voxel that the ray touches where it crosses an edge or corner of a
+// voxel exactly, rather than only the voxels on one side of the edge or corner. This is the supercover variant of the
+// traversal, which leaves no diagonal gaps, as needed for drawing lines or propagating light. The voxels touched at a
+// crossing are passed through in the order of the amount of boundaries crossed to reach them, with ties in ascending
+// X, Y, Z order, or in descending order if the end of the ray comes before its start when comparing their X, then Y,
+// then Z coordinates, so that a trace in reverse passes through the same voxels in reverse order. The voxels touched
+// are not counted towards WithMaxVoxels. WithSupercover applies to Trace, BetweenPoints, BetweenPointsInt and
+// InDirection.
+func WithSupercover() Option {
+	return func(c *config) {
+		c.supercover = true
+	}
+}
+
+// WithCoverageSamples sets the amount of samples taken along every axis of a voxel by CoverageBetweenPoints to n, so
+// that n*n*n samples are taken per voxel. Higher values produce smoother weights at a cubic cost. The default is 4.
+// Values below 1 are ignored.
+func WithCoverageSamples(n int) Option {
+	return func(c *config) {
+		if n >= 1 {
+			c.coverageSamples = n
+		}
+	}
+}
+
+// WithIgnoreStartingSolid makes FirstSolidHit and SolidHits skip the run of solid voxels that the ray starts in, so
+// that a ray starting inside a wall reports the first solid voxel after at least one voxel that is not solid, rather
+// than the voxel it started in. This is useful for rays cast from a point that may be buried in a solid voxel.
+func WithIgnoreStartingSolid() Option {
+	return func(c *config) {
+		c.ignoreStartingSolid = true
+	}
+}
+
+// WithPassThrough makes FirstSolidHit, SolidHits, FirstSolidHitSDF and Octree.FirstSolidHit pass through the solid
+// voxels for which pass returns true as if they were not solid, such as water or tall grass when looking for the
+// block a player is looking at. pass is only called for solid voxels, in the order in which the ray passes through
+// them, so it may also record the voxels passed through, such as to find the first fluid on the way to the hit.
+func WithPassThrough(pass func(voxel mgl64.Vec3) bool) Option {
+	return func(c *config) {
+		c.passThrough = pass
+	}
+}
+
+// WithCenteredVoxels makes a trace use a grid in which voxels are centred on integer coordinates, so that the voxel
+// n spans from n-0.5 to n+0.5 on every axis, rather than from n to n+1. The voxels passed through are reported by
+// their index in this grid, while hit points remain in world space.
+func WithCenteredVoxels() Option {
+	return func(c *config) {
+		c.offset = mgl64.Vec3{-0.5, -0.5, -0.5}
+	}
+}
+
+// WithGridOrigin makes a trace use a grid of which the voxel at (0, 0, 0) has its minimum corner at the world space
+// position passed, rather than at the world origin, such as for a grid local to a chunk starting at an arbitrary
+// corner. Coordinates are passed in world space and the voxels passed through are reported by their index in this
+// grid, while hit points remain in world space. WithCenteredVoxels is the same as passing an origin of -0.5 on every
+// axis.
+func WithGridOrigin(origin mgl64.Vec3) Option {
+	return func(c *config) {
+		c.offset = origin
+	}
+}
+
+// WithCellSize makes a trace use a grid of which the voxels are sx by sy by sz units in size, rather than 1 by 1 by 1,
+// so that the voxel (x, y, z) spans from x*sx to (x+1)*sx on the X axis, and likewise on the other axes. Coordinates
+// are passed in world space and the voxels passed through are reported by their index in this grid, while hit points
+// and distances remain in world space. Options limiting the voxels passed through, such as WithXRange, are in voxel
+// indices. WithCellSize panics if any of the sizes is not positive and finite.
+func WithCellSize(sx, sy, sz float64) Option {
+	for _, s := range [...]float64{sx, sy, sz} {
+		if !(s > 0) || math.IsInf(s, 1) {
+			panic(fmt.Sprintf("voxelraytrace: cell size %v must be positive and finite", s))
+		}
+	}
+	return func(c *config) {
+		c.cellSize = mgl64.Vec3{sx, sy, sz}
+	}
+}
+
+// WithCeilOwnership makes voxel boundaries belong to the voxel below them, so that the voxel n spans (n, n+1] on every
+// axis, rather than the default of [n, n+1). This matters for rays that start, end or travel exactly on a boundary,
+// such as a ray travelling horizontally at Y=3, which passes through voxels at Y=3 by default and through voxels at
+// Y=2 with this option. The voxels passed through, the faces reported and VoxelAt all follow the ownership used.
+func WithCeilOwnership() Option {
+	return func(c *config) {
+		c.ceil = true
+	}
+}
+
+// WithBoundaryTowardDirection makes a ray that starts or ends exactly on a voxel boundary only pass through the voxel
+// on the side of the boundary that the ray travels through, rather than also through the voxel that owns the
+// boundary, such as a ray starting at X=12 towards negative X, which then starts in the voxel at X=11 instead of
+// starting in the voxel at X=12 and stepping out of it at a distance of 0. Boundaries on axes the ray does not
+// travel along keep the ownership set using WithCeilOwnership. Traces remain symmetric with this option.
+func WithBoundaryTowardDirection() Option {
+	return func(c *config) {
+		c.toward = true
+	}
+}
+
+// WithNudgeBoundary moves the start point of the ray slightly along the ray if it lies on a voxel boundary, as if
+// NudgeOffBoundary were called on it, so that the voxel the ray starts in is never ambiguous. The ray is shortened by
+// the same distance, so that it still ends at the same point.
+func WithNudgeBoundary() Option {
+	return func(c *config) {
+		c.nudge = true
+	}
+}
+
+// WithWrap makes a trace wrap voxel coordinates around a world that is sizeX by sizeY by sizeZ voxels in size, such as
+// a map that wraps around on the X and Z axis, so that a ray crossing the edge of the world continues at its other
+// side. A size of 0 disables wrapping on that axis. The voxels passed through and looked up in a Grid are reported by
+// their wrapped coordinates, from 0 to the size on a wrapped axis, while hit points and distances remain those along
+// the ray as passed. Options limiting the voxels passed through, such as WithXRange and WithBounds, apply to the
+// coordinates before wrapping, while the bounds of a BoundedGrid only limit the axes that are not wrapped. Like the
+// ranges, the sizes refer to the axes of the Y-up convention if WithUpAxis is passed. Skipping empty chunks of a
+// ChunkedGrid and empty octants of an Octree is disabled. WithWrap panics if any of the sizes is negative.
+func WithWrap(sizeX, sizeY, sizeZ int) Option {
+	if sizeX < 0 || sizeY < 0 || sizeZ < 0 {
+		panic(fmt.Sprintf("voxelraytrace: wrap sizes %v, %v and %v must not be negative", sizeX, sizeY, sizeZ))
+	}
+	return func(c *config) {
+		c.wrap = [3]int{sizeX, sizeY, sizeZ}
+	}
+}
+
+// WithStepHook makes the trace call hook with the state of the traversal for every voxel visited, including the one
+// the ray starts in. The StepInfo passed holds a copy of the state, so it may be kept. This is intended for debugging
+// traversals: a trace with a hook set is slower, while a trace without one is not affected.
+func WithStepHook(hook func(StepInfo)) Option {
+	return func(c *config) {
+		c.hook = hook
+	}
+}
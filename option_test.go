@@ -0,0 +1,343 @@
+package voxelraytrace
+
+import (
+	"github.com/go-gl/mathgl/mgl64"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestCenteredVoxelsMatchShiftedTrace(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	half := mgl64.Vec3{0.5, 0.5, 0.5}
+	g := randomGrid(rng, 8, 60)
+	for i := 0; i < 5000; i++ {
+		start, end := randomPoint(rng, 8), randomPoint(rng, 8)
+		if start == end {
+			continue
+		}
+		// Under the centered convention, the voxel at n spans from n-0.5 to n+0.5, which is the same as shifting the
+		// ray by half a voxel under the default convention.
+		got, err := BetweenPointsInt(start, end, WithCenteredVoxels())
+		want, wantErr := BetweenPointsInt(start.Add(half), end.Add(half))
+		if err != wantErr || !reflect.DeepEqual(got, want) {
+			t.Fatalf("trace %v -> %v: got %v, %v, want %v, %v", start, end, got, err, want, wantErr)
+		}
+		if got, want := VoxelAt(start, WithCenteredVoxels()), BlockPosFromVec3(start.Add(half)); got != want {
+			t.Fatalf("point %v: got voxel %v, want %v", start, got, want)
+		}
+
+		hit, ok, _ := FirstSolidHit(g, start, end, WithCenteredVoxels())
+		wantHit, wantOK, _ := FirstSolidHit(g, start.Add(half), end.Add(half))
+		if ok != wantOK || hit.Pos != wantHit.Pos || hit.Face != wantHit.Face || ok && !vec3Near(hit.Point.Add(half), wantHit.Point) {
+			t.Fatalf("trace %v -> %v: got hit %v, %v, want %v, %v", start, end, hit, ok, wantHit, wantOK)
+		}
+
+		bounds := WithBounds(BlockPos{-2, -1, -3}, BlockPos{2, 3, 1})
+		got, err = BetweenPointsInt(start, end, WithCenteredVoxels(), bounds)
+		want, wantErr = BetweenPointsInt(start.Add(half), end.Add(half), bounds)
+		if err != wantErr || !reflect.DeepEqual(got, want) {
+			t.Fatalf("trace %v -> %v within bounds: got %v, %v, want %v, %v", start, end, got, err, want, wantErr)
+		}
+	}
+}
+
+func TestCenteredVoxels(t *testing.T) {
+	got, err := BetweenPointsInt(mgl64.Vec3{0, 0, 0}, mgl64.Vec3{2, 0.2, 0}, WithCenteredVoxels())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []BlockPos{{0, 0, 0}, {1, 0, 0}, {2, 0, 0}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if got := VoxelAt(mgl64.Vec3{-0.5, 0.49, -0.51}, WithCenteredVoxels()); got != (BlockPos{0, 0, -1}) {
+		t.Errorf("got %v, want [0 0 -1]", got)
+	}
+}
+
+func TestBoundaryPlaneOwnership(t *testing.T) {
+	for axis := 0; axis < 3; axis++ {
+		for _, edge := range []bool{false, true} {
+			// The ray travels along the axis after the one it lies on a boundary plane of, and also lies on a boundary
+			// of the third axis if it travels along an edge.
+			var start, end mgl64.Vec3
+			start[axis], end[axis] = 3, 3
+			along, other := (axis+1)%3, (axis+2)%3
+			start[along], end[along] = -2.5, 4.5
+			start[other], end[other] = 0.5, 0.5
+			if edge {
+				start[other], end[other] = -1, -1
+			}
+			for _, ceil := range []bool{false, true} {
+				var opts []Option
+				want, wantOther := 3, 0
+				if edge {
+					wantOther = -1
+				}
+				if ceil {
+					opts = append(opts, WithCeilOwnership())
+					want--
+					if edge {
+						wantOther--
+					}
+				}
+				positions, err := BetweenPointsInt(start, end, opts...)
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if len(positions) != 8 {
+					t.Fatalf("axis %v, edge %v, ceil %v: got %v, want 8 voxels", axis, edge, ceil, positions)
+				}
+				for _, pos := range positions {
+					if pos[axis] != want || pos[other] != wantOther {
+						t.Fatalf("axis %v, edge %v, ceil %v: got %v", axis, edge, ceil, positions)
+					}
+				}
+				if got := VoxelAt(start, opts...); got != positions[0] {
+					t.Errorf("axis %v, edge %v, ceil %v: VoxelAt gives %v, trace starts in %v", axis, edge, ceil, got, positions[0])
+				}
+				if got := VoxelAt(end, opts...); got != positions[len(positions)-1] {
+					t.Errorf("axis %v, edge %v, ceil %v: VoxelAt gives %v, trace ends in %v", axis, edge, ceil, got, positions[len(positions)-1])
+				}
+
+				g := NewSparseGrid()
+				wall := positions[5]
+				g.Set(wall[0], wall[1], wall[2], true)
+				hit, ok, _ := FirstSolidHit(g, start, end, opts...)
+				if wantFace := axisEntryFace(1, along); !ok || hit.Pos != wall || hit.Face != wantFace {
+					t.Errorf("axis %v, edge %v, ceil %v: got hit %v, %v, want %v through %v", axis, edge, ceil, hit, ok, wall, wantFace)
+				}
+			}
+		}
+	}
+}
+
+func TestBoundaryTowardDirection(t *testing.T) {
+	tests := []struct {
+		name       string
+		start, end mgl64.Vec3
+		ceil       bool
+		want       []BlockPos
+		toward     []BlockPos
+	}{
+		{
+			name:   "start towards negative",
+			start:  mgl64.Vec3{12, 0.5, 0.5},
+			end:    mgl64.Vec3{9.5, 0.5, 0.5},
+			want:   []BlockPos{{12, 0, 0}, {11, 0, 0}, {10, 0, 0}, {9, 0, 0}},
+			toward: []BlockPos{{11, 0, 0}, {10, 0, 0}, {9, 0, 0}},
+		},
+		{
+			name:   "start towards positive",
+			start:  mgl64.Vec3{12, 0.5, 0.5},
+			end:    mgl64.Vec3{14.5, 0.5, 0.5},
+			want:   []BlockPos{{12, 0, 0}, {13, 0, 0}, {14, 0, 0}},
+			toward: []BlockPos{{12, 0, 0}, {13, 0, 0}, {14, 0, 0}},
+		},
+		{
+			name:   "end towards positive",
+			start:  mgl64.Vec3{9.5, 0.5, 0.5},
+			end:    mgl64.Vec3{12, 0.5, 0.5},
+			want:   []BlockPos{{9, 0, 0}, {10, 0, 0}, {11, 0, 0}, {12, 0, 0}},
+			toward: []BlockPos{{9, 0, 0}, {10, 0, 0}, {11, 0, 0}},
+		},
+		{
+			name:   "start towards positive with ceil ownership",
+			start:  mgl64.Vec3{12, 0.5, 0.5},
+			end:    mgl64.Vec3{14.5, 0.5, 0.5},
+			ceil:   true,
+			want:   []BlockPos{{11, 0, 0}, {12, 0, 0}, {13, 0, 0}, {14, 0, 0}},
+			toward: []BlockPos{{12, 0, 0}, {13, 0, 0}, {14, 0, 0}},
+		},
+		{
+			name:   "corner",
+			start:  mgl64.Vec3{12, 3, 0.5},
+			end:    mgl64.Vec3{10.5, 1.5, 0.5},
+			want:   []BlockPos{{12, 3, 0}, {11, 3, 0}, {11, 2, 0}, {10, 2, 0}, {10, 1, 0}},
+			toward: []BlockPos{{11, 2, 0}, {10, 2, 0}, {10, 1, 0}},
+		},
+		{
+			// The ray travels on the Y=3 plane, which keeps belonging to the voxels above it.
+			name:   "along a boundary",
+			start:  mgl64.Vec3{12, 3, 0.5},
+			end:    mgl64.Vec3{10.5, 3, 0.5},
+			want:   []BlockPos{{12, 3, 0}, {11, 3, 0}, {10, 3, 0}},
+			toward: []BlockPos{{11, 3, 0}, {10, 3, 0}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var opts []Option
+			if test.ceil {
+				opts = append(opts, WithCeilOwnership())
+			}
+			got, err := BetweenPointsInt(test.start, test.end, opts...)
+			if err != nil || !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %v, %v, want %v", got, err, test.want)
+			}
+			opts = append(opts, WithBoundaryTowardDirection())
+			got, err = BetweenPointsInt(test.start, test.end, opts...)
+			if err != nil || !reflect.DeepEqual(got, test.toward) {
+				t.Errorf("got %v, %v towards the direction, want %v", got, err, test.toward)
+			}
+			reversed, err := BetweenPointsInt(test.end, test.start, opts...)
+			for i, j := 0, len(reversed)-1; i < j; i, j = i+1, j-1 {
+				reversed[i], reversed[j] = reversed[j], reversed[i]
+			}
+			if err != nil || !reflect.DeepEqual(reversed, test.toward) {
+				t.Errorf("got %v, %v tracing backwards, want the reverse of %v", reversed, err, test.toward)
+			}
+		})
+	}
+
+	// An entity standing against a wall on its boundary does not hit the wall when looking away from it.
+	g := wallGrid(12)
+	start, end := mgl64.Vec3{12, 0.5, 0.5}, mgl64.Vec3{9.5, 0.5, 0.5}
+	if hit, ok, err := FirstSolidHit(g, start, end); err != nil || !ok || !hit.StartedInside {
+		t.Errorf("got %v, %v, %v, want a hit started inside", hit, ok, err)
+	}
+	if hit, ok, err := FirstSolidHit(g, start, end, WithBoundaryTowardDirection()); err != nil || ok {
+		t.Errorf("got %v, %v, %v towards the direction, want no hit", hit, ok, err)
+	}
+}
+
+// chebyshev returns the Chebyshev distance between two voxel positions.
+func chebyshev(a, b BlockPos) int {
+	d := 0
+	for i := range a {
+		if v := absInt(a[i] - b[i]); v > d {
+			d = v
+		}
+	}
+	return d
+}
+
+func TestMaxGridDistanceMatchesTruncatedTrace(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 5000; i++ {
+		start, end := randomPoint(rng, 8), randomPoint(rng, 8)
+		positions, err := BetweenPointsInt(start, end)
+		if err != nil {
+			continue
+		}
+		n := rng.Intn(6)
+		for _, limit := range []struct {
+			name   string
+			opt    func(n int) Option
+			metric func(a, b BlockPos) int
+		}{{"chebyshev", WithMaxChebyshev, chebyshev}, {"manhattan", WithMaxManhattan, manhattan}} {
+			// The trace stops before the first voxel further than n from the voxel the ray starts in.
+			want := positions
+			for j, pos := range positions {
+				if limit.metric(pos, positions[0]) > n {
+					want = positions[:j]
+					break
+				}
+			}
+			got, err := BetweenPointsInt(start, end, limit.opt(n))
+			if err != nil || !reflect.DeepEqual(got, want) {
+				t.Fatalf("%v %v: trace %v -> %v: got %v, %v, want %v", limit.name, n, start, end, got, err, want)
+			}
+		}
+	}
+}
+
+func TestMaxGridDistanceDiagonal(t *testing.T) {
+	start, dir := mgl64.Vec3{0.5, 0.5, 0.5}, mgl64.Vec3{1, 1, 0.9}.Normalize()
+	euclidean, _ := InDirection(start, dir, 8)
+	chebyshev, _ := InDirection(start, dir, 8, WithMaxChebyshev(3))
+	// Along a diagonal, 8 units reach 5 voxels away on the X and Y axes, but the cube of Chebyshev distance 3 is left
+	// earlier.
+	if last := BlockPosFromVec3(euclidean[len(euclidean)-1]); last != (BlockPos{5, 5, 4}) {
+		t.Fatalf("got %v as last voxel within 8 units, want %v", last, BlockPos{5, 5, 4})
+	}
+	if last := BlockPosFromVec3(chebyshev[len(chebyshev)-1]); last != (BlockPos{3, 3, 3}) || len(chebyshev) >= len(euclidean) {
+		t.Errorf("got %v voxels ending at %v, want fewer than %v ending at %v", len(chebyshev), last, len(euclidean), BlockPos{3, 3, 3})
+	}
+	manhattan, _ := InDirection(start, dir, 8, WithMaxManhattan(4))
+	if len(manhattan) != 5 {
+		t.Errorf("got %v voxels within a Manhattan distance of 4, want 5", len(manhattan))
+	}
+	// The Euclidean limit stops the trace first if it is shorter.
+	short, _ := InDirection(start, dir, 2, WithMaxChebyshev(3))
+	if want, _ := InDirection(start, dir, 2); !reflect.DeepEqual(short, want) {
+		t.Errorf("got %v, want %v limited by the length of the ray", short, want)
+	}
+}
+
+func TestMinDistanceMatchesTrace(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 5000; i++ {
+		start, end := randomPoint(rng, 8), randomPoint(rng, 8)
+		positions, err := BetweenPointsInt(start, end)
+		if err != nil {
+			continue
+		}
+		length := end.Sub(start).Len()
+		d := rng.Float64() * length * 1.2
+		got, err := BetweenPointsInt(start, end, WithMinDistance(d))
+		if err != nil {
+			t.Fatalf("trace %v -> %v from %v: unexpected error: %v", start, end, d, err)
+		}
+		if d >= length {
+			if len(got) != 0 {
+				t.Fatalf("trace %v -> %v from %v: got %v beyond the end of the ray", start, end, d, got)
+			}
+			continue
+		}
+		// The voxels are the tail of the trace, starting with the voxel containing the point at distance d.
+		if len(got) == 0 || !reflect.DeepEqual(got, positions[len(positions)-len(got):]) {
+			t.Fatalf("trace %v -> %v from %v: got %v, want a tail of %v", start, end, d, got, positions)
+		}
+		if p := along(start, end.Sub(start).Normalize(), d); !voxelContains(got[0], p) {
+			t.Fatalf("trace %v -> %v from %v: first voxel %v does not contain %v", start, end, d, got[0], p)
+		}
+	}
+}
+
+func TestMinDistanceFace(t *testing.T) {
+	g := wallGrid(3, 6)
+	start, end := mgl64.Vec3{0.5, 0.5, 0.5}, mgl64.Vec3{10.5, 2.5, 0.5}
+	full, ok, _ := FirstSolidHit(g, start, end)
+	if !ok {
+		t.Fatal("no hit without a minimum distance")
+	}
+	tests := []struct {
+		name string
+		d    float64
+		want HitResult
+	}{
+		// The ray starts inside the wall at the minimum distance, but is reported as entering it from the west, as
+		// it did when starting at the true start.
+		{name: "inside first wall", d: full.Distance + 0.5, want: full},
+		{name: "on entry of first wall", d: full.Distance, want: full},
+		{name: "just before first wall", d: full.Distance - 1e-3, want: full},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			hit, ok, err := FirstSolidHit(g, start, end, WithMinDistance(test.d))
+			if err != nil || !ok || hit != test.want || hit.StartedInside {
+				t.Errorf("got %+v, %v, %v, want %+v", hit, ok, err, test.want)
+			}
+		})
+	}
+
+	// Beyond the first wall, the second is hit through its west face.
+	hit, ok, err := FirstSolidHit(g, start, end, WithMinDistance(full.Distance+1.5))
+	if err != nil || !ok || hit.Pos[0] != 6 || hit.Face != FaceWest || hit.Point[0] != 6 {
+		t.Errorf("got %+v, %v, %v, want a hit on the west face of the second wall", hit, ok, err)
+	}
+	// A voxel entered through another face is reported with that face, even if the point at the minimum distance
+	// lies closer to a different face.
+	g = NewSparseGrid()
+	g.Set(1, 1, 0, true)
+	start, end = mgl64.Vec3{0.5, 0.5, 0.5}, mgl64.Vec3{4.5, 2.25, 0.5}
+	full, _, _ = FirstSolidHit(g, start, end)
+	hit, ok, err = FirstSolidHit(g, start, end, WithMinDistance(full.Distance+0.3))
+	if err != nil || !ok || hit != full || hit.Face != FaceDown {
+		t.Errorf("got %+v, %v, %v, want %+v entered through its bottom face", hit, ok, err, full)
+	}
+	if positions, err := BetweenPointsInt(start, end, WithMinDistance(end.Sub(start).Len())); err != nil || len(positions) != 0 {
+		t.Errorf("got %v, %v for a minimum distance of the length of the ray, want no voxels", positions, err)
+	}
+}
@@ -0,0 +1,69 @@
+package voxelraytrace
+
+import (
+	"errors"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// OrientedGrid is a Grid placed in the world using an arbitrary transform, such as the blocks of a moving ship or of a
+// rotated structure. Rays are passed in world space and transformed into the space of the grid, in which the voxels
+// are traced, so that voxels are reported by their position in the grid, while hit points and distances are in world
+// space. An OrientedGrid may be used concurrently as long as its Grid may.
+type OrientedGrid struct {
+	g                Grid
+	transform, local mgl64.Mat4
+}
+
+// NewOrientedGrid creates an OrientedGrid for the Grid passed, of which the grid space coordinates are transformed to
+// world space using the transform passed, which is usually a rotation followed by a translation. An error is returned
+// if the transform cannot be inverted, such as if it scales an axis by zero.
+func NewOrientedGrid(g Grid, transform mgl64.Mat4) (*OrientedGrid, error) {
+	if transform.Det() == 0 {
+		return nil, errors.New("grid transform is not invertible")
+	}
+	return &OrientedGrid{g: g, transform: transform, local: transform.Inv()}, nil
+}
+
+// ToLocal transforms the world space point passed to the space of the grid.
+func (o *OrientedGrid) ToLocal(p mgl64.Vec3) mgl64.Vec3 {
+	return o.local.Mul4x1(p.Vec4(1)).Vec3()
+}
+
+// ToWorld transforms the point in the space of the grid passed to world space.
+func (o *OrientedGrid) ToWorld(p mgl64.Vec3) mgl64.Vec3 {
+	return o.transform.Mul4x1(p.Vec4(1)).Vec3()
+}
+
+// FaceNormal returns the normalised world space normal of the face passed of the voxels of the grid, such as to
+// orient a particle spawned on the face of a HitResult. FaceNormal panics if FaceNone or an unknown face is passed.
+func (o *OrientedGrid) FaceNormal(f Face) mgl64.Vec3 {
+	// Normals are transformed using the inverse transpose, so that they remain perpendicular to the face if the
+	// transform scales the grid unevenly.
+	return o.local.Transpose().Mul4x1(FaceNormal(f).Vec4(0)).Vec3().Normalize()
+}
+
+// BetweenPoints performs a ray trace between the world space start and end coordinates like BetweenPointsInt and
+// returns the positions in the grid of the voxels passed through. The Options passed apply to the trace in the space
+// of the grid.
+func (o *OrientedGrid) BetweenPoints(start, end mgl64.Vec3, opts ...Option) ([]BlockPos, error) {
+	return BetweenPointsInt(o.ToLocal(start), o.ToLocal(end), opts...)
+}
+
+// FirstSolidHit performs a ray trace between the world space start and end coordinates like the FirstSolidHit
+// function and returns the first voxel that is solid in the grid. Pos and Face of the HitResult are those of the
+// voxel in the grid, which FaceNormal may be used to turn into a world space normal, while Point and Distance are in
+// world space. The Options passed apply to the trace in the space of the grid.
+func (o *OrientedGrid) FirstSolidHit(start, end mgl64.Vec3, opts ...Option) (HitResult, bool, error) {
+	hit, ok, err := FirstSolidHit(o.g, o.ToLocal(start), o.ToLocal(end), opts...)
+	if !ok {
+		return hit, ok, err
+	}
+	if hit.StartedInside {
+		hit.Point, hit.Distance = start, 0
+	} else {
+		// The distance is measured in world space, as the transform may scale the grid.
+		hit.Point = o.ToWorld(hit.Point)
+		hit.Distance = distance(start, hit.Point)
+	}
+	return hit, true, nil
+}
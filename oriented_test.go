@@ -0,0 +1,78 @@
+package voxelraytrace
+
+import (
+	"github.com/go-gl/mathgl/mgl64"
+	"math"
+	"testing"
+)
+
+// vec3Near checks if the vectors a and b are equal within a small tolerance.
+func vec3Near(a, b mgl64.Vec3) bool {
+	return a.Sub(b).Len() < 1e-9
+}
+
+func TestOrientedGridFirstSolidHit(t *testing.T) {
+	g := NewSparseGrid()
+	g.Set(3, 0, 0, true)
+	// The grid is rotated by 90 degrees around the Y axis and moved, so that its X axis points along -Z in the world.
+	transform := mgl64.Translate3D(10, 64, 10).Mul4(mgl64.HomogRotate3DY(math.Pi / 2))
+	o, err := NewOrientedGrid(g, transform)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	start := o.ToWorld(mgl64.Vec3{0.5, 0.5, 0.5})
+	end := o.ToWorld(mgl64.Vec3{8.5, 0.5, 0.5})
+	hit, ok, err := o.FirstSolidHit(start, end)
+	if err != nil || !ok {
+		t.Fatalf("got %v, %v, want a hit", ok, err)
+	}
+	if hit.Pos != (BlockPos{3, 0, 0}) || hit.Face != FaceWest {
+		t.Errorf("got %v through %v, want [3 0 0] through west", hit.Pos, hit.Face)
+	}
+	if want := o.ToWorld(mgl64.Vec3{3, 0.5, 0.5}); !vec3Near(hit.Point, want) {
+		t.Errorf("got point %v, want %v", hit.Point, want)
+	}
+	if math.Abs(hit.Distance-2.5) > 1e-9 {
+		t.Errorf("got distance %v, want 2.5", hit.Distance)
+	}
+	if n := o.FaceNormal(hit.Face); !vec3Near(n, mgl64.Vec3{0, 0, 1}) {
+		t.Errorf("got normal %v, want [0 0 1]", n)
+	}
+}
+
+func TestOrientedGridFaceNormalScaled(t *testing.T) {
+	// A grid rotated and then scaled unevenly is sheared, so that its faces are no longer at right angles to the
+	// transformed axes and the normal must be perpendicular to the transformed face itself.
+	transform := mgl64.Scale3D(4, 1, 0.5).Mul4(mgl64.HomogRotate3DZ(math.Pi / 6))
+	o, err := NewOrientedGrid(NewSparseGrid(), transform)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, f := range []Face{FaceDown, FaceUp, FaceNorth, FaceSouth, FaceWest, FaceEast} {
+		n := o.FaceNormal(f)
+		if math.Abs(n.Len()-1) > 1e-9 {
+			t.Errorf("%v: normal %v is not normalised", f, n)
+		}
+		// Every edge of the face lies along one of the two axes that the face does not point along.
+		axis, _ := f.axis()
+		for i := 0; i < 3; i++ {
+			if i == int(axis)-1 {
+				continue
+			}
+			var edge mgl64.Vec3
+			edge[i] = 1
+			if d := o.ToWorld(edge).Sub(o.ToWorld(mgl64.Vec3{})).Dot(n); math.Abs(d) > 1e-9 {
+				t.Errorf("%v: normal %v is not perpendicular to edge %v", f, n, edge)
+			}
+		}
+		if d := o.ToWorld(FaceNormal(f)).Sub(o.ToWorld(mgl64.Vec3{})).Dot(n); d <= 0 {
+			t.Errorf("%v: normal %v points into the voxel", f, n)
+		}
+	}
+}
+
+func TestNewOrientedGridSingular(t *testing.T) {
+	if _, err := NewOrientedGrid(NewSparseGrid(), mgl64.Scale3D(1, 0, 1)); err == nil {
+		t.Error("expected an error for a transform that is not invertible")
+	}
+}
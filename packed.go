@@ -0,0 +1,77 @@
+package voxelraytrace
+
+import (
+	"encoding/binary"
+	"errors"
+	"github.com/go-gl/mathgl/mgl64"
+	"math"
+)
+
+// ErrOutOfInt32Range is returned when a voxel position is packed of which a coordinate does not fit in an int32.
+var ErrOutOfInt32Range = errors.New("voxel coordinate out of int32 range")
+
+// BetweenPointsPacked performs a ray trace between the start and end coordinates like BetweenPoints, but returns the
+// positions of the voxels it passes through packed as x, y, z triplets in a single slice, as done by PackPath.
+func BetweenPointsPacked(start, end mgl64.Vec3, opts ...Option) ([]int32, error) {
+	positions, err := BetweenPointsInt(start, end, opts...)
+	if err != nil && positions == nil {
+		return nil, err
+	}
+	packed, packErr := PackPath(positions)
+	if packErr != nil {
+		return nil, packErr
+	}
+	return packed, err
+}
+
+// PackPath packs the positions passed into a single slice of x, y, z triplets. ErrOutOfInt32Range is returned if any
+// coordinate does not fit in an int32.
+func PackPath(positions []BlockPos) ([]int32, error) {
+	packed := make([]int32, 0, len(positions)*3)
+	for _, pos := range positions {
+		for _, v := range pos {
+			if v < math.MinInt32 || v > math.MaxInt32 {
+				return nil, ErrOutOfInt32Range
+			}
+			packed = append(packed, int32(v))
+		}
+	}
+	return packed, nil
+}
+
+// UnpackPath turns a slice of x, y, z triplets as returned by PackPath back into positions. An error is returned if
+// the length of the slice is not a multiple of three.
+func UnpackPath(packed []int32) ([]BlockPos, error) {
+	if len(packed)%3 != 0 {
+		return nil, errors.New("packed path length is not a multiple of three")
+	}
+	positions := make([]BlockPos, len(packed)/3)
+	for i := range positions {
+		positions[i] = BlockPos{int(packed[i*3]), int(packed[i*3+1]), int(packed[i*3+2])}
+	}
+	return positions, nil
+}
+
+// AppendPathBytes appends the packed path passed to dst and returns the extended slice. Every value is encoded as a
+// 4 byte little-endian two's complement integer, so that a voxel takes up 12 bytes, and no length is written.
+func AppendPathBytes(dst []byte, path []int32) []byte {
+	var b [4]byte
+	for _, v := range path {
+		binary.LittleEndian.PutUint32(b[:], uint32(v))
+		dst = append(dst, b[:]...)
+	}
+	return dst
+}
+
+// DecodePathBytes decodes a packed path encoded using AppendPathBytes. An error is returned if the length of b is not
+// a multiple of 12 bytes.
+func DecodePathBytes(b []byte) ([]int32, error) {
+	if len(b)%12 != 0 {
+		return nil, errors.New("path byte length is not a multiple of 12")
+	}
+	path := make([]int32, len(b)/4)
+	for i := range path {
+		path[i] = int32(binary.LittleEndian.Uint32(b[i*4:]))
+	}
+	return path, nil
+}
@@ -0,0 +1,34 @@
+//go:build go1.18
+
+package voxelraytrace
+
+import (
+	"bytes"
+	"testing"
+)
+
+func FuzzDecodePathBytes(f *testing.F) {
+	f.Add([]byte{})
+	f.Add(AppendPathBytes(nil, []int32{1, -2, 3, -2147483648, 2147483647, 0}))
+	f.Add([]byte{1, 2, 3})
+	f.Fuzz(func(t *testing.T, b []byte) {
+		path, err := DecodePathBytes(b)
+		if err != nil {
+			if len(b)%12 == 0 {
+				t.Fatalf("unexpected error for %v bytes: %v", len(b), err)
+			}
+			return
+		}
+		positions, err := UnpackPath(path)
+		if err != nil {
+			t.Fatalf("unexpected error unpacking a decoded path: %v", err)
+		}
+		packed, err := PackPath(positions)
+		if err != nil {
+			t.Fatalf("unexpected error packing an unpacked path: %v", err)
+		}
+		if encoded := AppendPathBytes(nil, packed); !bytes.Equal(encoded, b) {
+			t.Fatalf("got %v after encoding again, want %v", encoded, b)
+		}
+	})
+}
@@ -0,0 +1,79 @@
+package voxelraytrace
+
+import (
+	"github.com/go-gl/mathgl/mgl64"
+	"math"
+	"math/rand"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestPackedPathRoundTrip(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 1000; i++ {
+		start, end := randomPoint(rng, 1000), randomPoint(rng, 1000)
+		positions, err := BetweenPointsInt(start, end)
+		if err != nil {
+			continue
+		}
+		packed, err := BetweenPointsPacked(start, end)
+		if err != nil {
+			t.Fatalf("trace %v -> %v: unexpected error: %v", start, end, err)
+		}
+		if len(packed) != len(positions)*3 {
+			t.Fatalf("trace %v -> %v: got %v values for %v voxels", start, end, len(packed), len(positions))
+		}
+		b := AppendPathBytes([]byte{0xff}, packed)
+		if len(b) != 1+len(positions)*12 || b[0] != 0xff {
+			t.Fatalf("trace %v -> %v: got %v bytes appended for %v voxels", start, end, len(b)-1, len(positions))
+		}
+		decoded, err := DecodePathBytes(b[1:])
+		if err != nil {
+			t.Fatalf("trace %v -> %v: unexpected error: %v", start, end, err)
+		}
+		unpacked, err := UnpackPath(decoded)
+		if err != nil || !reflect.DeepEqual(unpacked, positions) {
+			t.Fatalf("trace %v -> %v: got %v, %v, want %v", start, end, unpacked, err, positions)
+		}
+	}
+}
+
+func TestAppendPathBytesLittleEndian(t *testing.T) {
+	got := AppendPathBytes(nil, []int32{1, -2, 0x01020304})
+	want := []byte{1, 0, 0, 0, 0xfe, 0xff, 0xff, 0xff, 4, 3, 2, 1}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestPackPathOutOfRange(t *testing.T) {
+	if strconv.IntSize == 32 {
+		t.Skip("int is 32 bits, so every coordinate fits in an int32")
+	}
+	// The coordinates are converted at run time, so that the test still compiles where int is 32 bits.
+	above, below := int64(math.MaxInt32)+1, int64(math.MinInt32)-1
+	for _, pos := range []BlockPos{{int(above), 0, 0}, {0, int(below), 0}, {0, 0, int(above << 20)}} {
+		if _, err := PackPath([]BlockPos{{1, 2, 3}, pos}); err != ErrOutOfInt32Range {
+			t.Errorf("%v: got %v, want %v", pos, err, ErrOutOfInt32Range)
+		}
+	}
+	packed, err := PackPath([]BlockPos{{math.MaxInt32, math.MinInt32, 0}})
+	if err != nil || !reflect.DeepEqual(packed, []int32{math.MaxInt32, math.MinInt32, 0}) {
+		t.Errorf("got %v, %v for the limits of int32", packed, err)
+	}
+	if _, err := BetweenPointsPacked(mgl64.Vec3{3e9, 0, 0}, mgl64.Vec3{3e9 + 2, 0, 0}); err != ErrOutOfInt32Range {
+		t.Errorf("got %v for a trace beyond the int32 range, want %v", err, ErrOutOfInt32Range)
+	}
+}
+
+func TestDecodePathBytesInvalid(t *testing.T) {
+	for _, n := range []int{1, 4, 11, 13} {
+		if _, err := DecodePathBytes(make([]byte, n)); err == nil {
+			t.Errorf("expected an error for %v bytes", n)
+		}
+	}
+	if _, err := UnpackPath(make([]int32, 4)); err == nil {
+		t.Error("expected an error for a packed path of 4 values")
+	}
+}
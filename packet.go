@@ -0,0 +1,173 @@
+package voxelraytrace
+
+import (
+	"github.com/go-gl/mathgl/mgl64"
+	"math"
+)
+
+// packetBlock is the amount of rays of a RayPacket that are traced together in lockstep.
+const packetBlock = 8
+
+// RayPacket holds a set of rays in a structure-of-arrays layout, so that they may be traced together using
+// TracePacket. Each ray is made up of a start position, a direction and the length of the ray in that direction. All
+// slices must have the same length.
+type RayPacket struct {
+	StartX, StartY, StartZ []float64
+	DirX, DirY, DirZ       []float64
+	Length                 []float64
+}
+
+// Add adds a ray from the start position in the direction passed, for a distance of the length, to the packet.
+func (p *RayPacket) Add(start, dir mgl64.Vec3, length float64) {
+	p.StartX, p.StartY, p.StartZ = append(p.StartX, start[0]), append(p.StartY, start[1]), append(p.StartZ, start[2])
+	p.DirX, p.DirY, p.DirZ = append(p.DirX, dir[0]), append(p.DirY, dir[1]), append(p.DirZ, dir[2])
+	p.Length = append(p.Length, length)
+}
+
+// Len returns the amount of rays in the packet.
+func (p *RayPacket) Len() int {
+	return len(p.StartX)
+}
+
+// Reset removes all rays from the packet, while keeping the memory allocated for them.
+func (p *RayPacket) Reset() {
+	p.StartX, p.StartY, p.StartZ = p.StartX[:0], p.StartY[:0], p.StartZ[:0]
+	p.DirX, p.DirY, p.DirZ = p.DirX[:0], p.DirY[:0], p.DirZ[:0]
+	p.Length = p.Length[:0]
+}
+
+// HitPacket holds the results of tracing a RayPacket in a structure-of-arrays layout. The result of the ray at index
+// i of the RayPacket is found at index i of every slice.
+type HitPacket struct {
+	// Hit specifies if the ray hit a solid voxel. The other values are only set if this is true.
+	Hit     []bool
+	X, Y, Z []int
+	// Distance is the distance from the start of the ray to the point at which it entered the voxel hit.
+	Distance []float64
+}
+
+// resize resizes all slices of the packet to n, reusing the memory already allocated where possible.
+func (h *HitPacket) resize(n int) {
+	if cap(h.Hit) < n {
+		h.Hit, h.Distance = make([]bool, n), make([]float64, n)
+		h.X, h.Y, h.Z = make([]int, n), make([]int, n), make([]int, n)
+		return
+	}
+	h.Hit, h.Distance = h.Hit[:n], h.Distance[:n]
+	h.X, h.Y, h.Z = h.X[:n], h.Y[:n], h.Z[:n]
+}
+
+// TracePacket traces all rays in the RayPacket against the Grid and writes the first solid voxel hit by each ray to
+// the HitPacket, growing it if needed. The rays are traced in blocks, stepping all rays of a block in lockstep, which
+// avoids the per-ray call overhead of FirstSolidHit. The results are identical to calling FirstSolidHit for every
+// ray from its start to start+dir*length, as voxels are looked up the same way: using SolidErr for a FallibleGrid and
+// a region at a time for a RegionGrid. Rays for which FirstSolidHit would return an error, such as when a voxel of a
+// FallibleGrid is not loaded, are reported as misses.
+func TracePacket(p *RayPacket, g Grid, out *HitPacket) {
+	n := p.Len()
+	out.resize(n)
+	bg, bounded := g.(BoundedGrid)
+	var min, max BlockPos
+	if bounded {
+		min, max = bg.Bounds()
+	}
+	// Every lane gets a reader of its own, so that the regions cached for the rays do not evict each other.
+	var readers [packetBlock]voxelReader
+	for l := range readers {
+		readers[l] = newVoxelReader(g, nil)
+	}
+
+	var (
+		active              [packetBlock]bool
+		x, y, z             [packetBlock]int
+		stepX, stepY, stepZ [packetBlock]int
+		tMaxX, tMaxY, tMaxZ [packetBlock]float64
+		keyX, keyY, keyZ    [packetBlock]float64
+		ties                [packetBlock][3]bool
+		line                [packetBlock]canonicalLine
+		t                   [packetBlock]float64
+		limX, limY, limZ    [packetBlock]float64
+	)
+	for base := 0; base < n; base += packetBlock {
+		size := n - base
+		if size > packetBlock {
+			size = packetBlock
+		}
+
+		remaining := 0
+		for l := 0; l < size; l++ {
+			i := base + l
+			out.Hit[i], out.X[i], out.Y[i], out.Z[i], out.Distance[i] = false, 0, 0, 0, 0
+
+			sx, sy, sz := p.StartX[i], p.StartY[i], p.StartZ[i]
+			ex, ey, ez := sx+float64(p.DirX[i]*p.Length[i]), sy+float64(p.DirY[i]*p.Length[i]), sz+float64(p.DirZ[i]*p.Length[i])
+			dx, dy, dz := ex-sx, ey-sy, ez-sz
+			lenSqr := sumSquares(dx, dy, dz)
+			if !(lenSqr > 0) {
+				active[l] = false
+				continue
+			}
+
+			active[l], remaining = true, remaining+1
+			line[l] = newCanonicalLine(mgl64.Vec3{sx, sy, sz}, mgl64.Vec3{ex, ey, ez}, yUp)
+			dir := line[l].direction()
+			x[l], y[l], z[l] = int(math.Floor(sx)), int(math.Floor(sy)), int(math.Floor(sz))
+			stepX[l], stepY[l], stepZ[l] = int(compareTo(dir[0], 0)), int(compareTo(dir[1], 0)), int(compareTo(dir[2], 0))
+			ties[l][0], ties[l][1], ties[l][2] = tieBreaks(stepX[l], stepY[l], stepZ[l], yUp)
+			tMaxX[l], keyX[l] = line[l].crossing(0, x[l], stepX[l])
+			tMaxY[l], keyY[l] = line[l].crossing(1, y[l], stepY[l])
+			tMaxZ[l], keyZ[l] = line[l].crossing(2, z[l], stepZ[l])
+			t[l] = 0
+			limX[l], limY[l], limZ[l] = endLimit(line[l].length, stepX[l], false, false), endLimit(line[l].length, stepY[l], false, false), endLimit(line[l].length, stepZ[l], false, false)
+		}
+
+		for remaining > 0 {
+			for l := 0; l < size; l++ {
+				if !active[l] {
+					continue
+				}
+				if bounded && !insideRegion(BlockPos{x[l], y[l], z[l]}, min, max) {
+					// The ray either started outside the bounds or just left them, which ends it either way.
+					active[l], remaining = false, remaining-1
+					continue
+				}
+				solid, err := readers[l].at(BlockPos{x[l], y[l], z[l]}, t[l])
+				if err != nil {
+					active[l], remaining = false, remaining-1
+					continue
+				}
+				if solid {
+					i := base + l
+					out.Hit[i], out.X[i], out.Y[i], out.Z[i], out.Distance[i] = true, x[l], y[l], z[l], t[l]
+					active[l], remaining = false, remaining-1
+					continue
+				}
+
+				kx, ky, kz := capLimit(keyX[l], tMaxX[l], limX[l]), capLimit(keyY[l], tMaxY[l], limY[l]), capLimit(keyZ[l], tMaxZ[l], limZ[l])
+				switch nearestAxis(kx, ky, kz, ties[l][0], ties[l][1], ties[l][2]) {
+				case 0:
+					if tMaxX[l] > limX[l] {
+						active[l], remaining = false, remaining-1
+						continue
+					}
+					x[l], t[l] = x[l]+stepX[l], tMaxX[l]
+					tMaxX[l], keyX[l] = line[l].crossing(0, x[l], stepX[l])
+				case 1:
+					if tMaxY[l] > limY[l] {
+						active[l], remaining = false, remaining-1
+						continue
+					}
+					y[l], t[l] = y[l]+stepY[l], tMaxY[l]
+					tMaxY[l], keyY[l] = line[l].crossing(1, y[l], stepY[l])
+				default:
+					if tMaxZ[l] > limZ[l] {
+						active[l], remaining = false, remaining-1
+						continue
+					}
+					z[l], t[l] = z[l]+stepZ[l], tMaxZ[l]
+					tMaxZ[l], keyZ[l] = line[l].crossing(2, z[l], stepZ[l])
+				}
+			}
+		}
+	}
+}
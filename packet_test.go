@@ -0,0 +1,167 @@
+package voxelraytrace
+
+import (
+	"fmt"
+	"github.com/go-gl/mathgl/mgl64"
+	"math/rand"
+	"testing"
+)
+
+// randomGrid returns a SparseGrid with the amount of solid voxels passed placed randomly in the cube spanning size
+// voxels on every axis from the origin.
+func randomGrid(rng *rand.Rand, size, solid int) *SparseGrid {
+	g := NewSparseGrid()
+	for i := 0; i < solid; i++ {
+		g.Set(rng.Intn(size), rng.Intn(size), rng.Intn(size), true)
+	}
+	return g
+}
+
+// checkPacket checks that the results of tracing the packet passed against the Grid match those of FirstSolidHit.
+func checkPacket(t *testing.T, p *RayPacket, g Grid) {
+	t.Helper()
+	var out HitPacket
+	TracePacket(p, g, &out)
+	for i := 0; i < p.Len(); i++ {
+		start := mgl64.Vec3{p.StartX[i], p.StartY[i], p.StartZ[i]}
+		dir := mgl64.Vec3{p.DirX[i], p.DirY[i], p.DirZ[i]}
+		hit, ok, _ := FirstSolidHit(g, start, start.Add(dir.Mul(p.Length[i])))
+		if out.Hit[i] != ok {
+			t.Fatalf("ray %v from %v along %v: got hit %v, want %v", i, start, dir, out.Hit[i], ok)
+		}
+		if ok && (BlockPos{out.X[i], out.Y[i], out.Z[i]} != hit.Pos || out.Distance[i] != hit.Distance) {
+			t.Fatalf("ray %v from %v along %v: got %v at %v, want %v at %v", i, start, dir,
+				BlockPos{out.X[i], out.Y[i], out.Z[i]}, out.Distance[i], hit.Pos, hit.Distance)
+		}
+	}
+}
+
+func TestTracePacket(t *testing.T) {
+	g := NewSparseGrid()
+	g.Set(4, 0, 0, true)
+	g.Set(0, -3, 0, true)
+	g.Set(3, 3, 3, true)
+	g.Set(1, 1, 0, true)
+
+	tests := []struct {
+		name  string
+		start mgl64.Vec3
+		dir   mgl64.Vec3
+	}{
+		{name: "axis-aligned X", start: mgl64.Vec3{0.5, 0.5, 0.5}, dir: mgl64.Vec3{1, 0, 0}},
+		{name: "axis-aligned down", start: mgl64.Vec3{0.5, 0.5, 0.5}, dir: mgl64.Vec3{0, -1, 0}},
+		{name: "axis-aligned miss", start: mgl64.Vec3{0.5, 0.5, 0.5}, dir: mgl64.Vec3{0, 0, -1}},
+		{name: "diagonal", start: mgl64.Vec3{0.5, 0.5, 0.5}, dir: mgl64.Vec3{1, 1, 1}.Normalize()},
+		{name: "diagonal through corners", start: mgl64.Vec3{0, 0, 0}, dir: mgl64.Vec3{1, 1, 1}.Normalize()},
+		{name: "corner clip", start: mgl64.Vec3{0, 0, 0.5}, dir: mgl64.Vec3{1, 1, 0}.Normalize()},
+		{name: "edge graze", start: mgl64.Vec3{2, 1, 0.5}, dir: mgl64.Vec3{-1, 0, 0}},
+		{name: "zero length", start: mgl64.Vec3{4.5, 0.5, 0.5}, dir: mgl64.Vec3{}},
+	}
+	var p RayPacket
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var single RayPacket
+			single.Add(test.start, test.dir, 8)
+			checkPacket(t, &single, g)
+		})
+		p.Add(test.start, test.dir, 8)
+	}
+	t.Run("all at once", func(t *testing.T) {
+		checkPacket(t, &p, g)
+	})
+}
+
+func TestTracePacketRandom(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	g := randomGrid(rng, 16, 200)
+	var p RayPacket
+	for i := 0; i < 1000; i++ {
+		p.Add(randomPoint(rng, 16), randomPoint(rng, 1), rng.Float64()*24)
+	}
+	checkPacket(t, &p, g)
+
+	bounded := NewArrayGrid(BlockPos{0, 0, 0}, 16, 16, 16)
+	for i := 0; i < 200; i++ {
+		bounded.Set(rng.Intn(16), rng.Intn(16), rng.Intn(16), true)
+	}
+	checkPacket(t, &p, bounded)
+}
+
+func TestTracePacketFallibleGrid(t *testing.T) {
+	// The voxels beyond the plane at X=5 are solid when looked up using Solid, but not loaded according to SolidErr,
+	// so the rays crossing the plane are misses, like the errors FirstSolidHit returns for them.
+	g := &planeGrid{SparseGrid: wallGrid(3, 8), plane: 5}
+	rng := rand.New(rand.NewSource(2))
+	var p RayPacket
+	for i := 0; i < 200; i++ {
+		p.Add(randomPoint(rng, 1.5).Add(mgl64.Vec3{3.5, 0, 0}), mgl64.Vec3{1, rng.Float64() - 0.5, rng.Float64() - 0.5}.Normalize(), 12)
+	}
+	checkPacket(t, &p, g)
+
+	var out HitPacket
+	var single RayPacket
+	single.Add(mgl64.Vec3{4.5, 0.5, 0.5}, mgl64.Vec3{1, 0, 0}, 10)
+	if TracePacket(&single, g, &out); out.Hit[0] {
+		t.Errorf("got a hit at %v through unloaded voxels, want a miss", BlockPos{out.X[0], out.Y[0], out.Z[0]})
+	}
+	g.loaded = true
+	if TracePacket(&single, g, &out); !out.Hit[0] || out.X[0] != 8 {
+		t.Errorf("got %v, %v once loaded, want a hit on the wall at X=8", out.Hit[0], BlockPos{out.X[0], out.Y[0], out.Z[0]})
+	}
+}
+
+func TestTracePacketRegionGrid(t *testing.T) {
+	rng := rand.New(rand.NewSource(3))
+	g := regionChunkGrid{newChunkGrid(randomGrid(rng, 48, 400))}
+	var p RayPacket
+	for i := 0; i < 1000; i++ {
+		p.Add(randomPoint(rng, 24).Add(mgl64.Vec3{24, 24, 24}), randomPoint(rng, 1), rng.Float64()*48)
+	}
+	checkPacket(t, &p, g)
+}
+
+// packetSizes are the amounts of rays per packet that the packet benchmarks are run with.
+var packetSizes = []int{8, 64, 512}
+
+// benchmarkRays returns the grid and rays that the packet benchmarks trace.
+func benchmarkRays(n int) (*SparseGrid, *RayPacket) {
+	rng := rand.New(rand.NewSource(1))
+	g := randomGrid(rng, 32, 2000)
+	p := &RayPacket{}
+	origin := mgl64.Vec3{16.5, 16.5, 16.5}
+	for i := 0; i < n; i++ {
+		p.Add(origin, randomPoint(rng, 1).Normalize(), 32)
+	}
+	return g, p
+}
+
+func BenchmarkTracePacket(b *testing.B) {
+	for _, n := range packetSizes {
+		b.Run(fmt.Sprint(n), func(b *testing.B) {
+			g, p := benchmarkRays(n)
+			var out HitPacket
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				TracePacket(p, g, &out)
+			}
+		})
+	}
+}
+
+func BenchmarkTracePacketPerRay(b *testing.B) {
+	for _, n := range packetSizes {
+		b.Run(fmt.Sprint(n), func(b *testing.B) {
+			g, p := benchmarkRays(n)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				for j := 0; j < p.Len(); j++ {
+					start := mgl64.Vec3{p.StartX[j], p.StartY[j], p.StartZ[j]}
+					dir := mgl64.Vec3{p.DirX[j], p.DirY[j], p.DirZ[j]}
+					_, _, _ = FirstSolidHit(g, start, start.Add(dir.Mul(p.Length[j])))
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package voxelraytrace
+
+import (
+	"fmt"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// PalettedSection is a section of 16x16x16 voxels stored as a palette of runtime IDs and a packed list of indices into
+// that palette, one for every voxel, in the layout used by Minecraft: Bedrock Edition. The index of the voxel at the
+// coordinates x, y and z within the section is found at position x<<8 | z<<4 | y of the list. Every word holds
+// 32/BitsPerIndex indices, starting at the least significant bits, and indices never span two words. If the palette
+// holds a single runtime ID, BitsPerIndex may be 0, in which case Words is not used.
+type PalettedSection struct {
+	Palette      []uint32
+	BitsPerIndex int
+	Words        []uint32
+}
+
+// PalettedSource is a source of voxels stored in PalettedSections, such as the chunks of a world.
+type PalettedSource interface {
+	// Section returns the section at the section coordinates passed, which are the voxel coordinates divided by 16,
+	// rounded down. If there is no section at these coordinates, false is returned, and none of its voxels are solid.
+	Section(x, y, z int) (PalettedSection, bool)
+}
+
+// palettedCursor reads the runtime IDs of voxels from a PalettedSource, keeping the section of the last voxel read
+// and the solidity of its palette entries, so that consecutive voxels in the same section only cost an index lookup.
+type palettedCursor struct {
+	src   PalettedSource
+	solid func(rid uint32) bool
+
+	pos     BlockPos
+	loaded  bool
+	present bool
+	section PalettedSection
+	// solidity holds the solidity of every palette entry of the section: 0 if not yet known, 1 if solid and 2 if not.
+	solidity []uint8
+}
+
+// at returns the runtime ID of the voxel at the position passed and whether it is solid. An error is returned if the
+// section holding the voxel is malformed.
+func (c *palettedCursor) at(x, y, z int) (rid uint32, solid bool, err error) {
+	pos := BlockPos{x >> 4, y >> 4, z >> 4}
+	if !c.loaded || pos != c.pos {
+		c.pos, c.loaded = pos, true
+		c.section, c.present = c.src.Section(pos[0], pos[1], pos[2])
+		c.solidity = c.solidity[:0]
+		for range c.section.Palette {
+			c.solidity = append(c.solidity, 0)
+		}
+	}
+	if !c.present {
+		return 0, false, nil
+	}
+	s := c.section
+	index := 0
+	if s.BitsPerIndex != 0 {
+		if s.BitsPerIndex < 0 || s.BitsPerIndex > 32 {
+			return 0, false, fmt.Errorf("section %v has invalid bits per index %v", pos, s.BitsPerIndex)
+		}
+		offset, perWord := (x&15)<<8|(z&15)<<4|(y&15), 32/s.BitsPerIndex
+		word := offset / perWord
+		if word >= len(s.Words) {
+			return 0, false, fmt.Errorf("section %v holds %v words, expected at least %v", pos, len(s.Words), word+1)
+		}
+		index = int(uint64(s.Words[word]) >> uint(offset%perWord*s.BitsPerIndex) & (1<<uint(s.BitsPerIndex) - 1))
+	}
+	if index >= len(s.Palette) {
+		return 0, false, fmt.Errorf("section %v has palette index %v out of range of palette with %v entries", pos, index, len(s.Palette))
+	}
+	rid = s.Palette[index]
+	if c.solidity[index] == 0 {
+		c.solidity[index] = 2
+		if c.solid(rid) {
+			c.solidity[index] = 1
+		}
+	}
+	return rid, c.solidity[index] == 1, nil
+}
+
+// FirstHitPaletted performs a ray trace between the start and end coordinates like FirstSolidHit, reading the voxels
+// from the PalettedSource passed. The solid function is called with the runtime ID of a voxel to check if it stops
+// the ray, at most once for every palette entry of a section. The section of the previous voxel is kept between
+// voxels, so a new section is only requested when the ray crosses into one. The runtime ID of the voxel hit is
+// returned along with the hit. An error is returned if a section is malformed.
+func FirstHitPaletted(s PalettedSource, start, end mgl64.Vec3, solid func(rid uint32) bool, opts ...Option) (HitResult, uint32, bool, error) {
+	t, err := newTracer(start, end, newConfig(opts))
+	if err != nil {
+		return HitResult{}, 0, false, err
+	}
+	c := palettedCursor{src: s, solid: solid}
+	for {
+		pos := t.pos()
+		rid, ok, err := c.at(pos[0], pos[1], pos[2])
+		if err != nil {
+			return HitResult{}, 0, false, err
+		}
+		if ok {
+			return t.hit(), rid, true, nil
+		}
+		if !t.next() {
+			return HitResult{}, 0, false, t.err
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package voxelraytrace
+
+import (
+	"github.com/go-gl/mathgl/mgl64"
+	"math/rand"
+	"testing"
+)
+
+// palettedWorld is a PalettedSource holding its sections in a map, which counts the sections requested.
+type palettedWorld struct {
+	sections map[BlockPos]PalettedSection
+	requests int
+}
+
+// Section returns the section at the section coordinates passed.
+func (w *palettedWorld) Section(x, y, z int) (PalettedSection, bool) {
+	w.requests++
+	s, ok := w.sections[BlockPos{x, y, z}]
+	return s, ok
+}
+
+// newPalettedSection returns a section holding the palette passed, with the palette index of every voxel picked at
+// random and packed using the bits per index passed.
+func newPalettedSection(rng *rand.Rand, palette []uint32, bits int) PalettedSection {
+	s := PalettedSection{Palette: palette, BitsPerIndex: bits}
+	if bits == 0 {
+		return s
+	}
+	perWord := 32 / bits
+	s.Words = make([]uint32, (4096+perWord-1)/perWord)
+	for offset := 0; offset < 4096; offset++ {
+		index := uint32(rng.Intn(len(palette)))
+		s.Words[offset/perWord] |= index << uint(offset%perWord*bits)
+	}
+	return s
+}
+
+// newPalettedWorld returns a palettedWorld of 4x4x4 sections starting at section coordinates -2, with sections
+// missing, holding a single runtime ID or using different amounts of bits per index. Runtime IDs below 10 are solid
+// and are rare.
+func newPalettedWorld(rng *rand.Rand) *palettedWorld {
+	w := &palettedWorld{sections: make(map[BlockPos]PalettedSection)}
+	for x := -2; x < 2; x++ {
+		for y := -2; y < 2; y++ {
+			for z := -2; z < 2; z++ {
+				var palette []uint32
+				switch bits := []int{-1, 0, 1, 2, 3, 4, 5, 8, 16}[rng.Intn(9)]; bits {
+				case -1:
+					continue
+				case 0:
+					w.sections[BlockPos{x, y, z}] = PalettedSection{Palette: []uint32{uint32(rng.Intn(200))}}
+				default:
+					for i := 0; i < 1<<uint(bits) && i < 40; i++ {
+						palette = append(palette, uint32(rng.Intn(200)))
+					}
+					w.sections[BlockPos{x, y, z}] = newPalettedSection(rng, palette, bits)
+				}
+			}
+		}
+	}
+	return w
+}
+
+// palettedGrid is a naive Grid reading every voxel from a PalettedSource separately.
+type palettedGrid struct {
+	w     *palettedWorld
+	solid func(rid uint32) bool
+}
+
+// rid returns the runtime ID of the voxel at the position passed, and false if its section is missing.
+func (g palettedGrid) rid(x, y, z int) (uint32, bool) {
+	s, ok := g.w.Section(x>>4, y>>4, z>>4)
+	if !ok {
+		return 0, false
+	}
+	if s.BitsPerIndex == 0 {
+		return s.Palette[0], true
+	}
+	offset, perWord := (x&15)<<8|(z&15)<<4|(y&15), 32/s.BitsPerIndex
+	index := s.Words[offset/perWord] >> uint(offset%perWord*s.BitsPerIndex) & (1<<uint(s.BitsPerIndex) - 1)
+	return s.Palette[index], true
+}
+
+// Solid checks if the voxel at the position passed is solid.
+func (g palettedGrid) Solid(x, y, z int) bool {
+	rid, ok := g.rid(x, y, z)
+	return ok && g.solid(rid)
+}
+
+func TestFirstHitPalettedMatchesGrid(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	w := newPalettedWorld(rng)
+	solid := func(rid uint32) bool { return rid < 10 }
+	g := palettedGrid{w: w, solid: solid}
+	var hits int
+	for i := 0; i < 5000; i++ {
+		// The rays cross many section boundaries, and often run along them.
+		start, end := randomPoint(rng, 31), randomPoint(rng, 31)
+		if start == end {
+			continue
+		}
+		want, wantOK, _ := FirstSolidHit(g, start, end)
+		w.requests = 0
+		got, rid, ok, err := FirstHitPaletted(w, start, end, solid)
+		if err != nil || ok != wantOK || got != want {
+			t.Fatalf("trace %v -> %v: got %v, %v, %v, want %v, %v", start, end, got, ok, err, want, wantOK)
+		}
+		if !ok {
+			continue
+		}
+		hits++
+		// Every section is requested once, when the ray enters it.
+		if sections := len(sectionsPassed(start, end, got.Pos)); w.requests > sections {
+			t.Fatalf("trace %v -> %v: requested %v sections, but only passed through %v", start, end, w.requests, sections)
+		}
+		if wantRID, _ := g.rid(got.Pos[0], got.Pos[1], got.Pos[2]); rid != wantRID {
+			t.Fatalf("trace %v -> %v: got runtime ID %v, want %v", start, end, rid, wantRID)
+		}
+	}
+	if hits < 100 {
+		t.Fatalf("only %v rays hit a solid voxel", hits)
+	}
+}
+
+// sectionsPassed returns the sections passed through by a ray between the start and end coordinates, up to the voxel
+// at the last position passed.
+func sectionsPassed(start, end mgl64.Vec3, last BlockPos) map[BlockPos]struct{} {
+	positions, _ := BetweenPointsInt(start, end)
+	sections := make(map[BlockPos]struct{})
+	for _, pos := range positions {
+		sections[BlockPos{pos[0] >> 4, pos[1] >> 4, pos[2] >> 4}] = struct{}{}
+		if pos == last {
+			break
+		}
+	}
+	return sections
+}
+
+func TestFirstHitPalettedMalformed(t *testing.T) {
+	solid := func(rid uint32) bool { return false }
+	start, end := mgl64.Vec3{0.5, 0.5, 0.5}, mgl64.Vec3{8.5, 0.5, 0.5}
+	for name, s := range map[string]PalettedSection{
+		"bits per index": {Palette: []uint32{1, 2}, BitsPerIndex: 33, Words: make([]uint32, 4096)},
+		"missing words":  {Palette: []uint32{1, 2}, BitsPerIndex: 1, Words: make([]uint32, 4)},
+		// The voxel at the start of the ray has palette index 1, but the palette only holds one entry.
+		"palette index": {Palette: []uint32{1}, BitsPerIndex: 1, Words: append([]uint32{1}, make([]uint32, 127)...)},
+	} {
+		w := &palettedWorld{sections: map[BlockPos]PalettedSection{{}: s}}
+		if _, _, _, err := FirstHitPaletted(w, start, end, solid); err == nil {
+			t.Errorf("%v: expected an error", name)
+		}
+	}
+}
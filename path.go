@@ -0,0 +1,171 @@
+package voxelraytrace
+
+import (
+	"github.com/go-gl/mathgl/mgl64"
+	"math"
+	"time"
+)
+
+// VoxelPath is a list of voxels in the vector representation returned by BetweenPoints. Any []mgl64.Vec3 may be
+// converted to a VoxelPath, or assigned to one directly, to use its methods.
+type VoxelPath []mgl64.Vec3
+
+// Len returns the amount of voxels in the path.
+func (p VoxelPath) Len() int {
+	return len(p)
+}
+
+// First returns the first voxel of the path, or false if the path is empty.
+func (p VoxelPath) First() (mgl64.Vec3, bool) {
+	if len(p) == 0 {
+		return mgl64.Vec3{}, false
+	}
+	return p[0], true
+}
+
+// Last returns the last voxel of the path, or false if the path is empty.
+func (p VoxelPath) Last() (mgl64.Vec3, bool) {
+	if len(p) == 0 {
+		return mgl64.Vec3{}, false
+	}
+	return p[len(p)-1], true
+}
+
+// Contains checks if the path contains the voxel passed.
+func (p VoxelPath) Contains(v mgl64.Vec3) bool {
+	for _, voxel := range p {
+		if voxel == v {
+			return true
+		}
+	}
+	return false
+}
+
+// Filter returns a new path holding only the voxels for which pred returns true, as done by FilterVoxels.
+func (p VoxelPath) Filter(pred func(mgl64.Vec3) bool) VoxelPath {
+	return FilterVoxels(p, pred)
+}
+
+// Reverse returns a new path holding the voxels of the path in reverse order.
+func (p VoxelPath) Reverse() VoxelPath {
+	if p == nil {
+		return nil
+	}
+	reversed := make(VoxelPath, len(p))
+	for i, v := range p {
+		reversed[len(p)-1-i] = v
+	}
+	return reversed
+}
+
+// Compress returns a new path in which every run of the same voxel repeated directly after itself is replaced by a
+// single voxel. Unlike Unique, a voxel that occurs again later in the path is kept.
+func (p VoxelPath) Compress() VoxelPath {
+	var compressed VoxelPath
+	for i, v := range p {
+		if i == 0 || v != p[i-1] {
+			compressed = append(compressed, v)
+		}
+	}
+	return compressed
+}
+
+// Unique returns a new path holding every voxel of the path once, at the position it first occurs at.
+func (p VoxelPath) Unique() VoxelPath {
+	var unique VoxelPath
+	seen := make(map[mgl64.Vec3]struct{}, len(p))
+	for _, v := range p {
+		if _, ok := seen[v]; !ok {
+			seen[v] = struct{}{}
+			unique = append(unique, v)
+		}
+	}
+	return unique
+}
+
+// Bounds returns the smallest and largest coordinates of the voxels in the path on every axis. Note that max is the
+// minimum corner of a voxel, like every vector of the path, so the box spanned by the voxels ends at max+(1, 1, 1).
+// An empty path returns two zero vectors.
+func (p VoxelPath) Bounds() (min, max mgl64.Vec3) {
+	if len(p) == 0 {
+		return min, max
+	}
+	min, max = p[0], p[0]
+	for _, v := range p[1:] {
+		for i := 0; i < 3; i++ {
+			min[i], max[i] = math.Min(min[i], v[i]), math.Max(max[i], v[i])
+		}
+	}
+	return min, max
+}
+
+// Between performs a ray trace between the start and end coordinates like BetweenPoints, returning the voxels passed
+// through as a VoxelPath.
+func Between(start, end mgl64.Vec3, opts ...Option) (VoxelPath, error) {
+	return BetweenPoints(start, end, opts...)
+}
+
+// In performs a ray trace from the start position in the given direction like InDirection, returning the voxels
+// passed through as a VoxelPath.
+//
+// Deprecated: Use NewRay and Ray.Cast, of which the result may be converted to a VoxelPath.
+func In(start, directionVector mgl64.Vec3, maxDistance float64, opts ...Option) (VoxelPath, error) {
+	return InDirection(start, directionVector, maxDistance, opts...)
+}
+
+// AlongPath performs ray traces between every pair of consecutive points passed, such as the waypoints of a patrol
+// or the bounces of a laser, and returns the voxels passed through as a single VoxelPath. A voxel holding a point
+// between two segments is only included once, rather than once for each segment ending and starting in it, and
+// consecutive points that are the same are treated as one. A single point produces the voxel holding it, and no
+// points produce an empty path. The Options passed apply to every segment, except for WithMaxVoxels and
+// WithTimeBudget, which limit the path as a whole. If the trace is stopped early, the voxels passed through so far
+// are returned along with the error.
+func AlongPath(points []mgl64.Vec3, opts ...Option) (VoxelPath, error) {
+	if len(points) == 0 {
+		return nil, nil
+	}
+	conf := newConfig(opts)
+	var (
+		path  VoxelPath
+		began time.Time
+	)
+	for i := 1; i < len(points); i++ {
+		if points[i] == points[i-1] {
+			continue
+		}
+		t, err := newTracer(points[i-1], points[i], conf)
+		if err != nil {
+			return path, err
+		}
+		// The voxel the segment starts in is the last voxel of the segment before it.
+		joint := len(path) > 0 && t.pos().Vec3Min() == path[len(path)-1]
+		if conf.maxVoxels > 0 {
+			remaining := conf.maxVoxels - len(path)
+			if joint {
+				remaining++
+			}
+			if remaining <= 0 {
+				return path, ErrMaxVoxelsExceeded
+			}
+			t.maxVoxels = remaining
+		}
+		if began.IsZero() {
+			began = t.began
+		}
+		t.began = began
+		if !joint {
+			path = append(path, t.pos().Vec3Min())
+		}
+		for t.next() {
+			path = append(path, t.pos().Vec3Min())
+		}
+		if t.err != nil {
+			return path, t.err
+		}
+	}
+	if path == nil {
+		// All points are the same.
+		path = VoxelPath{VoxelAt(points[0], opts...).Vec3Min()}
+	}
+	return path, nil
+}
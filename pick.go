@@ -0,0 +1,95 @@
+package voxelraytrace
+
+import (
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// HitKind is the kind of object hit by a ray traced using RayTrace.
+type HitKind uint8
+
+const (
+	// HitMiss is the HitKind reported if the ray did not hit anything.
+	HitMiss HitKind = iota
+	// HitBlock is the HitKind reported if the ray hit a solid voxel first.
+	HitBlock
+	// HitEntity is the HitKind reported if the ray hit the box of an entity first.
+	HitEntity
+)
+
+// String returns the name of the kind of hit.
+func (k HitKind) String() string {
+	switch k {
+	case HitBlock:
+		return "block"
+	case HitEntity:
+		return "entity"
+	}
+	return "miss"
+}
+
+// RayTraceResult holds the result of a RayTrace.
+type RayTraceResult struct {
+	// Kind is the kind of object hit. The other fields are only set if it is not HitMiss.
+	Kind HitKind
+	// Pos is the position of the voxel hit, if Kind is HitBlock.
+	Pos BlockPos
+	// Entity is the index of the box hit, if Kind is HitEntity.
+	Entity int
+	// Point is the point at which the ray entered the voxel or box hit.
+	Point mgl64.Vec3
+	// Face is the face of the voxel or box through which the ray entered it, or FaceNone if the ray started inside it.
+	Face Face
+	// Distance is the distance from the start of the ray to Point.
+	Distance float64
+}
+
+// RayTrace traces a ray between the start and end coordinates against both the Grid and the boxes of entities passed,
+// such as to find what a player clicked on, and returns whichever of the two the ray hits first. The voxels are found
+// like FirstSolidHit, to which the Options passed apply, and the boxes like IntersectAABBs. The points at which the
+// ray enters the voxel and the box are compared using the same arithmetic, so that a box flush against a voxel is
+// compared exactly. If both are entered at the same point, the voxel is returned, as the box cannot be reached
+// without touching it. An error is returned if the trace through the Grid fails.
+func RayTrace(start, end mgl64.Vec3, g Grid, entities []AABB, opts ...Option) (RayTraceResult, error) {
+	var (
+		entity   RayTraceResult
+		entityAt float64
+	)
+	for i, box := range entities {
+		if hit, face, t, ok := clipAABB(start, end, box); ok && (entity.Kind == HitMiss || t < entityAt) {
+			entity, entityAt = RayTraceResult{Kind: HitEntity, Entity: i, Point: hit, Face: face, Distance: distance(start, hit)}, t
+		}
+	}
+
+	conf := newConfig(opts)
+	hit, ok, err := FirstSolidHit(g, start, end, opts...)
+	if err != nil {
+		return RayTraceResult{}, err
+	}
+	if ok {
+		// The voxel is clipped like the boxes, so that the positions along the ray at which both are entered compare
+		// consistently. If rounding makes the clip miss, the ray only grazes the voxel, and it is entered at the
+		// distance of the hit.
+		blockAt := 0.0
+		if !hit.StartedInside {
+			if _, _, t, clipped := clipAABB(start, end, conf.voxelBox(hit.Pos)); clipped {
+				blockAt = t
+			} else {
+				blockAt = hit.Distance / distance(start, end)
+			}
+		}
+		if entity.Kind == HitMiss || blockAt <= entityAt {
+			return RayTraceResult{Kind: HitBlock, Pos: hit.Pos, Point: hit.Point, Face: hit.Face, Distance: hit.Distance}, nil
+		}
+	}
+	return entity, nil
+}
+
+// voxelBox returns the world space box of the voxel at the position passed in the grid described by the config.
+func (c config) voxelBox(pos BlockPos) AABB {
+	var box AABB
+	for i := 0; i < 3; i++ {
+		box.Min[i] = float64(float64(pos[i])*c.cellSize[i]) + c.offset[i]
+		box.Max[i] = float64(float64(pos[i]+1)*c.cellSize[i]) + c.offset[i]
+	}
+	return box
+}
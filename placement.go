@@ -0,0 +1,19 @@
+package voxelraytrace
+
+// PlacementPos returns the position at which a block should be placed after a ray hit the voxel of the HitResult. If
+// replaceable returns true for the voxel hit, such as for grass or water, the block is placed into it. Otherwise, it
+// is placed against the face through which the voxel was entered. If replaceable is nil, no voxel is replaceable. If
+// the ray started inside the voxel hit, so that it has no face, the position of the voxel itself is returned.
+func PlacementPos(hit HitResult, replaceable func(pos BlockPos) bool) BlockPos {
+	if hit.Face == FaceNone || replaceable != nil && replaceable(hit.Pos) {
+		return hit.Pos
+	}
+	return hit.Pos.Side(hit.Face)
+}
+
+// PlacementPosWithin returns the position at which a block should be placed like PlacementPos, but also checks if
+// that position lies within the bounds passed, both inclusive. If it does not, false is returned.
+func PlacementPosWithin(hit HitResult, replaceable func(pos BlockPos) bool, min, max BlockPos) (BlockPos, bool) {
+	pos := PlacementPos(hit, replaceable)
+	return pos, insideRegion(pos, min, max)
+}
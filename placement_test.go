@@ -0,0 +1,85 @@
+package voxelraytrace
+
+import (
+	"github.com/go-gl/mathgl/mgl64"
+	"math/rand"
+	"testing"
+)
+
+func TestPlacementPos(t *testing.T) {
+	g := NewSparseGrid()
+	grass := BlockPos{0, 64, 0}
+	g.Set(grass[0], grass[1], grass[2], true)
+	hit, ok, err := FirstSolidHit(g, mgl64.Vec3{0.5, 70.5, 0.3}, mgl64.Vec3{0.5, 60.5, 0.7})
+	if err != nil || !ok || hit.Face != FaceUp {
+		t.Fatalf("got %v, %v, %v, want a hit on the top face of %v", hit, ok, err, grass)
+	}
+	isGrass := func(pos BlockPos) bool { return pos == grass }
+
+	if got := PlacementPos(hit, nil); got != (BlockPos{0, 65, 0}) {
+		t.Errorf("got %v, want the block placed above the grass", got)
+	}
+	if got := PlacementPos(hit, func(BlockPos) bool { return false }); got != (BlockPos{0, 65, 0}) {
+		t.Errorf("got %v, want the block placed above the grass", got)
+	}
+	if got := PlacementPos(hit, isGrass); got != grass {
+		t.Errorf("got %v, want the replaceable grass replaced", got)
+	}
+	west := HitResult{Pos: grass, Face: FaceWest}
+	if got := PlacementPos(west, nil); got != (BlockPos{-1, 64, 0}) {
+		t.Errorf("got %v, want the block placed west of the grass", got)
+	}
+	inside := HitResult{Pos: grass, Face: FaceNone, StartedInside: true}
+	if got := PlacementPos(inside, nil); got != grass {
+		t.Errorf("got %v for a ray starting inside the voxel, want the voxel itself", got)
+	}
+}
+
+func TestPlacementPosWithin(t *testing.T) {
+	min, max := BlockPos{-8, 0, -8}, BlockPos{7, 255, 7}
+	tests := []struct {
+		name        string
+		hit         HitResult
+		replaceable bool
+		want        BlockPos
+		ok          bool
+	}{
+		{name: "inside", hit: HitResult{Pos: BlockPos{0, 64, 0}, Face: FaceUp}, want: BlockPos{0, 65, 0}, ok: true},
+		{name: "above world", hit: HitResult{Pos: BlockPos{0, 255, 0}, Face: FaceUp}, want: BlockPos{0, 256, 0}},
+		{name: "below world", hit: HitResult{Pos: BlockPos{0, 0, 0}, Face: FaceDown}, want: BlockPos{0, -1, 0}},
+		{name: "replaced at edge", hit: HitResult{Pos: BlockPos{7, 0, 7}, Face: FaceEast}, replaceable: true, want: BlockPos{7, 0, 7}, ok: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			replaceable := func(BlockPos) bool { return test.replaceable }
+			got, ok := PlacementPosWithin(test.hit, replaceable, min, max)
+			if got != test.want || ok != test.ok {
+				t.Errorf("got %v, %v, want %v, %v", got, ok, test.want, test.ok)
+			}
+		})
+	}
+}
+
+func TestPlacementPosMatchesTrace(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	g := randomGrid(rng, 8, 80)
+	for i := 0; i < 5000; i++ {
+		start, end := randomPoint(rng, 12), randomPoint(rng, 12)
+		hit, ok, err := FirstSolidHit(g, start, end)
+		if err != nil || !ok || hit.StartedInside {
+			continue
+		}
+		// The block is placed into the voxel the ray passed through right before the one it hit, which is empty.
+		positions, _ := BetweenPointsInt(start, end)
+		var want BlockPos
+		for j, pos := range positions {
+			if pos == hit.Pos {
+				want = positions[j-1]
+				break
+			}
+		}
+		if got := PlacementPos(hit, nil); got != want || g.Solid(got[0], got[1], got[2]) {
+			t.Fatalf("trace %v -> %v: got %v after hitting %v, want %v", start, end, got, hit.Pos, want)
+		}
+	}
+}
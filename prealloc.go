@@ -0,0 +1,115 @@
+package voxelraytrace
+
+import (
+	"github.com/go-gl/mathgl/mgl64"
+	"math"
+	"sync"
+)
+
+// MaxStepBound returns an upper bound of the amount of voxels a ray trace between the start and end coordinates
+// passes through. It is computed from the amount of voxel boundaries crossed on every axis, plus one per axis to
+// account for the boundary ownership and grid offset Options, so it overestimates by at most 3.
+func MaxStepBound(start, end mgl64.Vec3) int {
+	n := 1
+	for i := 0; i < 3; i++ {
+		n += int(math.Abs(math.Floor(end[i])-math.Floor(start[i]))) + 1
+	}
+	return n
+}
+
+// stepBound returns an upper bound of the amount of voxels a ray trace between the start and end coordinates passes
+// through with the config, computed like MaxStepBound from the coordinates in grid space and limited to the maximum
+// amount of voxels set using WithMaxVoxels.
+func (c config) stepBound(start, end mgl64.Vec3) int {
+	n := MaxStepBound(c.toCells(start.Sub(c.offset)), c.toCells(end.Sub(c.offset)))
+	if c.maxVoxels > 0 && c.maxVoxels < n {
+		n = c.maxVoxels
+	}
+	return n
+}
+
+// BetweenPointsPrealloc performs a ray trace between the start and end coordinates like BetweenPoints, but allocates
+// the slice returned once, using MaxStepBound on the coordinates in grid space, so that Options such as WithCellSize
+// are taken into account, rather than growing it as voxels are passed through. The capacity of the slice returned is
+// trimmed to its length.
+func BetweenPointsPrealloc(start, end mgl64.Vec3, opts ...Option) ([]mgl64.Vec3, error) {
+	conf := newConfig(opts)
+	t, err := newTracer(start, end, conf)
+	if err != nil {
+		return nil, err
+	}
+	if !t.enter(conf) {
+		return nil, t.err
+	}
+	vectors := make([]mgl64.Vec3, 0, conf.stepBound(start, end))
+	for {
+		vectors = append(vectors, t.pos().Vec3Min())
+		if !t.next() {
+			break
+		}
+	}
+	return vectors[:len(vectors):len(vectors)], t.err
+}
+
+// BetweenPointsAppend performs a ray trace between the start and end coordinates like BetweenPoints, but appends the
+// voxels passed through to dst and returns the extended slice, like the append built-in. Passing the slice returned
+// by a previous call, truncated to a length of 0, reuses its memory, so that tracing many rays does not allocate. If
+// dst lacks the capacity for the voxels of the ray, it is grown once, using MaxStepBound. If the trace fails, dst is
+// returned along with the voxels appended so far.
+func BetweenPointsAppend(dst []mgl64.Vec3, start, end mgl64.Vec3, opts ...Option) ([]mgl64.Vec3, error) {
+	conf := newConfig(opts)
+	t, err := newTracer(start, end, conf)
+	if err != nil {
+		return dst, err
+	}
+	if !t.enter(conf) {
+		return dst, t.err
+	}
+	if n := conf.stepBound(start, end); cap(dst)-len(dst) < n {
+		grown := make([]mgl64.Vec3, len(dst), len(dst)+n)
+		copy(grown, dst)
+		dst = grown
+	}
+	for {
+		dst = append(dst, t.pos().Vec3Min())
+		if !t.next() {
+			break
+		}
+	}
+	return dst, t.err
+}
+
+// maxPooledVoxels is the largest capacity of the voxels of a Result that is put back into the pool on release, so that
+// a single very long ray does not keep a large slice alive for the rest of the program.
+const maxPooledVoxels = 1 << 16
+
+// resultPool holds Results released using Result.Release, to be reused by BetweenPointsPooled.
+var resultPool = sync.Pool{New: func() interface{} { return &Result{} }}
+
+// Result holds the voxels passed through by a ray trace performed using BetweenPointsPooled. A Result is taken from
+// a pool shared by all goroutines and may be returned to it using Release once it is no longer used, so that tracing
+// many rays, such as every tick of a server, does not allocate a new slice for every ray.
+type Result struct {
+	// Voxels holds the voxels passed through, in the vector representation and order returned by BetweenPoints.
+	Voxels []mgl64.Vec3
+}
+
+// Release returns the Result to the pool. Neither the Result nor its Voxels may be used after calling Release.
+func (r *Result) Release() {
+	if cap(r.Voxels) > maxPooledVoxels {
+		r.Voxels = nil
+	}
+	r.Voxels = r.Voxels[:0]
+	resultPool.Put(r)
+}
+
+// BetweenPointsPooled performs a ray trace between the start and end coordinates like BetweenPoints, but returns the
+// voxels passed through in a Result taken from a pool, reusing the memory of Results released before. The voxels are
+// appended like BetweenPointsAppend, so the slice is grown at most once, using MaxStepBound. The Result is returned
+// even if the trace fails, holding the voxels passed through so far, and should be released either way.
+func BetweenPointsPooled(start, end mgl64.Vec3, opts ...Option) (*Result, error) {
+	r := resultPool.Get().(*Result)
+	var err error
+	r.Voxels, err = BetweenPointsAppend(r.Voxels[:0], start, end, opts...)
+	return r, err
+}
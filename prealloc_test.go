@@ -0,0 +1,195 @@
+package voxelraytrace
+
+import (
+	"fmt"
+	"github.com/go-gl/mathgl/mgl64"
+	"math/rand"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+// preallocOptions are the sets of Options that BetweenPointsPrealloc and BetweenPointsAppend are checked with.
+var preallocOptions = [][]Option{
+	nil,
+	{WithCellSize(0.25, 0.5, 0.125)},
+	{WithCenteredVoxels()},
+	{WithBounds(BlockPos{-2, -1, -3}, BlockPos{2, 3, 1})},
+	{WithMinDistance(1.5)},
+	{WithMaxVoxels(5)},
+	{WithWrap(4, 0, 4)},
+}
+
+func TestBetweenPointsPrealloc(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for _, opts := range preallocOptions {
+		for i := 0; i < 2000; i++ {
+			start, end := randomPoint(rng, 8), randomPoint(rng, 8)
+			if start == end {
+				continue
+			}
+			want, wantErr := BetweenPoints(start, end, opts...)
+			got, err := BetweenPointsPrealloc(start, end, opts...)
+			if err != wantErr || !reflect.DeepEqual(got, want) {
+				t.Fatalf("trace %v -> %v: got %v, %v, want %v, %v", start, end, got, err, want, wantErr)
+			}
+			if len(got) != cap(got) {
+				t.Fatalf("trace %v -> %v: got length %v and capacity %v", start, end, len(got), cap(got))
+			}
+		}
+	}
+}
+
+func TestBetweenPointsPreallocAllocatesOnce(t *testing.T) {
+	start, end := mgl64.Vec3{0.3, 0.2, 0.1}, mgl64.Vec3{7.9, -3.4, 5.2}
+	for _, opts := range [][]Option{nil, {WithCellSize(0.1, 0.1, 0.1)}, {WithCenteredVoxels()}} {
+		// A ray inside a single voxel needs a slice of the same capacity whether it is grown or not.
+		single := testing.AllocsPerRun(10, func() {
+			_, _ = BetweenPointsPrealloc(mgl64.Vec3{0.1, 0.1, 0.1}, mgl64.Vec3{0.05, 0.05, 0.05}, opts...)
+		})
+		allocs := testing.AllocsPerRun(10, func() {
+			_, _ = BetweenPointsPrealloc(start, end, opts...)
+		})
+		if allocs != single {
+			t.Errorf("got %v allocations, want %v", allocs, single)
+		}
+	}
+}
+
+func TestBetweenPointsAppend(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	prefix := []mgl64.Vec3{{1, 2, 3}}
+	for _, opts := range preallocOptions {
+		for i := 0; i < 2000; i++ {
+			start, end := randomPoint(rng, 8), randomPoint(rng, 8)
+			if start == end {
+				continue
+			}
+			want, wantErr := BetweenPoints(start, end, opts...)
+			got, err := BetweenPointsAppend(prefix[:1:1], start, end, opts...)
+			if err != wantErr || !reflect.DeepEqual(got[1:], want) && len(want) > 0 || got[0] != prefix[0] {
+				t.Fatalf("trace %v -> %v: got %v, %v, want %v, %v", start, end, got, err, want, wantErr)
+			}
+		}
+	}
+	dst := make([]mgl64.Vec3, 0, 64)
+	single := testing.AllocsPerRun(10, func() {
+		dst, _ = BetweenPointsAppend(dst[:0], mgl64.Vec3{0.5, 0.5, 0.5}, mgl64.Vec3{0.25, 0.25, 0.25})
+	})
+	allocs := testing.AllocsPerRun(10, func() {
+		dst, _ = BetweenPointsAppend(dst[:0], mgl64.Vec3{0.5, 0.5, 0.5}, mgl64.Vec3{8.5, 3.5, -2.5})
+	})
+	if allocs != single {
+		t.Errorf("got %v allocations when reusing a slice with enough capacity, want %v", allocs, single)
+	}
+}
+
+func TestMaxStepBound(t *testing.T) {
+	rng := rand.New(rand.NewSource(3))
+	for _, opts := range [][]Option{nil, {WithCeilOwnership()}, {WithCenteredVoxels()}, {WithBoundaryTowardDirection()}} {
+		for i := 0; i < 5000; i++ {
+			start, end := randomPoint(rng, 20), randomPoint(rng, 20)
+			positions, err := BetweenPoints(start, end, opts...)
+			if err != nil {
+				continue
+			}
+			// The bound is computed from the coordinates alone, so it must hold for every convention.
+			if n := MaxStepBound(start, end); n < len(positions) || n > len(positions)+3 && opts == nil {
+				t.Fatalf("trace %v -> %v: got bound %v for %v voxels", start, end, n, len(positions))
+			}
+		}
+	}
+}
+
+func TestBetweenPointsPooled(t *testing.T) {
+	rng := rand.New(rand.NewSource(4))
+	for _, opts := range preallocOptions {
+		for i := 0; i < 2000; i++ {
+			start, end := randomPoint(rng, 8), randomPoint(rng, 8)
+			if start == end {
+				continue
+			}
+			want, wantErr := BetweenPoints(start, end, opts...)
+			r, err := BetweenPointsPooled(start, end, opts...)
+			if err != wantErr || !reflect.DeepEqual(r.Voxels, want) && len(want) > 0 {
+				t.Fatalf("trace %v -> %v: got %v, %v, want %v, %v", start, end, r.Voxels, err, want, wantErr)
+			}
+			r.Release()
+		}
+	}
+
+	// A Result released is reused by the next trace, so tracing with a released Result allocates less than growing
+	// a new slice.
+	start, end := mgl64.Vec3{0.5, 0.5, 0.5}, mgl64.Vec3{40.2, -30.3, 20.1}
+	pooled := testing.AllocsPerRun(20, func() {
+		r, _ := BetweenPointsPooled(start, end)
+		r.Release()
+	})
+	grown := testing.AllocsPerRun(20, func() {
+		_, _ = BetweenPoints(start, end)
+	})
+	if pooled >= grown {
+		t.Errorf("got %v allocations per pooled trace, want fewer than the %v of BetweenPoints", pooled, grown)
+	}
+}
+
+func TestBetweenPointsPooledConcurrent(t *testing.T) {
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(seed int64) {
+			defer wg.Done()
+			rng := rand.New(rand.NewSource(seed))
+			for i := 0; i < 500; i++ {
+				start, end := randomPoint(rng, 8), randomPoint(rng, 8)
+				want, wantErr := BetweenPoints(start, end)
+				r, err := BetweenPointsPooled(start, end)
+				if err != wantErr || !reflect.DeepEqual(r.Voxels, want) && len(want) > 0 {
+					t.Errorf("trace %v -> %v: got %v, %v, want %v, %v", start, end, r.Voxels, err, want, wantErr)
+				}
+				r.Release()
+			}
+		}(int64(g))
+	}
+	wg.Wait()
+}
+
+// stepRays are the ray lengths, in voxels, that the preallocation benchmarks are run with.
+var stepRays = []int{100, 1000, 10000}
+
+func BenchmarkBetweenPoints(b *testing.B) {
+	for _, n := range stepRays {
+		b.Run(fmt.Sprint(n), func(b *testing.B) {
+			start, end := mgl64.Vec3{0.5, 0.5, 0.5}, mgl64.Vec3{float64(n)/3 + 0.2, float64(n)/3 + 0.3, float64(n)/3 + 0.1}
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_, _ = BetweenPoints(start, end)
+			}
+		})
+	}
+}
+
+func BenchmarkBetweenPointsPrealloc(b *testing.B) {
+	for _, n := range stepRays {
+		b.Run(fmt.Sprint(n), func(b *testing.B) {
+			start, end := mgl64.Vec3{0.5, 0.5, 0.5}, mgl64.Vec3{float64(n)/3 + 0.2, float64(n)/3 + 0.3, float64(n)/3 + 0.1}
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_, _ = BetweenPointsPrealloc(start, end)
+			}
+		})
+	}
+}
+
+func BenchmarkBetweenPointsPooled(b *testing.B) {
+	for _, n := range stepRays {
+		b.Run(fmt.Sprint(n), func(b *testing.B) {
+			start, end := mgl64.Vec3{0.5, 0.5, 0.5}, mgl64.Vec3{float64(n)/3 + 0.2, float64(n)/3 + 0.3, float64(n)/3 + 0.1}
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				r, _ := BetweenPointsPooled(start, end)
+				r.Release()
+			}
+		})
+	}
+}
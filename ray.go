@@ -0,0 +1,75 @@
+package voxelraytrace
+
+import (
+	"errors"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// Ray is a ray starting at an origin, travelling in a direction for a maximum distance. It is a value type and may be
+// stored and copied cheaply. Rays should be created using NewRay, which normalises the direction.
+type Ray struct {
+	// Origin is the point at which the ray starts.
+	Origin mgl64.Vec3
+	// Direction is the normalised direction in which the ray travels.
+	Direction mgl64.Vec3
+	// MaxDistance is the distance the ray travels from its origin.
+	MaxDistance float64
+}
+
+// NewRay creates a Ray from the origin in the direction passed, travelling for a distance of maxDistance. The
+// direction is normalised. An error is returned if the direction is zero or if maxDistance is not positive.
+func NewRay(origin, direction mgl64.Vec3, maxDistance float64) (Ray, error) {
+	if !(sumSquares(direction[0], direction[1], direction[2]) > 0) {
+		return Ray{}, ErrZeroDirection
+	}
+	if !(maxDistance > 0) {
+		return Ray{}, errors.New("ray max distance must be positive")
+	}
+	return Ray{Origin: origin, Direction: yUp.normalize(direction), MaxDistance: maxDistance}, nil
+}
+
+// End returns the point at which the ray ends.
+func (r Ray) End() mgl64.Vec3 {
+	return r.At(r.MaxDistance)
+}
+
+// At returns the point on the ray at the distance passed from its origin.
+func (r Ray) At(dist float64) mgl64.Vec3 {
+	return along(r.Origin, r.Direction, dist)
+}
+
+// Cast performs a ray trace along the ray and returns the coordinates of the voxels it passes through, like
+// BetweenPoints.
+func (r Ray) Cast(opts ...Option) ([]mgl64.Vec3, error) {
+	return BetweenPoints(r.Origin, r.End(), opts...)
+}
+
+// FirstHit performs a ray trace along the ray and returns the coordinates of the first voxel returned by Cast for
+// which pred returns true. If pred does not return true for any of the voxels, false is returned.
+func (r Ray) FirstHit(pred func(mgl64.Vec3) bool, opts ...Option) (mgl64.Vec3, bool, error) {
+	conf := newConfig(opts)
+	t, err := newTracer(r.Origin, r.End(), conf)
+	if err != nil {
+		return mgl64.Vec3{}, false, err
+	}
+	if !t.enter(conf) {
+		return mgl64.Vec3{}, false, t.err
+	}
+	for {
+		if v := t.pos().Vec3Min(); pred(v) {
+			return v, true, nil
+		}
+		if !t.next() {
+			return mgl64.Vec3{}, false, t.err
+		}
+	}
+}
+
+// Transform transforms the ray using the matrix passed, for example to move it into the local space of an object.
+// The maximum distance is scaled along with the direction, so that the transformed ray ends at the transformed end of
+// the original ray.
+func (r Ray) Transform(m mgl64.Mat4) (Ray, error) {
+	origin := m.Mul4x1(r.Origin.Vec4(1)).Vec3()
+	direction := m.Mul4x1(r.Direction.Vec4(0)).Vec3()
+	return NewRay(origin, direction, r.MaxDistance*direction.Len())
+}
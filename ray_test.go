@@ -0,0 +1,86 @@
+package voxelraytrace
+
+import (
+	"github.com/go-gl/mathgl/mgl64"
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestNewRay(t *testing.T) {
+	r, err := NewRay(mgl64.Vec3{1, 2, 3}, mgl64.Vec3{0, 3, 4}, 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !vec3Near(r.Direction, mgl64.Vec3{0, 0.6, 0.8}) {
+		t.Errorf("got direction %v, want [0 0.6 0.8]", r.Direction)
+	}
+	if got := r.At(5); !vec3Near(got, mgl64.Vec3{1, 5, 7}) {
+		t.Errorf("got %v at distance 5, want [1 5 7]", got)
+	}
+	if got := r.End(); !vec3Near(got, mgl64.Vec3{1, 8, 11}) {
+		t.Errorf("got end %v, want [1 8 11]", got)
+	}
+	if _, err := NewRay(mgl64.Vec3{}, mgl64.Vec3{}, 10); err != ErrZeroDirection {
+		t.Errorf("got %v for a zero direction, want %v", err, ErrZeroDirection)
+	}
+	for _, dist := range []float64{0, -1, math.NaN()} {
+		if _, err := NewRay(mgl64.Vec3{}, mgl64.Vec3{1, 0, 0}, dist); err == nil {
+			t.Errorf("expected an error for max distance %v", dist)
+		}
+	}
+}
+
+func TestRayCastAndFirstHit(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	solid := func(v mgl64.Vec3) bool {
+		return int(v[0]+v[1]+v[2])%4 == 3
+	}
+	for _, opts := range [][]Option{nil, {WithBounds(BlockPos{-2, -1, -3}, BlockPos{2, 3, 1})}, {WithMinDistance(2)}} {
+		for i := 0; i < 2000; i++ {
+			origin, dir := randomPoint(rng, 8), randomPoint(rng, 1)
+			r, err := NewRay(origin, dir, rng.Float64()*10+0.1)
+			if err != nil {
+				continue
+			}
+			want, wantErr := BetweenPoints(r.Origin, r.End(), opts...)
+			got, err := r.Cast(opts...)
+			if err != wantErr || !reflect.DeepEqual(got, want) {
+				t.Fatalf("%v: got %v, %v, want %v, %v", r, got, err, want, wantErr)
+			}
+			var wantHit mgl64.Vec3
+			wantOK := false
+			for _, v := range want {
+				if solid(v) {
+					wantHit, wantOK = v, true
+					break
+				}
+			}
+			if hit, ok, _ := r.FirstHit(solid, opts...); hit != wantHit || ok != wantOK {
+				t.Fatalf("%v: got first hit %v, %v, want %v, %v", r, hit, ok, wantHit, wantOK)
+			}
+		}
+	}
+}
+
+func TestRayTransform(t *testing.T) {
+	r, _ := NewRay(mgl64.Vec3{1, 2, 3}, mgl64.Vec3{1, 1, 0}, 4)
+	m := mgl64.Translate3D(5, 0, -2).Mul4(mgl64.Scale3D(2, 2, 2))
+	transformed, err := r.Transform(m)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := m.Mul4x1(r.Origin.Vec4(1)).Vec3(); !vec3Near(transformed.Origin, want) {
+		t.Errorf("got origin %v, want %v", transformed.Origin, want)
+	}
+	if want := m.Mul4x1(r.End().Vec4(1)).Vec3(); !vec3Near(transformed.End(), want) {
+		t.Errorf("got end %v, want %v", transformed.End(), want)
+	}
+	if math.Abs(transformed.Direction.Len()-1) > 1e-9 {
+		t.Errorf("got direction %v, want a normalised direction", transformed.Direction)
+	}
+	if _, err := r.Transform(mgl64.Scale3D(0, 0, 0)); err == nil {
+		t.Error("expected an error for a transform collapsing the direction")
+	}
+}
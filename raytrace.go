@@ -1,94 +1,653 @@
 package voxelraytrace
 
 import (
+	"context"
 	"errors"
 	"github.com/go-gl/mathgl/mgl64"
 	"math"
+	"time"
 )
 
-// InDirection performs a ray trace from the start position in the given direction, for a distance of the maxDistance.
-// This returns a Generator which yields Vector3s containing the coordinates of voxels it passes through.
-func InDirection(start, directionVector mgl64.Vec3, maxDistance float64) (vectors []mgl64.Vec3, err error) {
-	return BetweenPoints(start, start.Add(directionVector.Mul(maxDistance)))
+var (
+	// ErrZeroDirection is returned when a ray has no direction, because its start and end points are the same or its
+	// direction vector is zero, so that there is nothing to trace.
+	ErrZeroDirection = errors.New("zero direction vector")
+	// ErrNonFiniteInput is returned when a coordinate or direction passed to a trace is NaN or infinite.
+	ErrNonFiniteInput = errors.New("coordinates must not be NaN or infinite")
+)
+
+// InDirection performs a ray trace from the start position to the point at the direction vector multiplied by the
+// maxDistance away from it, and returns the coordinates of the voxels it passes through like BetweenPoints. The
+// direction vector is not normalised, so the distance travelled is only the maxDistance for a unit direction vector.
+//
+// Deprecated: Use NewRay, which normalises the direction vector and rejects a zero one, and Ray.Cast, with which the
+// distance travelled is always the maximum distance of the Ray.
+func InDirection(start, directionVector mgl64.Vec3, maxDistance float64, opts ...Option) (vectors []mgl64.Vec3, err error) {
+	return BetweenPoints(start, along(start, directionVector, maxDistance), opts...)
 }
 
 // BetweenPoints performs a ray trace between the start and end coordinates.
 // This returns an array of vectors containing the coordinates of voxels it passes through.
+// Voxels are half-open, so that the voxel n spans [n, n+1) on every axis and a point exactly on a boundary belongs to
+// the voxel above it. WithCeilOwnership may be passed to change this, and WithBoundaryTowardDirection to make a ray
+// starting or ending on a boundary only pass through the voxel on the side it travels through.
+// The trace is symmetric: tracing from end to start passes through the same voxels in reverse order, including where
+// the ray crosses an edge or corner of a voxel exactly, with or without WithSupercover. The only exceptions are
+// WithNudgeBoundary, which moves the start of the ray only, and Options that stop the trace early, which always cut
+// it short from the start.
+// If the trace is stopped early by one of the Options passed, the voxels passed through so far are returned along
+// with the error.
 // http://www.cse.yorku.ca/~amana/research/grid.pdf
-func BetweenPoints(start, end mgl64.Vec3) (vectors []mgl64.Vec3, err error) {
-	currentPoint := mgl64.Vec3{math.Floor(start.X()), math.Floor(start.Y()), math.Floor(start.Z())}
+func BetweenPoints(start, end mgl64.Vec3, opts ...Option) (vectors []mgl64.Vec3, err error) {
+	conf := newConfig(opts)
+	t, err := newTracer(start, end, conf)
+	if err != nil {
+		return nil, err
+	}
+	if !t.enter(conf) {
+		return nil, t.err
+	}
+	if axis, steps, ok := t.aligned(); ok {
+		vectors = make([]mgl64.Vec3, steps+1)
+		pos, step := t.pos(), t.step(axis)
+		for i := range vectors {
+			vectors[i] = pos.Vec3Min()
+			pos[axis] += step
+		}
+		return vectors, nil
+	}
+	if conf.supercover {
+		t.supercover(func(pos BlockPos) {
+			vectors = append(vectors, pos.Vec3Min())
+		})
+		return vectors, t.err
+	}
+	for {
+		vectors = append(vectors, t.pos().Vec3Min())
+		if !t.next() {
+			break
+		}
+	}
+	return vectors, t.err
+}
+
+// BetweenPointsInt performs a ray trace between the start and end coordinates like BetweenPoints, but returns the
+// positions of the voxels it passes through as BlockPos.
+func BetweenPointsInt(start, end mgl64.Vec3, opts ...Option) (positions []BlockPos, err error) {
+	conf := newConfig(opts)
+	t, err := newTracer(start, end, conf)
+	if err != nil {
+		return nil, err
+	}
+	if !t.enter(conf) {
+		return nil, t.err
+	}
+	if axis, steps, ok := t.aligned(); ok {
+		positions = make([]BlockPos, steps+1)
+		pos, step := t.pos(), t.step(axis)
+		for i := range positions {
+			positions[i] = pos
+			pos[axis] += step
+		}
+		return positions, nil
+	}
+	if conf.supercover {
+		t.supercover(func(pos BlockPos) {
+			positions = append(positions, pos)
+		})
+		return positions, t.err
+	}
+	for {
+		positions = append(positions, t.pos())
+		if !t.next() {
+			break
+		}
+	}
+	return positions, t.err
+}
+
+// BetweenPointsSafe performs a ray trace between the start and end coordinates like BetweenPoints, but never returns
+// an error, for code that does not care about edge cases, such as rendering. If the start and end coordinates are
+// the same, only the voxel containing them is returned. If any coordinate is NaN or infinite, nil is returned. If the
+// trace is stopped early by an Option, the voxels passed through so far are returned. BetweenPoints should be used
+// where errors must be reported.
+func BetweenPointsSafe(start, end mgl64.Vec3, opts ...Option) []mgl64.Vec3 {
+	if !finite(start) || !finite(end) {
+		return nil
+	}
+	if start == end {
+		return []mgl64.Vec3{VoxelAt(start, opts...).Vec3Min()}
+	}
+	vectors, _ := BetweenPoints(start, end, opts...)
+	return vectors
+}
+
+// TraceBetweenBlocks performs a ray trace between the centres of the voxels a and b and returns the positions of the
+// voxels it passes through. As the centres lie inside the voxels, a is always the first and b always the last voxel
+// returned. Neighbouring voxels produce exactly a and b, and a voxel traced to itself produces only that voxel.
+func TraceBetweenBlocks(a, b BlockPos) ([]BlockPos, error) {
+	if a == b {
+		return []BlockPos{a}, nil
+	}
+	return BetweenPointsInt(a.Vec3Centre(), b.Vec3Centre())
+}
+
+// VoxelAt returns the position of the voxel that contains the point passed. Options such as WithCenteredVoxels,
+// WithCeilOwnership and WithWrap are taken into account, so that the result agrees with the voxels returned by
+// BetweenPoints.
+func VoxelAt(p mgl64.Vec3, opts ...Option) BlockPos {
+	conf := newConfig(opts)
+	p = conf.toCells(p.Sub(conf.offset))
+	pos := BlockPosFromVec3(p)
+	if conf.ceil {
+		pos = BlockPos{int(ceilVoxel(p[0])), int(ceilVoxel(p[1])), int(ceilVoxel(p[2]))}
+	}
+	if conf.wrap != [3]int{} {
+		var wrap [3]int
+		for i, axis := range conf.order {
+			wrap[axis] = conf.wrap[i]
+		}
+		pos = wrapPos(pos, wrap)
+	}
+	return pos
+}
+
+// tracer holds the state of a voxel traversal between two points. It is the core shared by the traversal functions
+// of the package, keeping all state in scalars so that stepping does not need any vector arithmetic.
+type tracer struct {
+	// start and dir are the start of the ray in grid space and its normalised direction, divided by the cell size.
+	start, dir mgl64.Vec3
+	// offset is the world space position of the origin of the grid, and cell the size of its voxels in world space.
+	// Grid space coordinates are world space coordinates with the offset subtracted, divided by the cell size.
+	offset, cell mgl64.Vec3
+	// x, y and z are the coordinates of the current voxel.
+	x, y, z             int
+	stepX, stepY, stepZ int
+
+	// tMaxX, tMaxY and tMaxZ are the distances along the ray at which the next boundary is crossed on that axis.
+	tMaxX, tMaxY, tMaxZ float64
+	// keyX, keyY and keyZ are the order keys of the next boundary crossings, which are compared to decide which
+	// boundary is crossed first. See canonicalLine.crossing.
+	keyX, keyY, keyZ float64
+
+	// line is the segment traced in its canonical orientation, from which all boundary crossings are computed.
+	line canonicalLine
 
-	directionVector := end.Sub(start).Normalize()
-	if directionVector.LenSqr() <= 0 {
-		return nil, errors.New("start and end points are the same, giving a zero direction vector")
+	// xBeforeY, xBeforeZ and yBeforeZ specify which axis is stepped first if the boundaries on both axes are reached
+	// at the same distance. They are computed using tieBreaks.
+	xBeforeY, xBeforeZ, yBeforeZ bool
+
+	// faceX, faceY and faceZ are the faces through which a voxel is entered when stepping on that axis.
+	faceX, faceY, faceZ Face
+
+	// face is the face through which the current voxel was entered, or FaceNone for the first voxel.
+	face Face
+	// t is the distance along the ray at which the current voxel was entered. radius is the length of the ray.
+	t, radius float64
+	// limitX, limitY and limitZ are the largest distances at which the tracer may step on that axis. They depend on
+	// the radius and on whether the voxel stepped into owns the boundary at the end of the ray.
+	limitX, limitY, limitZ float64
+	// ceil specifies if voxel boundaries belong to the voxel below them rather than the voxel above them.
+	ceil bool
+	// toward specifies if boundaries at the endpoints of the ray belong to the voxel on the side the ray travels
+	// through, as set using WithBoundaryTowardDirection.
+	toward bool
+
+	// limited specifies if any of the limits below are set, in which case they are checked before every step.
+	limited bool
+	// visited is the amount of voxels visited so far, including the current one.
+	visited   int
+	maxVoxels int
+	ranges    [3]axisRange
+	// origin is the voxel the ray starts in, and maxManhattan the largest Manhattan distance from it that the tracer
+	// may step to, or -1 if not limited. Limits on the Chebyshev distance are stored in the ranges.
+	origin       BlockPos
+	maxManhattan int
+	began        time.Time
+	budget       time.Duration
+	ctx          context.Context
+	// err is the error that caused the trace to stop early, if any.
+	err error
+
+	// wrap holds the size of the world on every axis that the coordinates reported by pos wrap around, or 0 if they
+	// do not. wrapped specifies if this is the case for any axis.
+	wrap    [3]int
+	wrapped bool
+	// hook is called with the state of the tracer for every voxel visited, if not nil.
+	hook func(StepInfo)
+}
+
+// limitCheckPeriod is the amount of voxels visited between checks of the time budget and context of a trace. The
+// checks are too expensive to do for every voxel.
+const limitCheckPeriod = 64
+
+// newTracer creates a tracer for a ray trace between the start and end coordinates, positioned at the voxel that
+// contains the start coordinates.
+func newTracer(start, end mgl64.Vec3, conf config) (tracer, error) {
+	if !finite(start) || !finite(end) {
+		return tracer{}, ErrNonFiniteInput
+	}
+	diff := end.Sub(start)
+	if sumSquares(diff[0], diff[1], diff[2]) <= 0 {
+		return tracer{}, ErrZeroDirection
+	}
+	start, end = start.Sub(conf.offset), end.Sub(conf.offset)
+	if conf.nudge {
+		directionVector, radius := conf.order.normalize(diff), conf.order.length(end.Sub(start))
+		if d := nudgeDistance(conf.toCells(start), conf.toCells(directionVector), nudgeEpsilon); d < radius {
+			start = along(start, directionVector, d)
+		}
 	}
+	return newLineTracer(conf.toCells(start), newCanonicalLine(start, end, conf.order).toCells(conf), conf), nil
+}
 
-	radius := distance(start, end)
+// newUnitTracer creates a tracer for a ray trace from the start coordinates along the normalised direction vector
+// passed, for the distance of the radius, positioned at the voxel that contains the start coordinates. Unlike
+// newTracer, the direction vector is used as is, and the radius may be infinite.
+func newUnitTracer(start, directionVector mgl64.Vec3, radius float64, conf config) tracer {
+	start = start.Sub(conf.offset)
+	if conf.nudge {
+		if d := nudgeDistance(conf.toCells(start), conf.toCells(directionVector), nudgeEpsilon); d < radius {
+			start, radius = along(start, directionVector, d), radius-d
+		}
+	}
+	return newLineTracer(conf.toCells(start), canonicalLine{
+		origin:   start,
+		far:      along(start, directionVector, radius),
+		dir:      directionVector,
+		length:   radius,
+		directed: true,
+	}.toCells(conf), conf)
+}
+
+// newLineTracer creates a tracer for a ray trace along the canonical line passed, starting at the grid space start
+// coordinates, which must be one of its endpoints.
+func newLineTracer(start mgl64.Vec3, line canonicalLine, conf config) tracer {
+	directionVector := line.direction()
+	startVoxel := math.Floor
+	if conf.ceil {
+		startVoxel = ceilVoxel
+	}
 
 	stepX := compareTo(directionVector.X(), 0)
 	stepY := compareTo(directionVector.Y(), 0)
 	stepZ := compareTo(directionVector.Z(), 0)
 
-	tMaxX := rayTraceDistanceToBoundary(start.X(), directionVector.X())
-	tMaxY := rayTraceDistanceToBoundary(start.Y(), directionVector.Y())
-	tMaxZ := rayTraceDistanceToBoundary(start.Z(), directionVector.Z())
+	var began time.Time
+	if conf.budget > 0 {
+		began = time.Now()
+	}
+	xBeforeY, xBeforeZ, yBeforeZ := tieBreaks(int(stepX), int(stepY), int(stepZ), conf.order)
+	origin := BlockPos{int(startVoxel(start.X())), int(startVoxel(start.Y())), int(startVoxel(start.Z()))}
+	if conf.toward {
+		origin = voxelAlong(start, directionVector, conf.ceil)
+	}
+	var ranges [3]axisRange
+	var wrap [3]int
+	for i, axis := range conf.order {
+		ranges[axis], wrap[axis] = conf.ranges[i], conf.wrap[i]
+	}
+	if n := conf.maxChebyshev; n >= 0 {
+		for i, r := range ranges {
+			if !r.set || r.min < origin[i]-n {
+				r.min = origin[i] - n
+			}
+			if !r.set || r.max > origin[i]+n {
+				r.max = origin[i] + n
+			}
+			r.set = true
+			ranges[i] = r
+		}
+	}
+	t := tracer{
+		start:  start,
+		dir:    directionVector,
+		offset: conf.offset,
+		cell:   conf.cellSize,
+
+		x: origin[0],
+		y: origin[1],
+		z: origin[2],
 
-	tDeltaX := findDelta(directionVector.X(), stepX)
-	tDeltaY := findDelta(directionVector.Y(), stepY)
-	tDeltaZ := findDelta(directionVector.Z(), stepZ)
+		stepX: int(stepX),
+		stepY: int(stepY),
+		stepZ: int(stepZ),
 
-	for {
-		vectors = append(vectors, currentPoint)
+		line: line,
 
-		if tMaxX < tMaxY && tMaxX < tMaxZ {
-			if tMaxX > radius {
-				break
-			}
-			currentPoint = currentPoint.Add(mgl64.Vec3{stepX})
-			tMaxX += tDeltaX
-		} else if tMaxY < tMaxZ {
-			if tMaxY > radius {
-				break
-			}
-			currentPoint = currentPoint.Add(mgl64.Vec3{0, stepY})
-			tMaxY += tDeltaY
-		} else {
-			if tMaxZ > radius {
-				break
+		faceX: axisEntryFace(int(stepX), conf.order[0]),
+		faceY: axisEntryFace(int(stepY), conf.order[1]),
+		faceZ: axisEntryFace(int(stepZ), conf.order[2]),
+
+		xBeforeY: xBeforeY,
+		xBeforeZ: xBeforeZ,
+		yBeforeZ: yBeforeZ,
+
+		radius: line.length,
+		limitX: endLimit(line.length, int(stepX), conf.ceil, conf.toward),
+		limitY: endLimit(line.length, int(stepY), conf.ceil, conf.toward),
+		limitZ: endLimit(line.length, int(stepZ), conf.ceil, conf.toward),
+		ceil:   conf.ceil,
+		toward: conf.toward,
+
+		limited: conf.maxVoxels > 0 || conf.budget > 0 || conf.ctx != nil || conf.ranged() ||
+			conf.maxChebyshev >= 0 || conf.maxManhattan >= 0,
+		visited:      1,
+		maxVoxels:    conf.maxVoxels,
+		ranges:       ranges,
+		origin:       origin,
+		maxManhattan: conf.maxManhattan,
+		began:        began,
+		budget:       conf.budget,
+		ctx:          conf.ctx,
+		hook:         conf.hook,
+		wrap:         wrap,
+		wrapped:      wrap != [3]int{},
+	}
+	t.cross()
+	if t.hook != nil {
+		t.hook(t.stepInfo())
+	}
+	return t
+}
+
+// setRadius changes the length of the ray traced by the tracer.
+func (t *tracer) setRadius(radius float64) {
+	t.radius = radius
+	t.limitX = endLimit(radius, t.stepX, t.ceil, t.toward)
+	t.limitY = endLimit(radius, t.stepY, t.ceil, t.toward)
+	t.limitZ = endLimit(radius, t.stepZ, t.ceil, t.toward)
+}
+
+// extend makes the ray traced by the tracer longer by the extra distance passed. If the ray was created with a
+// direction vector, its end is moved as well, so that the tracer behaves exactly like one created with the new
+// length from the start.
+func (t *tracer) extend(extra float64) {
+	radius := t.radius + extra
+	if t.line.directed {
+		t.line.far, t.line.length = along(t.line.origin, t.line.dir, radius), radius
+		t.cross()
+	}
+	t.setRadius(radius)
+}
+
+// endLimit returns the largest distance at which a ray with the radius passed may step on an axis with the step
+// passed. If the ray ends exactly on a voxel boundary, it only steps into the voxel beyond that boundary if the
+// boundary belongs to that voxel, which is the case when stepping towards positive coordinates with half-open voxels
+// or towards negative coordinates with ceil ownership. If toward is true, the ray never steps into that voxel.
+func endLimit(radius float64, step int, ceil, toward bool) float64 {
+	if !toward && (step > 0) != ceil {
+		return radius
+	}
+	return math.Nextafter(radius, math.Inf(-1))
+}
+
+// point returns the world space point on the ray at the distance passed from its start.
+func (t *tracer) point(dist float64) mgl64.Vec3 {
+	return t.world(along(t.start, t.dir, dist))
+}
+
+// world returns the world space position of the grid space coordinates passed.
+func (t *tracer) world(p mgl64.Vec3) mgl64.Vec3 {
+	return mgl64.Vec3{
+		float64(p[0]*t.cell[0]) + t.offset[0],
+		float64(p[1]*t.cell[1]) + t.offset[1],
+		float64(p[2]*t.cell[2]) + t.offset[2],
+	}
+}
+
+// worldDir returns the normalised world space direction of the ray.
+func (t *tracer) worldDir() mgl64.Vec3 {
+	return mgl64.Vec3{t.dir[0] * t.cell[0], t.dir[1] * t.cell[1], t.dir[2] * t.cell[2]}
+}
+
+// pos returns the position of the voxel that the tracer is currently at, with its coordinates wrapped around the world
+// if set using WithWrap.
+func (t *tracer) pos() BlockPos {
+	if t.wrapped {
+		return wrapPos(BlockPos{t.x, t.y, t.z}, t.wrap)
+	}
+	return BlockPos{t.x, t.y, t.z}
+}
+
+// rawPos returns the position of the voxel that the tracer is currently at, without wrapping its coordinates, so that
+// it may be compared to the ray itself and to limits on the coordinates.
+func (t *tracer) rawPos() BlockPos {
+	return BlockPos{t.x, t.y, t.z}
+}
+
+// wrapPos wraps the coordinates of the position passed around the world on every axis of which the size passed is not
+// 0.
+func wrapPos(pos BlockPos, wrap [3]int) BlockPos {
+	for i, size := range wrap {
+		if size > 0 {
+			if pos[i] %= size; pos[i] < 0 {
+				pos[i] += size
 			}
-			currentPoint = currentPoint.Add(mgl64.Vec3{0, 0, stepZ})
-			tMaxZ += tDeltaZ
 		}
 	}
+	return pos
+}
+
+// next moves the tracer to the next voxel passed through by the ray. If the ray ends before reaching the next voxel,
+// false is returned and the tracer is left unchanged.
+func (t *tracer) next() bool {
+	switch t.nextAxis() {
+	case 0:
+		if t.tMaxX > t.limitX || t.limited && !t.withinLimits(0, t.x+t.stepX) {
+			return false
+		}
+		t.x, t.t, t.face = t.x+t.stepX, t.tMaxX, t.faceX
+		t.tMaxX, t.keyX = t.line.crossing(0, t.x, t.stepX)
+	case 1:
+		if t.tMaxY > t.limitY || t.limited && !t.withinLimits(1, t.y+t.stepY) {
+			return false
+		}
+		t.y, t.t, t.face = t.y+t.stepY, t.tMaxY, t.faceY
+		t.tMaxY, t.keyY = t.line.crossing(1, t.y, t.stepY)
+	default:
+		if t.tMaxZ > t.limitZ || t.limited && !t.withinLimits(2, t.z+t.stepZ) {
+			return false
+		}
+		t.z, t.t, t.face = t.z+t.stepZ, t.tMaxZ, t.faceZ
+		t.tMaxZ, t.keyZ = t.line.crossing(2, t.z, t.stepZ)
+	}
+	if t.hook != nil {
+		t.hook(t.stepInfo())
+	}
+	return true
+}
+
+// cross computes the next boundary crossings of the tracer on every axis from the voxel it is currently at.
+func (t *tracer) cross() {
+	t.tMaxX, t.keyX = t.line.crossing(0, t.x, t.stepX)
+	t.tMaxY, t.keyY = t.line.crossing(1, t.y, t.stepY)
+	t.tMaxZ, t.keyZ = t.line.crossing(2, t.z, t.stepZ)
+}
 
-	return
+// nextAxis returns the axis on which the tracer steps next: the axis of which the next boundary is nearest, with
+// ties broken as described in tieBreaks. Axes on which the ray may no longer step are only returned if this is the
+// case for every axis, so that a ray ending exactly on an edge or corner still steps into the voxels that own it.
+func (t *tracer) nextAxis() int {
+	x, y, z := capLimit(t.keyX, t.tMaxX, t.limitX), capLimit(t.keyY, t.tMaxY, t.limitY), capLimit(t.keyZ, t.tMaxZ, t.limitZ)
+	return nearestAxis(x, y, z, t.xBeforeY, t.xBeforeZ, t.yBeforeZ)
 }
 
-// findDelta finds the change in t on an axis when taking a step on that axis (always positive).
-func findDelta(first, second float64) float64 {
-	if first == 0 {
+// capLimit returns the order key passed, or positive infinity if the distance of the crossing exceeds the limit.
+func capLimit(key, dist, limit float64) float64 {
+	if dist > limit {
+		return math.Inf(1)
+	}
+	return key
+}
+
+// nearestAxis returns the axis with the smallest of the order keys passed, using the tie breaks returned by tieBreaks
+// for equal keys.
+func nearestAxis(x, y, z float64, xBeforeY, xBeforeZ, yBeforeZ bool) int {
+	if (x < y || x == y && xBeforeY) && (x < z || x == z && xBeforeZ) {
 		return 0
 	}
-	return second / first
+	if y < z || y == z && yBeforeZ {
+		return 1
+	}
+	return 2
 }
 
-// rayTraceDistanceToBoundary returns the distance that must be travelled on an axis
-// from the start point with the direction vector component to cross a block boundary.
-func rayTraceDistanceToBoundary(first, second float64) float64 {
-	if second == 0 {
-		return math.Inf(0)
+// tieBreaks returns, for rays with the steps passed, which of two axes is stepped first when the ray crosses the
+// boundaries of both at exactly the same distance, such as at an edge or corner of a voxel. The order is chosen so
+// that the voxel stepped into is always the smallest of the candidates when comparing their X, then Y, then Z
+// coordinates. This makes the order independent of the direction of the ray: tracing the same segment in reverse
+// passes through the same voxels in reverse order. Axes with a negative step come first, in X, Y, Z order, followed
+// by the other axes in Z, Y, X order. For rays that step positively on every axis, this means Z goes before Y, which
+// goes before X. The axes are ordered as in the Y-up convention, using the axisOrder passed.
+func tieBreaks(stepX, stepY, stepZ int, order axisOrder) (xBeforeY, xBeforeZ, yBeforeZ bool) {
+	rank := func(axis, step int) int {
+		if step < 0 {
+			return order[axis]
+		}
+		return 5 - order[axis]
 	}
+	x, y, z := rank(0, stepX), rank(1, stepY), rank(2, stepZ)
+	return x < y, x < z, y < z
+}
 
-	if second < 0 {
-		first = -first
-		second = -second
+// aligned checks if the ray of the tracer is axis-aligned, and if so, returns the axis it travels along and the
+// amount of steps it takes along that axis. Axis-aligned rays only ever step along a single axis, so the voxels they
+// pass through may be produced by incrementing a single coordinate, without any of the comparisons done by next.
+// Rays with limits, a step hook or wrapping set are never treated as axis-aligned, as these must be handled for every
+// step.
+func (t *tracer) aligned() (axis, steps int, ok bool) {
+	if t.limited || t.hook != nil || t.wrapped {
+		return 0, 0, false
+	}
+	var tMax, limit float64
+	switch {
+	case t.stepY == 0 && t.stepZ == 0:
+		axis, tMax, limit = 0, t.tMaxX, t.limitX
+	case t.stepX == 0 && t.stepZ == 0:
+		axis, tMax, limit = 1, t.tMaxY, t.limitY
+	case t.stepX == 0 && t.stepY == 0:
+		axis, tMax, limit = 2, t.tMaxZ, t.limitZ
+	default:
+		return 0, 0, false
+	}
+	// The distances are computed the same way as done by next, so that the result is identical.
+	coord, step := t.pos()[axis], t.step(axis)
+	for tMax <= limit {
+		steps++
+		coord += step
+		tMax, _ = t.line.crossing(axis, coord, step)
+	}
+	return axis, steps, true
+}
+
+// step returns the step of the tracer on the axis passed, with 0 being the X axis, 1 the Y axis and 2 the Z axis.
+func (t *tracer) step(axis int) int {
+	switch axis {
+	case 0:
+		return t.stepX
+	case 1:
+		return t.stepY
+	}
+	return t.stepZ
+}
+
+// jump moves the tracer to the voxel that the ray is in at the distance passed along the ray, as if the voxels
+// before it had been stepped through. The state of the tracer is computed directly from the distance, so the cost
+// does not depend on the amount of voxels skipped.
+func (t *tracer) jump(dist float64) {
+	pos := voxelAlong(along(t.start, t.dir, dist), t.dir, t.ceil)
+	t.x, t.y, t.z = pos[0], pos[1], pos[2]
+	t.t, t.face = 0, FaceNone
+
+	t.cross()
+	// The voxel was entered where the ray crossed the last of the boundaries behind it.
+	entryX, entryY, entryZ := t.entry(0, t.x, t.stepX), t.entry(1, t.y, t.stepY), t.entry(2, t.z, t.stepZ)
+	if entryX > t.t {
+		t.t, t.face = entryX, t.faceX
+	}
+	if entryY > t.t {
+		t.t, t.face = entryY, t.faceY
+	}
+	if entryZ > t.t {
+		t.t, t.face = entryZ, t.faceZ
+	}
+}
 
-		if math.Floor(first) == first {
-			return 0
+// skipTo moves the tracer to the voxel containing the point at the distance passed along the ray, stepping through
+// the voxels before it. If the point lies on a boundary crossed by the ray, the voxel beyond it is moved to. False is
+// returned if the ray is not longer than the distance or is stopped before reaching it, in which case the tracer
+// should not be used further.
+func (t *tracer) skipTo(dist float64) bool {
+	if !(dist > 0) {
+		return true
+	}
+	if dist >= t.radius {
+		return false
+	}
+	for {
+		if tMax, _ := t.peek(); tMax > dist {
+			// The voxels skipped are not counted towards WithMaxVoxels.
+			t.visited = 1
+			return true
+		}
+		if !t.next() {
+			return false
 		}
 	}
+}
 
-	return (1 - (first - math.Floor(first))) / second
+// entry returns the distance along the ray at which it entered the voxel at the coordinate passed on an axis, when
+// travelling with the step passed on that axis.
+func (t *tracer) entry(axis, coord, step int) float64 {
+	if step == 0 {
+		return math.Inf(-1)
+	}
+	dist, _ := t.line.crossing(axis, coord-step, step)
+	return dist
+}
+
+// withinLimits checks if the tracer may step to the coordinate passed on an axis without exceeding any of the limits
+// of the trace. If not, the error of the tracer is set to the reason, if any, and false is returned.
+func (t *tracer) withinLimits(axis, coord int) bool {
+	if r := t.ranges[axis]; r.set {
+		// The ray only leaves a range for good if it moves past the end of the range it is travelling towards. If it
+		// is outside the range on the other side, it is still moving towards the range.
+		if step := t.step(axis); step > 0 && coord > r.max || step < 0 && coord < r.min {
+			return false
+		}
+	}
+	if t.maxManhattan >= 0 {
+		pos := t.rawPos()
+		pos[axis] = coord
+		if absInt(pos[0]-t.origin[0])+absInt(pos[1]-t.origin[1])+absInt(pos[2]-t.origin[2]) > t.maxManhattan {
+			return false
+		}
+	}
+	if t.maxVoxels > 0 && t.visited >= t.maxVoxels {
+		t.err = ErrMaxVoxelsExceeded
+		return false
+	}
+	if t.visited%limitCheckPeriod == 0 {
+		if t.budget > 0 && time.Since(t.began) > t.budget {
+			t.err = ErrTimeBudgetExceeded
+			return false
+		}
+		if t.ctx != nil {
+			if err := t.ctx.Err(); err != nil {
+				t.err = err
+				return false
+			}
+		}
+	}
+	t.visited++
+	return true
+}
+
+// ceilVoxel returns the coordinate of the voxel containing the coordinate passed, if boundaries belong to the voxel
+// below them.
+func ceilVoxel(f float64) float64 {
+	return math.Ceil(f) - 1
 }
 
 // compareTo compares the first and second float. It returns 0 if they are both the same,
@@ -104,8 +663,18 @@ func compareTo(first, second float64) float64 {
 	}
 }
 
+// finite checks if none of the coordinates of the vector passed are NaN or infinite.
+func finite(v mgl64.Vec3) bool {
+	for _, f := range v {
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return false
+		}
+	}
+	return true
+}
+
 // distance measures the distance between two vectors.
 func distance(a, b mgl64.Vec3) float64 {
 	xDiff, yDiff, zDiff := b[0]-a[0], b[1]-a[1], b[2]-a[2]
-	return math.Sqrt(xDiff*xDiff + yDiff*yDiff + zDiff*zDiff)
+	return math.Sqrt(sumSquares(xDiff, yDiff, zDiff))
 }
@@ -0,0 +1,34 @@
+package voxelraytrace
+
+import (
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// InDirection2D performs a ray trace on a 2D grid from the start position in the given direction, for a distance of
+// the maxDistance, like InDirection.
+func InDirection2D(start, directionVector mgl64.Vec2, maxDistance float64, opts ...Option) ([]mgl64.Vec2, error) {
+	return between2D(start.Vec3(0), along(start.Vec3(0), directionVector.Vec3(0), maxDistance), opts)
+}
+
+// BetweenPoints2D performs a ray trace on a 2D grid between the start and end coordinates, such as a tile map or a
+// heightmap viewed from the top, and returns the coordinates of the cells it passes through. The trace is the same as
+// that of BetweenPoints for a ray lying in a plane of constant Z, so the boundary ownership, tie breaks and Options
+// work the same way, with the X and Y axes of the grid being those of the 2D coordinates. Options for the Z axis, such
+// as WithZRange, have no effect.
+func BetweenPoints2D(start, end mgl64.Vec2, opts ...Option) ([]mgl64.Vec2, error) {
+	return between2D(start.Vec3(0), end.Vec3(0), opts)
+}
+
+// between2D performs a ray trace between the start and end coordinates, which must have a Z coordinate of 0, and
+// returns the X and Y coordinates of the voxels it passes through.
+func between2D(start, end mgl64.Vec3, opts []Option) ([]mgl64.Vec2, error) {
+	positions, err := BetweenPointsInt(start, end, opts...)
+	if positions == nil {
+		return nil, err
+	}
+	vectors := make([]mgl64.Vec2, len(positions))
+	for i, pos := range positions {
+		vectors[i] = mgl64.Vec2{float64(pos[0]), float64(pos[1])}
+	}
+	return vectors, err
+}
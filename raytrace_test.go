@@ -0,0 +1,290 @@
+package voxelraytrace
+
+import (
+	"fmt"
+	"github.com/go-gl/mathgl/mgl64"
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+// referenceBetweenPoints is the original implementation of BetweenPoints, which accumulates the distances to the voxel
+// boundaries in Vec3 arithmetic. It is used as the reference that the scalar traversal is checked against for rays
+// that do not pass close to voxel edges, where the rounding of the accumulated distances decides the voxel order.
+func referenceBetweenPoints(start, end mgl64.Vec3) []mgl64.Vec3 {
+	current := mgl64.Vec3{math.Floor(start[0]), math.Floor(start[1]), math.Floor(start[2])}
+	dir := end.Sub(start).Normalize()
+	radius := end.Sub(start).Len()
+
+	var step, tMax, tDelta mgl64.Vec3
+	for i := 0; i < 3; i++ {
+		tMax[i] = math.Inf(1)
+		switch {
+		case dir[i] > 0:
+			step[i], tDelta[i] = 1, 1/dir[i]
+			tMax[i] = (1 - (start[i] - math.Floor(start[i]))) / dir[i]
+		case dir[i] < 0:
+			step[i], tDelta[i] = -1, -1/dir[i]
+			if f := -start[i]; math.Floor(f) == f {
+				tMax[i] = 0
+			} else {
+				tMax[i] = (1 - (f - math.Floor(f))) / -dir[i]
+			}
+		}
+	}
+	var vectors []mgl64.Vec3
+	for {
+		vectors = append(vectors, current)
+		axis := 2
+		if tMax[0] < tMax[1] && tMax[0] < tMax[2] {
+			axis = 0
+		} else if tMax[1] < tMax[2] {
+			axis = 1
+		}
+		if tMax[axis] > radius {
+			return vectors
+		}
+		var delta mgl64.Vec3
+		delta[axis] = step[axis]
+		current = current.Add(delta)
+		tMax[axis] += tDelta[axis]
+	}
+}
+
+func TestBetweenPointsMatchesReference(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 20000; i++ {
+		var start, end mgl64.Vec3
+		for j := 0; j < 3; j++ {
+			start[j], end[j] = rng.Float64()*32-16, rng.Float64()*32-16
+		}
+		got, err := BetweenPoints(start, end)
+		if err != nil {
+			t.Fatalf("trace %v -> %v: unexpected error: %v", start, end, err)
+		}
+		if want := referenceBetweenPoints(start, end); !reflect.DeepEqual(got, want) {
+			t.Fatalf("trace %v -> %v: got %v, want %v", start, end, got, want)
+		}
+	}
+}
+
+// traversalLengths are the ray lengths, in blocks, that the traversal benchmarks are run with.
+var traversalLengths = []int{10, 100, 1000}
+
+// benchmarkTraversal benchmarks the traversal function passed on rays of each of the traversalLengths.
+func benchmarkTraversal(b *testing.B, trace func(start, end mgl64.Vec3)) {
+	for _, n := range traversalLengths {
+		b.Run(fmt.Sprint(n), func(b *testing.B) {
+			start := mgl64.Vec3{0.3, 0.6, 0.1}
+			end := start.Add(mgl64.Vec3{0.48, 0.64, -0.6}.Mul(float64(n)))
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				trace(start, end)
+			}
+		})
+	}
+}
+
+func BenchmarkTraversal(b *testing.B) {
+	benchmarkTraversal(b, func(start, end mgl64.Vec3) {
+		_, _ = BetweenPoints(start, end)
+	})
+}
+
+func BenchmarkTraversalReference(b *testing.B) {
+	benchmarkTraversal(b, func(start, end mgl64.Vec3) {
+		referenceBetweenPoints(start, end)
+	})
+}
+
+// alignedOptions are the sets of Options that the fast path for axis-aligned rays is checked with.
+var alignedOptions = [][]Option{
+	nil,
+	{WithCenteredVoxels()},
+	{WithCeilOwnership()},
+	{WithBoundaryTowardDirection()},
+	{WithCellSize(0.5, 0.25, 2)},
+	{WithSupercover()},
+	{WithUpAxis(AxisZ)},
+}
+
+// randomAlignedRay returns a ray travelling along a single random axis. The coordinates are often integers or halves,
+// so that the ray starts, ends or travels along voxel boundaries.
+func randomAlignedRay(rng *rand.Rand) (start, end mgl64.Vec3) {
+	coord := func() float64 {
+		switch rng.Intn(3) {
+		case 0:
+			return float64(rng.Intn(17) - 8)
+		case 1:
+			return float64(rng.Intn(33)-16) / 2
+		}
+		return rng.Float64()*16 - 8
+	}
+	for i := 0; i < 3; i++ {
+		start[i] = coord()
+	}
+	end = start
+	for end == start {
+		end[rng.Intn(3)] = coord()
+	}
+	return start, end
+}
+
+func TestBetweenPointsAligned(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for _, opts := range alignedOptions {
+		// A step hook disables the fast path, so that the general traversal is used as the reference.
+		general := append(opts[:len(opts):len(opts)], WithStepHook(func(StepInfo) {}))
+		for i := 0; i < 5000; i++ {
+			start, end := randomAlignedRay(rng)
+			want, wantErr := BetweenPointsInt(start, end, general...)
+			got, err := BetweenPointsInt(start, end, opts...)
+			if err != wantErr || !reflect.DeepEqual(got, want) {
+				t.Fatalf("trace %v -> %v: got %v, %v, want %v, %v", start, end, got, err, want, wantErr)
+			}
+			if n, _ := VoxelCount(start, end, opts...); n != len(want) {
+				t.Fatalf("trace %v -> %v: got count %v, want %v", start, end, n, len(want))
+			}
+		}
+	}
+}
+
+func BenchmarkAligned(b *testing.B) {
+	start, end := mgl64.Vec3{0.5, 64, 0.5}, mgl64.Vec3{0.5, -64, 0.5}
+	b.Run("fast", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_, _ = BetweenPointsInt(start, end)
+		}
+	})
+	b.Run("general", func(b *testing.B) {
+		// A step hook disables the fast path.
+		hook := WithStepHook(func(StepInfo) {})
+		for i := 0; i < b.N; i++ {
+			_, _ = BetweenPointsInt(start, end, hook)
+		}
+	})
+}
+
+func TestAxisRanges(t *testing.T) {
+	tests := []struct {
+		name       string
+		start, end mgl64.Vec3
+		opts       []Option
+		want       []BlockPos
+	}{
+		{
+			name:  "below the floor",
+			start: mgl64.Vec3{0.5, 2.5, 0.5}, end: mgl64.Vec3{0.5, -3.5, 0.5},
+			opts: []Option{WithYRange(0, 255)},
+			want: []BlockPos{{0, 2, 0}, {0, 1, 0}, {0, 0, 0}},
+		},
+		{
+			name:  "entering from below",
+			start: mgl64.Vec3{0.5, -2.5, 0.5}, end: mgl64.Vec3{0.5, 4.5, 0.5},
+			opts: []Option{WithYRange(0, 1)},
+			want: []BlockPos{{0, -3, 0}, {0, -2, 0}, {0, -1, 0}, {0, 0, 0}, {0, 1, 0}},
+		},
+		{
+			name:  "entering from above and leaving below",
+			start: mgl64.Vec3{0.5, 6.5, 0.5}, end: mgl64.Vec3{2.5, -3.5, 0.5},
+			opts: []Option{WithYRange(2, 4)},
+			want: []BlockPos{{0, 6, 0}, {0, 5, 0}, {0, 4, 0}, {0, 3, 0}, {1, 3, 0}, {1, 2, 0}},
+		},
+		{
+			name:  "starting past the range",
+			start: mgl64.Vec3{8.5, 0.5, 0.5}, end: mgl64.Vec3{12.5, 0.5, 0.5},
+			opts: []Option{WithXRange(0, 4)},
+			want: []BlockPos{{8, 0, 0}},
+		},
+		{
+			name:  "unrelated axis",
+			start: mgl64.Vec3{0.5, 0.5, 0.5}, end: mgl64.Vec3{3.5, 0.5, 0.5},
+			opts: []Option{WithZRange(0, 0)},
+			want: []BlockPos{{0, 0, 0}, {1, 0, 0}, {2, 0, 0}, {3, 0, 0}},
+		},
+		{
+			name:  "chunk column",
+			start: mgl64.Vec3{14.5, 70.5, 3.5}, end: mgl64.Vec3{18.5, 70.5, 20.5},
+			opts: []Option{WithXRange(0, 15), WithZRange(0, 15)},
+			want: []BlockPos{{14, 70, 3}, {14, 70, 4}, {14, 70, 5}, {15, 70, 5}, {15, 70, 6}, {15, 70, 7}, {15, 70, 8}, {15, 70, 9}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := BetweenPointsInt(test.start, test.end, test.opts...)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestAxisRangesMatchTruncatedTrace(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	min, max := BlockPos{-2, -1, -3}, BlockPos{2, 3, 1}
+	for i := 0; i < 20000; i++ {
+		start, end := randomPoint(rng, 8), randomPoint(rng, 8)
+		full, err := BetweenPointsInt(start, end)
+		if err != nil {
+			continue
+		}
+		// Only some of the axes are limited, and the trace stops before the first voxel past the end of a range that
+		// the ray travels towards.
+		var opts []Option
+		ranged := [3]bool{rng.Intn(2) == 0, rng.Intn(2) == 0, rng.Intn(2) == 0}
+		for axis, with := range []func(min, max int) Option{WithXRange, WithYRange, WithZRange} {
+			if ranged[axis] {
+				opts = append(opts, with(min[axis], max[axis]))
+			}
+		}
+		want := full[:1]
+	outer:
+		for j := 1; j < len(full); j++ {
+			for axis := 0; axis < 3; axis++ {
+				moved := full[j][axis] - full[j-1][axis]
+				if ranged[axis] && (moved > 0 && full[j][axis] > max[axis] || moved < 0 && full[j][axis] < min[axis]) {
+					break outer
+				}
+			}
+			want = full[:j+1]
+		}
+		got, err := BetweenPointsInt(start, end, opts...)
+		if err != nil || !reflect.DeepEqual(got, want) {
+			t.Fatalf("trace %v -> %v with ranges %v: got %v, %v, want %v", start, end, ranged, got, err, want)
+		}
+	}
+}
+
+func TestBetweenPointsSymmetric(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for _, opts := range [][]Option{nil, {WithCeilOwnership()}, {WithBoundaryTowardDirection()}, {WithUpAxis(AxisZ)}} {
+		for i := 0; i < 20000; i++ {
+			// Half of the rays have endpoints on a grid of half voxels, so that they often cross edges and corners
+			// of voxels exactly.
+			start, end := randomPoint(rng, 8), randomPoint(rng, 8)
+			if i%2 == 0 {
+				for j := 0; j < 3; j++ {
+					start[j], end[j] = float64(rng.Intn(17)-8)/2, float64(rng.Intn(17)-8)/2
+				}
+			}
+			if start == end {
+				continue
+			}
+			forward, err := BetweenPointsInt(start, end, opts...)
+			if err != nil {
+				t.Fatalf("trace %v -> %v: unexpected error: %v", start, end, err)
+			}
+			backward, _ := BetweenPointsInt(end, start, opts...)
+			for j, k := 0, len(backward)-1; j < k; j, k = j+1, k-1 {
+				backward[j], backward[k] = backward[k], backward[j]
+			}
+			if !reflect.DeepEqual(forward, backward) {
+				t.Fatalf("trace %v -> %v: got %v, reversed trace gives %v", start, end, forward, backward)
+			}
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package voxelraytrace
+
+import (
+	"errors"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// reachInset is the distance by which the points sampled by ReachableFaces are moved inwards from the edges of a
+// face and into the voxel, so that rays towards them do not end exactly on a voxel boundary.
+const reachInset = 1e-3
+
+// ReachableFaces returns the faces of the target voxel that may be reached from the eye position within the reach
+// distance passed, such as the faces of a block that a player could click. A face is reachable if a ray from the eye
+// towards one of the points sampled on it, being its centre and its four corners inset slightly, reaches the target
+// without passing through a voxel that is solid in the Grid first, and that point lies within reach. Faces pointing
+// away from the eye are never reachable and are excluded before tracing. The faces are returned in the order of
+// their Face values.
+func ReachableFaces(g Grid, eye mgl64.Vec3, target BlockPos, reach float64) ([]Face, error) {
+	if !(reach > 0) {
+		return nil, errors.New("reach must be positive")
+	}
+	var faces []Face
+	for f := FaceDown; f <= FaceEast; f++ {
+		normal, centre := FaceNormal(f), VoxelFaceCenter(target.Vec3Min(), f)
+		if eye.Sub(centre).Dot(normal) <= 0 {
+			continue
+		}
+		reachable, err := faceReachable(g, eye, target, f, centre, reach)
+		if err != nil {
+			return nil, err
+		}
+		if reachable {
+			faces = append(faces, f)
+		}
+	}
+	return faces, nil
+}
+
+// faceReachable checks if any of the points sampled on the face of the target voxel with the centre passed is
+// reachable from the eye position.
+func faceReachable(g Grid, eye mgl64.Vec3, target BlockPos, f Face, centre mgl64.Vec3, reach float64) (bool, error) {
+	normal := FaceNormal(f)
+	// u and v are unit vectors along the two axes spanning the face.
+	axes := [...]Axis{AxisX, AxisY, AxisZ, AxisX, AxisY}
+	i := int(FaceAxis(f) - AxisX)
+	u, v := axes[i+1].UnitVector(), axes[i+2].UnitVector()
+	corner := 0.5 - reachInset
+
+	inward := centre.Sub(normal.Mul(reachInset))
+	for _, offset := range [5][2]float64{{0, 0}, {-1, -1}, {-1, 1}, {1, -1}, {1, 1}} {
+		p := inward.Add(u.Mul(offset[0] * corner)).Add(v.Mul(offset[1] * corner))
+		if distance(eye, p) > reach {
+			continue
+		}
+		hit, ok, err := FirstSolidHit(g, eye, p)
+		if err != nil {
+			return false, err
+		}
+		if !ok || hit.Pos == target {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,48 @@
+package voxelraytrace
+
+import (
+	"github.com/go-gl/mathgl/mgl64"
+	"reflect"
+	"testing"
+)
+
+func TestReachableFaces(t *testing.T) {
+	// The target rests on a floor at Y 63 and is flush against a wall at X 1 on its east side.
+	g := NewSparseGrid()
+	for x := -5; x <= 5; x++ {
+		for z := -5; z <= 5; z++ {
+			g.Set(x, 63, z, true)
+		}
+	}
+	for y := 64; y <= 70; y++ {
+		for z := -5; z <= 5; z++ {
+			g.Set(1, y, z, true)
+		}
+	}
+	target := BlockPos{0, 64, 0}
+	g.Set(target[0], target[1], target[2], true)
+
+	tests := []struct {
+		name  string
+		eye   mgl64.Vec3
+		reach float64
+		want  []Face
+	}{
+		{name: "above and in front", eye: mgl64.Vec3{-2.5, 66.5, 3.5}, reach: 6, want: []Face{FaceUp, FaceSouth, FaceWest}},
+		{name: "level in front", eye: mgl64.Vec3{-2.5, 64.5, 0.5}, reach: 6, want: []Face{FaceWest}},
+		{name: "behind wall", eye: mgl64.Vec3{3.5, 64.5, 0.5}, reach: 6},
+		{name: "below floor", eye: mgl64.Vec3{0.5, 60.5, 0.5}, reach: 6},
+		{name: "out of reach", eye: mgl64.Vec3{-2.5, 66.5, 3.5}, reach: 2},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			faces, err := ReachableFaces(g, test.eye, target, test.reach)
+			if err != nil || !reflect.DeepEqual(faces, test.want) {
+				t.Errorf("got %v, %v, want %v", faces, err, test.want)
+			}
+		})
+	}
+	if _, err := ReachableFaces(g, mgl64.Vec3{-2.5, 64.5, 0.5}, target, 0); err == nil {
+		t.Error("expected an error for a reach of 0")
+	}
+}
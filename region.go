@@ -0,0 +1,105 @@
+package voxelraytrace
+
+import (
+	"github.com/go-gl/mathgl/mgl64"
+	"math"
+)
+
+// RegionEntryExit returns the first and last voxels passed through by a ray trace between the start and end
+// coordinates that lie inside the region of voxels spanning from min to max, both inclusive. Rather than visiting
+// every voxel, the ray is clipped against the region and skips to just before it leaves it, so the cost does not
+// depend on the length of the ray. If the ray starts inside the region, the voxel containing the start is the entry.
+// If the ray does not pass through the region, or if the start and end are the same, false is returned.
+// The result matches the first and last voxels inside the region returned by BetweenPoints, including where the ray
+// enters or leaves the region through a voxel edge or corner.
+func RegionEntryExit(start, end mgl64.Vec3, min, max BlockPos) (entry, exit BlockPos, ok bool) {
+	t, err := newTracer(start, end, newConfig(nil))
+	if err != nil || !t.clip(min, max) {
+		return
+	}
+	entry = t.pos()
+	t.skipEmpty(min, max)
+	for t.next() {
+	}
+	return entry, t.pos(), true
+}
+
+// RegionExit holds where a ray left a region of voxels, as returned by ExitRegion.
+type RegionExit struct {
+	// Pos is the position of the last voxel inside the region that the ray passed through.
+	Pos BlockPos
+	// Face is the face of Pos through which the ray left the region, so that Pos.Side(Face) is the first voxel outside
+	// of it.
+	Face Face
+	// Point is the exact point at which the ray left the region.
+	Point mgl64.Vec3
+	// Distance is the distance from the start of the ray to Point.
+	Distance float64
+}
+
+// ExitRegion performs a ray trace between the start and end coordinates through the region of voxels spanning from
+// min to max, both inclusive, and returns where and through which face the ray left it, such as for portals, beams
+// rendered up to the sky or handing a ray over to the server owning the next chunk. A ray that starts outside the
+// region is moved forward to the point at which it enters it, as done by WithBounds, which the region replaces.
+// Rather than stepping through every voxel, the ray skips to just before it leaves the region. If the ray ends inside
+// the region or does not pass through it, false is returned, along with the error that stopped the trace early, if
+// any. FirstSolidHit with WithBounds may be used to check if the ray hits anything before it leaves the region.
+func ExitRegion(start, end mgl64.Vec3, min, max BlockPos, opts ...Option) (RegionExit, bool, error) {
+	conf := newConfig(opts)
+	conf.bounds, conf.bounded = AABBInt{Min: min, Max: max}, true
+	t, err := newTracer(start, end, conf)
+	if err != nil {
+		return RegionExit{}, false, err
+	}
+	if !t.enter(conf) {
+		return RegionExit{}, false, t.err
+	}
+	t.skipEmpty(min, max)
+	for t.next() {
+	}
+	exit, ok := t.exit()
+	return exit, ok, t.err
+}
+
+// exit returns where the ray of the tracer leaves the ranges it is limited to, once next has returned false. False is
+// returned if the ray ends before leaving them or the trace was stopped for another reason.
+func (t *tracer) exit() (RegionExit, bool) {
+	if t.err != nil {
+		return RegionExit{}, false
+	}
+	axis := t.nextAxis()
+	tMax, step := t.peek()
+	if tMax > [3]float64{t.limitX, t.limitY, t.limitZ}[axis] {
+		return RegionExit{}, false
+	}
+	// The ray may also have been stopped by WithMaxManhattan, in which case it did not leave the ranges.
+	r, coord := t.ranges[axis], t.rawPos()[axis]+step
+	if !r.set || step > 0 && coord <= r.max || step < 0 && coord >= r.min {
+		return RegionExit{}, false
+	}
+	face := [3]Face{t.faceX, t.faceY, t.faceZ}[axis].Opposite()
+	return RegionExit{Pos: t.pos(), Face: face, Point: t.point(tMax), Distance: tMax}, true
+}
+
+// voxelAlong returns the voxel that a ray travelling in the direction passed is in when it reaches the point p. If
+// the point lies exactly on a voxel boundary on an axis the ray travels along, the ray is in the voxel it crosses
+// into at that point. On other axes, the boundary belongs to the voxel above it, or the voxel below it if ceil is
+// true.
+func voxelAlong(p, dir mgl64.Vec3, ceil bool) BlockPos {
+	var pos BlockPos
+	for i := 0; i < 3; i++ {
+		if dir[i] < 0 || dir[i] == 0 && ceil {
+			pos[i] = int(math.Ceil(p[i])) - 1
+		} else {
+			pos[i] = int(math.Floor(p[i]))
+		}
+	}
+	return pos
+}
+
+// insideRegion checks if the position passed lies inside the region spanning from min to max, both inclusive.
+func insideRegion(pos, min, max BlockPos) bool {
+	return pos[0] >= min[0] && pos[0] <= max[0] &&
+		pos[1] >= min[1] && pos[1] <= max[1] &&
+		pos[2] >= min[2] && pos[2] <= max[2]
+}
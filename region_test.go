@@ -0,0 +1,151 @@
+package voxelraytrace
+
+import (
+	"github.com/go-gl/mathgl/mgl64"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestRegionEntryExit(t *testing.T) {
+	min, max := BlockPos{0, 0, 0}, BlockPos{3, 3, 3}
+	tests := []struct {
+		name        string
+		start, end  mgl64.Vec3
+		entry, exit BlockPos
+		ok          bool
+	}{
+		{name: "through", start: mgl64.Vec3{-2.5, 1.5, 1.5}, end: mgl64.Vec3{6.5, 1.5, 1.5}, entry: BlockPos{0, 1, 1}, exit: BlockPos{3, 1, 1}, ok: true},
+		{name: "start inside", start: mgl64.Vec3{2.5, 1.5, 1.5}, end: mgl64.Vec3{2.5, 9.5, 1.5}, entry: BlockPos{2, 1, 1}, exit: BlockPos{2, 3, 1}, ok: true},
+		{name: "end inside", start: mgl64.Vec3{-2.5, -2.5, 1.5}, end: mgl64.Vec3{1.25, 1.75, 1.5}, entry: BlockPos{0, 0, 1}, exit: BlockPos{1, 1, 1}, ok: true},
+		{name: "miss", start: mgl64.Vec3{-2.5, 4.5, 1.5}, end: mgl64.Vec3{6.5, 4.5, 1.5}},
+		{name: "before region", start: mgl64.Vec3{-6.5, 1.5, 1.5}, end: mgl64.Vec3{-2.5, 1.5, 1.5}},
+		{name: "leaving from surface", start: mgl64.Vec3{1.5, 4, 1.5}, end: mgl64.Vec3{2.5, 6, 1.5}},
+		{name: "ending on surface", start: mgl64.Vec3{-2.5, 1.5, 1.5}, end: mgl64.Vec3{0, 1.5, 1.5}, entry: BlockPos{0, 1, 1}, exit: BlockPos{0, 1, 1}, ok: true},
+		{name: "zero length", start: mgl64.Vec3{1.5, 1.5, 1.5}, end: mgl64.Vec3{1.5, 1.5, 1.5}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			entry, exit, ok := RegionEntryExit(test.start, test.end, min, max)
+			if entry != test.entry || exit != test.exit || ok != test.ok {
+				t.Errorf("got %v, %v, %v, want %v, %v, %v", entry, exit, ok, test.entry, test.exit, test.ok)
+			}
+		})
+	}
+}
+
+func TestRegionEntryExitMatchesFilteredTrace(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	min, max := BlockPos{-2, -1, -3}, BlockPos{2, 3, 1}
+	for i := 0; i < 20000; i++ {
+		start, end := randomPoint(rng, 8), randomPoint(rng, 8)
+		if start == end {
+			continue
+		}
+		positions, err := BetweenPointsInt(start, end)
+		if err != nil {
+			t.Fatalf("trace %v -> %v: unexpected error: %v", start, end, err)
+		}
+		inside := filterRegion(positions, min, max)
+		entry, exit, ok := RegionEntryExit(start, end, min, max)
+		if ok != (len(inside) > 0) || ok && (entry != inside[0] || exit != inside[len(inside)-1]) {
+			t.Fatalf("trace %v -> %v: got %v, %v, %v, want the ends of %v", start, end, entry, exit, ok, inside)
+		}
+	}
+}
+
+func TestExitRegionMatchesTrace(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	min, max := BlockPos{-2, -1, -3}, BlockPos{2, 3, 1}
+	for _, opts := range [][]Option{nil, {WithCeilOwnership()}, {WithBoundaryTowardDirection()}} {
+		for i := 0; i < 20000; i++ {
+			start, end := randomPoint(rng, 8), randomPoint(rng, 8)
+			if start == end {
+				continue
+			}
+			all, err := BetweenPointsInt(start, end, opts...)
+			if err != nil {
+				t.Fatalf("trace %v -> %v: unexpected error: %v", start, end, err)
+			}
+			// The ray leaves the region if it passes through it and the last voxel of the trace lies outside it. The
+			// voxels inside the region are always passed through in one go, as the region is convex.
+			inside := filterRegion(all, min, max)
+			wantOK := len(inside) > 0 && !insideRegion(all[len(all)-1], min, max)
+			exit, ok, err := ExitRegion(start, end, min, max, opts...)
+			if err != nil || ok != wantOK {
+				t.Fatalf("trace %v -> %v: got %v, %v, %v, want %v", start, end, exit, ok, err, wantOK)
+			}
+			if !ok {
+				continue
+			}
+			last := inside[len(inside)-1]
+			var next BlockPos
+			for j, pos := range all {
+				if pos == last {
+					next = all[j+1]
+					break
+				}
+			}
+			if exit.Pos != last || exit.Pos.Side(exit.Face) != next {
+				t.Fatalf("trace %v -> %v: left %v through %v, want %v into %v", start, end, exit.Pos, exit.Face, last, next)
+			}
+			// The exit point lies on the plane of the face left through, at the distance reported along the ray.
+			a, positive := exit.Face.axis()
+			axis := int(a - AxisX)
+			plane := float64(exit.Pos[axis])
+			if positive {
+				plane++
+			}
+			dir := end.Sub(start)
+			want := start.Add(dir.Mul(exit.Distance / dir.Len()))
+			if math.Abs(exit.Point[axis]-plane) > 1e-9 || !vec3Near(exit.Point, want) || exit.Distance > dir.Len()+1e-9 {
+				t.Fatalf("trace %v -> %v: left at %v, %v along the ray, want a point on the plane %v = %v", start, end, exit.Point, exit.Distance, axis, plane)
+			}
+		}
+	}
+}
+
+func TestExitRegion(t *testing.T) {
+	min, max := BlockPos{0, 0, 0}, BlockPos{15, 255, 15}
+	tests := []struct {
+		name       string
+		start, end mgl64.Vec3
+		want       RegionExit
+		ok         bool
+	}{
+		{
+			name:  "sky",
+			start: mgl64.Vec3{4.5, 64.5, 4.5},
+			end:   mgl64.Vec3{4.5, 400.5, 4.5},
+			want:  RegionExit{Pos: BlockPos{4, 255, 4}, Face: FaceUp, Point: mgl64.Vec3{4.5, 256, 4.5}, Distance: 191.5},
+			ok:    true,
+		},
+		{
+			name:  "neighbouring chunk",
+			start: mgl64.Vec3{12.5, 64.5, 4.5},
+			end:   mgl64.Vec3{20.5, 64.5, 8.5},
+			want:  RegionExit{Pos: BlockPos{15, 64, 6}, Face: FaceEast, Point: mgl64.Vec3{16, 64.5, 6.25}, Distance: 3.5 * math.Sqrt(1.25)},
+			ok:    true,
+		},
+		{
+			name:  "through from outside",
+			start: mgl64.Vec3{-4.5, 64.5, 4.5},
+			end:   mgl64.Vec3{20.5, 64.5, 4.5},
+			want:  RegionExit{Pos: BlockPos{15, 64, 4}, Face: FaceEast, Point: mgl64.Vec3{16, 64.5, 4.5}, Distance: 20.5},
+			ok:    true,
+		},
+		{name: "ending inside", start: mgl64.Vec3{4.5, 64.5, 4.5}, end: mgl64.Vec3{10.5, 70.5, 4.5}},
+		{name: "missing", start: mgl64.Vec3{-4.5, 64.5, 4.5}, end: mgl64.Vec3{-4.5, 64.5, 20.5}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			exit, ok, err := ExitRegion(test.start, test.end, min, max)
+			if err != nil || ok != test.ok || exit.Pos != test.want.Pos || exit.Face != test.want.Face || ok && (!vec3Near(exit.Point, test.want.Point) || math.Abs(exit.Distance-test.want.Distance) > 1e-9) {
+				t.Errorf("got %v, %v, %v, want %v, %v", exit, ok, err, test.want, test.ok)
+			}
+		})
+	}
+	if _, _, err := ExitRegion(mgl64.Vec3{1, 2, 3}, mgl64.Vec3{1, 2, 3}, min, max); err != ErrZeroDirection {
+		t.Errorf("got %v for a zero length ray, want %v", err, ErrZeroDirection)
+	}
+}
@@ -0,0 +1,55 @@
+package voxelraytrace
+
+// regionSize is the size of the cubic, aligned regions queried from a RegionGrid at once.
+const regionSize = 16
+
+// RegionGrid is a Grid that can report the solidity of a whole box of voxels at once. Grids for which Solid is
+// expensive, such as those that look up a chunk for every voxel, may implement it to reduce the cost of a trace:
+// FirstSolidHit, SolidHits and the functions built on them query a RegionGrid one 16x16x16 region, aligned to
+// multiples of 16, at a time, rather than calling Solid for every voxel. The results are the same as if Solid had
+// been called for every voxel.
+type RegionGrid interface {
+	Grid
+	// SolidRegion reports the solidity of every voxel in the box spanning min to max, both inclusive, in out. out
+	// has a length of (max[0]-min[0]+1) * (max[1]-min[1]+1) * (max[2]-min[2]+1), and the solidity of the voxel at
+	// (x, y, z) must be written to the index ((y-min[1])*depth + (z-min[2]))*width + (x-min[0]).
+	SolidRegion(min, max BlockPos, out []bool)
+}
+
+// regionCache is a Grid that answers Solid using the last region queried from a RegionGrid, querying a new region
+// whenever a voxel outside of it is requested.
+type regionCache struct {
+	g      RegionGrid
+	box    gridBox
+	data   []bool
+	loaded bool
+}
+
+// cachedGrid returns a Grid that queries g a region at a time if it implements RegionGrid, or g itself otherwise.
+func cachedGrid(g Grid) Grid {
+	if rg, ok := g.(RegionGrid); ok {
+		return &regionCache{g: rg}
+	}
+	return g
+}
+
+// Solid reports if the voxel at the coordinates passed is solid, querying its region first if it is not cached.
+func (c *regionCache) Solid(x, y, z int) bool {
+	if !c.loaded || !c.box.inside(x, y, z) {
+		c.load(x, y, z)
+	}
+	return c.data[c.box.index(x, y, z)]
+}
+
+// load queries the region containing the voxel at the coordinates passed from the RegionGrid.
+func (c *regionCache) load(x, y, z int) {
+	// Clearing the low bits rounds towards negative infinity, also for negative coordinates.
+	origin := BlockPos{x &^ (regionSize - 1), y &^ (regionSize - 1), z &^ (regionSize - 1)}
+	c.box = gridBox{origin: origin, w: regionSize, h: regionSize, d: regionSize}
+	if c.data == nil {
+		c.data = make([]bool, regionSize*regionSize*regionSize)
+	}
+	min, max := c.box.Bounds()
+	c.g.SolidRegion(min, max, c.data)
+	c.loaded = true
+}
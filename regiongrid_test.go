@@ -0,0 +1,103 @@
+package voxelraytrace
+
+import (
+	"github.com/go-gl/mathgl/mgl64"
+	"math/rand"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+// chunkGrid is a Grid of which every lookup of a voxel first looks up the chunk holding it, like a world split up in
+// chunks, under a lock like a world that may be accessed concurrently. It counts the amount of chunk lookups done.
+type chunkGrid struct {
+	mu      sync.Mutex
+	chunks  map[BlockPos]*ArrayGrid
+	lookups int
+}
+
+// newChunkGrid returns a chunkGrid holding the solid voxels of the SparseGrid passed.
+func newChunkGrid(g *SparseGrid) *chunkGrid {
+	c := &chunkGrid{chunks: make(map[BlockPos]*ArrayGrid)}
+	for pos := range g.solid {
+		c.chunk(pos[0], pos[1], pos[2]).Set(pos[0], pos[1], pos[2], true)
+	}
+	c.lookups = 0
+	return c
+}
+
+// chunk returns the chunk holding the voxel at the coordinates passed, creating it if it does not yet exist.
+func (c *chunkGrid) chunk(x, y, z int) *ArrayGrid {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.lookups++
+	origin := BlockPos{x &^ 15, y &^ 15, z &^ 15}
+	ch, ok := c.chunks[origin]
+	if !ok {
+		ch = NewArrayGrid(origin, 16, 16, 16)
+		c.chunks[origin] = ch
+	}
+	return ch
+}
+
+// Solid looks up the chunk holding the voxel at the coordinates passed and checks if the voxel is solid.
+func (c *chunkGrid) Solid(x, y, z int) bool {
+	return c.chunk(x, y, z).Solid(x, y, z)
+}
+
+// regionChunkGrid is a chunkGrid that implements RegionGrid, looking up a chunk once for every region queried.
+type regionChunkGrid struct {
+	*chunkGrid
+}
+
+// SolidRegion looks up the chunk holding the region passed once and copies the solidity of its voxels. The regions
+// queried are aligned to chunks, so that the layout of out matches that of the chunk.
+func (c regionChunkGrid) SolidRegion(min, _ BlockPos, out []bool) {
+	copy(out, c.chunk(min[0], min[1], min[2]).data)
+}
+
+func TestRegionGridMatchesPerVoxel(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	sparse := randomGrid(rng, 48, 400)
+	g := regionChunkGrid{newChunkGrid(sparse)}
+	for i := 0; i < 2000; i++ {
+		start := mgl64.Vec3{rng.Float64()*64 - 8, rng.Float64()*64 - 8, rng.Float64()*64 - 8}
+		end := mgl64.Vec3{rng.Float64()*64 - 8, rng.Float64()*64 - 8, rng.Float64()*64 - 8}
+		want, wantOK, wantErr := FirstSolidHit(sparse, start, end)
+		got, ok, err := FirstSolidHit(g, start, end)
+		if got != want || ok != wantOK || err != wantErr {
+			t.Fatalf("trace %v -> %v: got %v, %v, %v, want %v, %v, %v", start, end, got, ok, err, want, wantOK, wantErr)
+		}
+		wantHits, _ := SolidHits(sparse, start, end)
+		if hits, _ := SolidHits(g, start, end); !reflect.DeepEqual(hits, wantHits) {
+			t.Fatalf("trace %v -> %v: got hits %v, want %v", start, end, hits, wantHits)
+		}
+	}
+}
+
+func TestRegionGridQueriesRegions(t *testing.T) {
+	// A ray along a row of 64 empty voxels passes through 4 regions, each of which is queried once.
+	g := regionChunkGrid{newChunkGrid(NewSparseGrid())}
+	if _, ok, _ := FirstSolidHit(g, mgl64.Vec3{0.5, 0.5, 0.5}, mgl64.Vec3{63.5, 0.5, 0.5}); ok {
+		t.Fatal("unexpected hit in an empty grid")
+	}
+	if g.lookups != 4 {
+		t.Errorf("got %v chunk lookups, want 4", g.lookups)
+	}
+}
+
+func BenchmarkRegionGrid(b *testing.B) {
+	start, end := mgl64.Vec3{0.2, 0.3, 0.1}, mgl64.Vec3{63.9, 47.8, 55.7}
+	b.Run("per voxel", func(b *testing.B) {
+		g := newChunkGrid(NewSparseGrid())
+		for i := 0; i < b.N; i++ {
+			_, _, _ = FirstSolidHit(g, start, end)
+		}
+	})
+	b.Run("region", func(b *testing.B) {
+		g := regionChunkGrid{newChunkGrid(NewSparseGrid())}
+		for i := 0; i < b.N; i++ {
+			_, _, _ = FirstSolidHit(g, start, end)
+		}
+	})
+}
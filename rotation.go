@@ -0,0 +1,22 @@
+package voxelraytrace
+
+import (
+	"github.com/go-gl/mathgl/mgl64"
+	"math"
+)
+
+// DirectionFromRotation returns the normalised direction vector in which an entity with the yaw and pitch in degrees
+// passed is looking. The Minecraft convention is followed, as used by both Java and Bedrock Edition: a yaw of 0 faces
+// positive Z, a yaw of 90 faces negative X, a pitch of -90 faces straight up and a pitch of 90 straight down.
+func DirectionFromRotation(yaw, pitch float64) mgl64.Vec3 {
+	yawRad, pitchRad := mgl64.DegToRad(yaw), mgl64.DegToRad(pitch)
+	cosPitch := math.Cos(pitchRad)
+	return mgl64.Vec3{-math.Sin(yawRad) * cosPitch, -math.Sin(pitchRad), math.Cos(yawRad) * cosPitch}
+}
+
+// FromRotation performs a ray trace from the eye position passed in the direction of the yaw and pitch in degrees
+// passed, as returned by DirectionFromRotation, for a distance of the maxDistance, such as to find the blocks an
+// entity is looking at. It returns the coordinates of the voxels passed through like InDirection.
+func FromRotation(eyePos mgl64.Vec3, yaw, pitch, maxDistance float64, opts ...Option) ([]mgl64.Vec3, error) {
+	return InDirection(eyePos, DirectionFromRotation(yaw, pitch), maxDistance, opts...)
+}
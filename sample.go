@@ -0,0 +1,77 @@
+package voxelraytrace
+
+import (
+	"errors"
+	"github.com/go-gl/mathgl/mgl64"
+	"math"
+)
+
+// boundaryTolerance is the distance along a ray within which a point is considered to be close enough to a voxel
+// boundary for rounding in computing the point to place it on either side of the boundary.
+const boundaryTolerance = 1e-9
+
+// Sample is a point sampled along a ray by SamplePoints.
+type Sample struct {
+	// Point is the world space position of the sample.
+	Point mgl64.Vec3
+	// Distance is the distance from the start of the ray to the sample.
+	Distance float64
+	// Voxel is the position of the voxel containing the sample.
+	Voxel BlockPos
+}
+
+// SamplePoints returns points spaced evenly along the ray between the start and end coordinates, starting at the start
+// coordinates, along with the voxel each point lies in. The end coordinates are always included as the last sample,
+// even if they are closer than the spacing to the sample before them. The voxels are found by traversing the ray
+// once rather than flooring every point, and follow the boundary ownership of the Options passed, like VoxelAt.
+// An error is returned if the spacing is not positive.
+func SamplePoints(start, end mgl64.Vec3, spacing float64, opts ...Option) ([]Sample, error) {
+	if !(spacing > 0) {
+		return nil, errors.New("sample spacing must be positive")
+	}
+	t, err := newTracer(start, end, newConfig(opts))
+	if err != nil {
+		return nil, err
+	}
+	samples := make([]Sample, 0, int(t.radius/spacing)+2)
+	for i := 0; ; i++ {
+		dist := float64(i) * spacing
+		if dist >= t.radius {
+			break
+		}
+		t.advance(dist)
+		point, voxel := t.point(dist), t.pos()
+		if next, _ := t.peek(); math.Abs(next-dist) <= boundaryTolerance || math.Abs(t.t-dist) <= boundaryTolerance {
+			// The point lies on or very close to a boundary, where rounding in computing it may place it in the
+			// voxel on the other side, so the voxel is computed from the point to agree with it.
+			voxel = VoxelAt(point, opts...)
+		}
+		samples = append(samples, Sample{Point: point, Distance: dist, Voxel: voxel})
+	}
+	// The end coordinates are often exactly on a boundary, beyond which the traversal does not step, so the voxel
+	// containing them is computed directly.
+	samples = append(samples, Sample{Point: end, Distance: t.radius, Voxel: VoxelAt(end, opts...)})
+	return samples, t.err
+}
+
+// advance steps the tracer until it is in the voxel containing the point at the distance passed along the ray. The
+// tracer is never moved beyond the end of the ray.
+func (t *tracer) advance(dist float64) {
+	for {
+		tMax, step := t.peek()
+		if tMax > endLimit(dist, step, t.ceil, t.toward) || !t.next() {
+			return
+		}
+	}
+}
+
+// peek returns the distance along the ray at which the tracer will step next and the step it will take on that axis.
+func (t *tracer) peek() (tMax float64, step int) {
+	switch t.nextAxis() {
+	case 0:
+		return t.tMaxX, t.stepX
+	case 1:
+		return t.tMaxY, t.stepY
+	}
+	return t.tMaxZ, t.stepZ
+}
@@ -0,0 +1,68 @@
+package voxelraytrace
+
+import (
+	"github.com/go-gl/mathgl/mgl64"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestSamplePointsMatchesVoxelAt(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for _, opts := range [][]Option{nil, {WithCenteredVoxels()}, {WithCellSize(0.5, 0.25, 1)}} {
+		for i := 0; i < 5000; i++ {
+			var start, end mgl64.Vec3
+			if i%2 == 0 {
+				start, end = randomPoint(rng, 8), randomPoint(rng, 8)
+			} else {
+				// Points on a coarse grid make many of the samples lie exactly on voxel boundaries.
+				for j := 0; j < 3; j++ {
+					start[j], end[j] = float64(rng.Intn(33)-16)/4, float64(rng.Intn(33)-16)/4
+				}
+			}
+			if start == end {
+				continue
+			}
+			samples, err := SamplePoints(start, end, 0.25, opts...)
+			if err != nil {
+				t.Fatalf("trace %v -> %v: unexpected error: %v", start, end, err)
+			}
+			for _, s := range samples {
+				if want := VoxelAt(s.Point, opts...); s.Voxel != want {
+					t.Fatalf("trace %v -> %v: sample %v at distance %v in %v, want %v", start, end, s.Point, s.Distance, s.Voxel, want)
+				}
+			}
+		}
+	}
+}
+
+func TestSamplePoints(t *testing.T) {
+	start, end := mgl64.Vec3{0.5, 0.5, 0.5}, mgl64.Vec3{0.5, 0.5, 1.6}
+	samples, err := SamplePoints(start, end, 0.25)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(samples) != 6 {
+		t.Fatalf("got %v samples, want 6", len(samples))
+	}
+	for i, s := range samples[:5] {
+		want := Sample{Point: mgl64.Vec3{0.5, 0.5, 0.5 + float64(i)*0.25}, Distance: float64(i) * 0.25, Voxel: BlockPos{0, 0, 0}}
+		if i >= 2 {
+			want.Voxel = BlockPos{0, 0, 1}
+		}
+		if s != want {
+			t.Errorf("sample %v: got %v, want %v", i, s, want)
+		}
+	}
+	if last := samples[5]; last.Point != end || math.Abs(last.Distance-1.1) > 1e-9 || last.Voxel != (BlockPos{0, 0, 1}) {
+		t.Errorf("got last sample %v, want the end point", last)
+	}
+}
+
+func TestSamplePointsInvalidSpacing(t *testing.T) {
+	for _, spacing := range []float64{0, -1, math.NaN()} {
+		if _, err := SamplePoints(mgl64.Vec3{}, mgl64.Vec3{1, 1, 1}, spacing); err == nil {
+			t.Errorf("expected an error for spacing %v", spacing)
+		}
+	}
+}
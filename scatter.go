@@ -0,0 +1,82 @@
+package voxelraytrace
+
+import (
+	"github.com/go-gl/mathgl/mgl64"
+	"math"
+)
+
+// ScatterTrace fires count rays, or pellets, from the origin in random directions within a cone around dir, such as
+// for a shotgun, and returns the first solid voxel in the Grid passed hit by every pellet within the maxDistance. The
+// cone has an opening of halfAngle degrees between dir and its edge, and the directions are spread uniformly over the
+// solid angle of the cone. A pellet that hits nothing, or starts outside of the bounds of a BoundedGrid, is reported
+// as a HitResult with an infinite Distance.
+// The directions are generated by a random number generator owned by the package, so the same seed always produces
+// the same pellets, for example on both a server and a client. Apart from the cosine of halfAngle, no trigonometry is
+// involved, so the pellets are identical on all platforms in the same way as described for BetweenPoints. nil is
+// returned if count is 0 or less or if dir is zero.
+func ScatterTrace(g Grid, origin, dir mgl64.Vec3, halfAngle float64, count int, seed uint64, maxDistance float64) []HitResult {
+	if count <= 0 || !(sumSquares(dir[0], dir[1], dir[2]) > 0) {
+		return nil
+	}
+	// w is the axis of the cone, and u and v span the plane perpendicular to it.
+	w := yUp.normalize(dir)
+	helper := mgl64.Vec3{1, 0, 0}
+	if math.Abs(w[0]) > 0.9 {
+		helper = mgl64.Vec3{0, 1, 0}
+	}
+	u := yUp.normalize(cross(helper, w))
+	v := cross(w, u)
+	minCos := math.Cos(mgl64.DegToRad(math.Max(0, math.Min(halfAngle, 180))))
+
+	hits := make([]HitResult, count)
+	cached, conf, rng := cachedGrid(g), newConfig(nil), splitMix64(seed)
+	for i := range hits {
+		hits[i].Distance = math.Inf(1)
+
+		// Picking the cosine of the angle with the axis uniformly spreads directions uniformly over the solid angle.
+		cosTheta := minCos + float64((1-minCos)*rng.float64())
+		sinTheta := math.Sqrt(math.Max(0, 1-float64(cosTheta*cosTheta)))
+		// A uniformly distributed point on the unit circle is found by rejection sampling rather than from an angle.
+		var cx, cy, r float64
+		for {
+			cx, cy = 2*rng.float64()-1, 2*rng.float64()-1
+			if r = sumSquares(cx, cy, 0); r > 0 && r <= 1 {
+				break
+			}
+		}
+		r = math.Sqrt(r)
+		pelletDir := along(along(w.Mul(cosTheta), u, float64(sinTheta*cx)/r), v, float64(sinTheta*cy)/r)
+
+		t := newUnitTracer(origin, pelletDir, maxDistance, conf)
+		if t.bound(g) != nil {
+			continue
+		}
+		for {
+			if cached.Solid(t.x, t.y, t.z) {
+				hits[i] = t.hit()
+				break
+			}
+			if !t.next() {
+				break
+			}
+		}
+	}
+	return hits
+}
+
+// splitMix64 is a SplitMix64 random number generator, which produces the same sequence for a seed on every platform.
+type splitMix64 uint64
+
+// next returns the next random 64-bit number produced by the generator.
+func (s *splitMix64) next() uint64 {
+	*s += 0x9e3779b97f4a7c15
+	z := uint64(*s)
+	z = (z ^ z>>30) * 0xbf58476d1ce4e5b9
+	z = (z ^ z>>27) * 0x94d049bb133111eb
+	return z ^ z>>31
+}
+
+// float64 returns a random number in [0, 1) with 53 bits of precision.
+func (s *splitMix64) float64() float64 {
+	return float64(s.next()>>11) / (1 << 53)
+}
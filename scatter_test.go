@@ -0,0 +1,103 @@
+package voxelraytrace
+
+import (
+	"github.com/go-gl/mathgl/mgl64"
+	"math"
+	"reflect"
+	"testing"
+)
+
+// hollowGrid is a Grid of which every voxel is solid, apart from the one at the origin.
+type hollowGrid struct{}
+
+// Solid reports if the voxel at the position passed is solid, which it is unless it is at the origin.
+func (hollowGrid) Solid(x, y, z int) bool {
+	return x != 0 || y != 0 || z != 0
+}
+
+// chiSquare returns the chi-squared statistic of the counts passed against a uniform distribution.
+func chiSquare(counts []int, total int) float64 {
+	expected := float64(total) / float64(len(counts))
+	var sum float64
+	for _, c := range counts {
+		sum += (float64(c) - expected) * (float64(c) - expected) / expected
+	}
+	return sum
+}
+
+func TestScatterTraceUniform(t *testing.T) {
+	// Every pellet fired from the centre of the only empty voxel hits the voxel next to it, at the point at which it
+	// leaves the empty voxel, so the direction of a pellet is that of its hit point.
+	origin := mgl64.Vec3{0.5, 0.5, 0.5}
+	const count, bins = 20000, 20
+	// The critical value of the chi-squared distribution with bins-1 degrees of freedom at a significance of 0.001.
+	const critical = 43.82
+	for _, test := range []struct {
+		dir       mgl64.Vec3
+		halfAngle float64
+	}{
+		{dir: mgl64.Vec3{0, 0, 1}, halfAngle: 30},
+		{dir: mgl64.Vec3{1, -2, 0.5}, halfAngle: 10},
+		{dir: mgl64.Vec3{-1, 0, 0}, halfAngle: 120},
+	} {
+		hits := ScatterTrace(hollowGrid{}, origin, test.dir, test.halfAngle, count, 7, 4)
+		w := test.dir.Normalize()
+		minCos := math.Cos(mgl64.DegToRad(test.halfAngle))
+
+		// Over the solid angle of the cone, the cosine of the angle with its axis is uniformly distributed, and so
+		// is the angle around the axis.
+		cosCounts, angleCounts := make([]int, bins), make([]int, bins)
+		u := w.Cross(mgl64.Vec3{0, 1, 0}).Normalize()
+		v := w.Cross(u)
+		for _, hit := range hits {
+			d := hit.Point.Sub(origin).Normalize()
+			cos := d.Dot(w)
+			if cos < minCos-1e-9 {
+				t.Fatalf("%v: pellet direction %v lies outside the cone", test.dir, d)
+			}
+			cosCounts[int(math.Min(bins-1, (cos-minCos)/(1-minCos)*bins))]++
+			angle := math.Atan2(d.Dot(v), d.Dot(u)) + math.Pi
+			angleCounts[int(math.Min(bins-1, angle/(2*math.Pi)*bins))]++
+		}
+		if chi := chiSquare(cosCounts, count); chi > critical {
+			t.Errorf("%v: cosines of the angles with the axis are not uniform: chi-squared %v, counts %v", test.dir, chi, cosCounts)
+		}
+		if chi := chiSquare(angleCounts, count); chi > critical {
+			t.Errorf("%v: angles around the axis are not uniform: chi-squared %v, counts %v", test.dir, chi, angleCounts)
+		}
+	}
+}
+
+func TestScatterTraceSeed(t *testing.T) {
+	g := wallGrid(6)
+	origin, dir := mgl64.Vec3{0.5, 0.5, 0.5}, mgl64.Vec3{1, 0, 0}
+	a := ScatterTrace(g, origin, dir, 25, 64, 42, 10)
+	b := ScatterTrace(g, origin, dir, 25, 64, 42, 10)
+	if !reflect.DeepEqual(a, b) {
+		t.Fatal("the same seed produced different pellets")
+	}
+	if c := ScatterTrace(g, origin, dir, 25, 64, 43, 10); reflect.DeepEqual(a, c) {
+		t.Error("different seeds produced the same pellets")
+	}
+	var hits, misses int
+	for _, hit := range a {
+		if math.IsInf(hit.Distance, 1) {
+			misses++
+			continue
+		}
+		if hit.Pos[0] != 6 || hit.Face != FaceWest {
+			t.Errorf("got %+v, want a hit on the west face of the wall", hit)
+		}
+		hits++
+	}
+	// The wall spans 5x5 voxels, so some pellets pass by it.
+	if hits == 0 || misses == 0 {
+		t.Errorf("got %v hits and %v misses, want both", hits, misses)
+	}
+	if got := ScatterTrace(g, origin, mgl64.Vec3{}, 25, 64, 42, 10); got != nil {
+		t.Errorf("got %v for a zero direction, want nil", got)
+	}
+	if got := ScatterTrace(g, origin, dir, 25, 0, 42, 10); got != nil {
+		t.Errorf("got %v for no pellets, want nil", got)
+	}
+}
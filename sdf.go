@@ -0,0 +1,124 @@
+package voxelraytrace
+
+import (
+	"github.com/go-gl/mathgl/mgl64"
+	"math"
+)
+
+// sdfMaxIterations is the maximum amount of steps taken by a ray marched through a signed distance function, so that
+// rays grazing a surface, which take ever smaller steps, always end.
+const sdfMaxIterations = 256
+
+// densityBisections is the amount of times the interval holding a surface found by MarchDensity is halved, which is
+// enough for the hit to be exact to well below a millionth of the step.
+const densityBisections = 24
+
+// MarchSDF marches a ray from the start position in the given direction through the signed distance function passed,
+// for a distance of the maxDistance, and returns the first point at which the distance to the surface is less than
+// epsilon. The sdf must return the distance from the point passed to the nearest surface, negative inside of it. The
+// ray moves forward by that distance at every step, which is safe as no surface can be nearer.
+// This is only exact for true distance functions: if sdf overestimates the distance, such as for a deformed or
+// combined shape, the ray may step over features thinner than the overestimation. Rays passing closely along a
+// surface without hitting it take ever smaller steps, so the march gives up after 256 steps, reporting no hit. If
+// the start lies inside a surface, it is returned as the hit. false is returned if the direction is zero or if no
+// surface was hit.
+func MarchSDF(sdf func(p mgl64.Vec3) float64, start, dir mgl64.Vec3, maxDistance, epsilon float64) (hit mgl64.Vec3, ok bool) {
+	if !(sumSquares(dir[0], dir[1], dir[2]) > 0) {
+		return mgl64.Vec3{}, false
+	}
+	dir = yUp.normalize(dir)
+	if dist, ok := marchSDF(sdf, start, dir, 0, maxDistance, epsilon); ok {
+		return along(start, dir, dist), true
+	}
+	return mgl64.Vec3{}, false
+}
+
+// MarchDensity marches a ray from the start position in the given direction through the density function passed, for
+// a distance of the maxDistance, and returns the first point at which the density becomes positive, such as the
+// surface of smooth terrain generated using marching cubes. Unlike a signed distance function, a density says nothing
+// about how far away the surface is, so the density is sampled at fixed steps of the step distance along the ray,
+// after which the surface between the last two samples is found by bisection. Surfaces thinner than the step may
+// therefore be missed. If the density at the start is positive, the start is returned as the hit. false is returned
+// if the direction is zero, if step is not positive or if no surface was hit.
+func MarchDensity(density func(p mgl64.Vec3) float64, start, dir mgl64.Vec3, maxDistance, step float64) (hit mgl64.Vec3, ok bool) {
+	if !(sumSquares(dir[0], dir[1], dir[2]) > 0) || !(step > 0) {
+		return mgl64.Vec3{}, false
+	}
+	dir = yUp.normalize(dir)
+	if density(start) > 0 {
+		return start, true
+	}
+	for prev := 0.0; prev < maxDistance; {
+		dist := math.Min(prev+step, maxDistance)
+		if density(along(start, dir, dist)) > 0 {
+			// The density is positive at dist but not at prev, so the surface lies between the two.
+			for i := 0; i < densityBisections; i++ {
+				mid := (prev + dist) / 2
+				if density(along(start, dir, mid)) > 0 {
+					dist = mid
+				} else {
+					prev = mid
+				}
+			}
+			return along(start, dir, dist), true
+		}
+		prev = dist
+	}
+	return mgl64.Vec3{}, false
+}
+
+// FirstSolidHitSDF performs a ray trace between the start and end coordinates like FirstSolidHit, but refines the
+// hit within voxels holding smooth terrain. sdfAt is called for every solid voxel passed through and may return a
+// signed distance function for the contents of the voxel, as used by MarchSDF, or nil if the voxel is a full block.
+// In the first case, the ray is marched through the part of the voxel it passes through, and the voxel is only hit if
+// the ray reaches the surface within the voxel, in which case Point and Distance of the HitResult are those of the
+// surface. Face remains the face through which the ray entered the voxel. The limitations of MarchSDF apply.
+func FirstSolidHitSDF(g Grid, sdfAt func(pos BlockPos) func(p mgl64.Vec3) float64, start, end mgl64.Vec3, epsilon float64, opts ...Option) (HitResult, bool, error) {
+	conf := newConfig(opts)
+	t, err := newTracer(start, end, conf)
+	if err != nil {
+		return HitResult{}, false, err
+	}
+	if !t.enter(conf) {
+		return HitResult{}, false, t.err
+	}
+	if err := t.bound(g); err != nil {
+		return HitResult{}, false, err
+	}
+	r := newVoxelReader(g, conf.passThrough)
+	for {
+		solid, ok := r.solid(&t)
+		if !ok {
+			return HitResult{}, false, t.err
+		}
+		if solid {
+			sdf := sdfAt(t.pos())
+			if sdf == nil {
+				return t.hit(), true, nil
+			}
+			exit, _ := t.peek()
+			if dist, ok := marchSDF(sdf, t.point(0), t.worldDir(), t.t, math.Min(exit, t.radius), epsilon); ok {
+				hit := t.hit()
+				hit.Point, hit.Distance = t.point(dist), dist
+				return hit, true, nil
+			}
+		}
+		if !t.next() {
+			return HitResult{}, false, t.err
+		}
+	}
+}
+
+// marchSDF marches the ray from the start in the normalised direction passed through the signed distance function,
+// between the distances from and to along it, and returns the distance at which the surface was reached.
+func marchSDF(sdf func(p mgl64.Vec3) float64, start, dir mgl64.Vec3, from, to, epsilon float64) (float64, bool) {
+	dist := from
+	for i := 0; i < sdfMaxIterations && dist <= to; i++ {
+		d := sdf(along(start, dir, dist))
+		if d < epsilon {
+			return dist, true
+		}
+		dist += d
+	}
+	return 0, false
+}
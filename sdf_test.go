@@ -0,0 +1,154 @@
+package voxelraytrace
+
+import (
+	"github.com/go-gl/mathgl/mgl64"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// sphereSDF returns the signed distance function of a sphere with the centre and radius passed.
+func sphereSDF(centre mgl64.Vec3, radius float64) func(p mgl64.Vec3) float64 {
+	return func(p mgl64.Vec3) float64 {
+		return p.Sub(centre).Len() - radius
+	}
+}
+
+// boxSDF returns the signed distance function of an axis-aligned box with the centre and half extents passed.
+func boxSDF(centre, half mgl64.Vec3) func(p mgl64.Vec3) float64 {
+	return func(p mgl64.Vec3) float64 {
+		var q, outside mgl64.Vec3
+		for i := range p {
+			q[i] = math.Abs(p[i]-centre[i]) - half[i]
+			outside[i] = math.Max(q[i], 0)
+		}
+		return outside.Len() + math.Min(math.Max(q[0], math.Max(q[1], q[2])), 0)
+	}
+}
+
+// raySphere returns the distance along the ray from the start in the normalised direction passed at which it first
+// hits the sphere passed, if it does.
+func raySphere(start, dir, centre mgl64.Vec3, radius float64) (float64, bool) {
+	oc := start.Sub(centre)
+	b, c := oc.Dot(dir), oc.Dot(oc)-radius*radius
+	disc := b*b - c
+	if disc < 0 {
+		return 0, false
+	}
+	t := -b - math.Sqrt(disc)
+	return t, t >= 0
+}
+
+func TestMarchSDFSphere(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	centre, radius := mgl64.Vec3{1, 2, 3}, 2.5
+	sdf := sphereSDF(centre, radius)
+	const epsilon = 1e-6
+	var hits int
+	for i := 0; i < 2000; i++ {
+		// Rays start outside the sphere and aim roughly at it, so that most hit it at varying angles.
+		start := centre.Add(mgl64.Vec3{rng.NormFloat64(), rng.NormFloat64(), rng.NormFloat64()}.Normalize().Mul(6))
+		dir := centre.Add(randomPoint(rng, 3.5)).Sub(start).Normalize()
+		want, wantOK := raySphere(start, dir, centre, radius)
+		hit, ok := MarchSDF(sdf, start, dir, 20, epsilon)
+		if !wantOK {
+			if ok {
+				t.Fatalf("ray %v along %v: got hit %v, want none", start, dir, hit)
+			}
+			continue
+		}
+		if !ok {
+			// Rays grazing the sphere may give up before reaching it.
+			if closestApproach(start, dir, centre) > radius-1e-3 {
+				continue
+			}
+			t.Fatalf("ray %v along %v: got no hit, want one at %v", start, dir, want)
+		}
+		hits++
+		if got := hit.Sub(start).Len(); got > want || want-got > 1e-4 || math.Abs(sdf(hit)) >= epsilon {
+			t.Fatalf("ray %v along %v: got hit %v at %v, want %v", start, dir, hit, got, want)
+		}
+	}
+	if hits < 1000 {
+		t.Fatalf("only %v rays hit the sphere", hits)
+	}
+}
+
+// closestApproach returns the distance between the point passed and the line through the start in the normalised
+// direction passed.
+func closestApproach(start, dir, p mgl64.Vec3) float64 {
+	v := p.Sub(start)
+	return v.Sub(dir.Mul(v.Dot(dir))).Len()
+}
+
+func TestMarchSDF(t *testing.T) {
+	box := boxSDF(mgl64.Vec3{5, 0, 0}, mgl64.Vec3{1, 2, 3})
+	tests := []struct {
+		name        string
+		sdf         func(p mgl64.Vec3) float64
+		start, dir  mgl64.Vec3
+		maxDistance float64
+		want        mgl64.Vec3
+		ok          bool
+	}{
+		{name: "box face", sdf: box, dir: mgl64.Vec3{1, 0, 0}, maxDistance: 10, want: mgl64.Vec3{4, 0, 0}, ok: true},
+		{name: "box edge", sdf: box, start: mgl64.Vec3{0, 6, 0}, dir: mgl64.Vec3{1, -1, 0}, maxDistance: 10, want: mgl64.Vec3{4, 2, 0}, ok: true},
+		{name: "box too far", sdf: box, dir: mgl64.Vec3{1, 0, 0}, maxDistance: 3.5},
+		{name: "box miss", sdf: box, dir: mgl64.Vec3{0, 1, 0}, maxDistance: 10},
+		{name: "start inside", sdf: box, start: mgl64.Vec3{5, 1, 1}, dir: mgl64.Vec3{1, 0, 0}, maxDistance: 10, want: mgl64.Vec3{5, 1, 1}, ok: true},
+		{name: "zero direction", sdf: box, maxDistance: 10},
+		// A ray running along the surface of a sphere at a distance barely above epsilon takes ever smaller steps
+		// and gives up.
+		{name: "grazing", sdf: sphereSDF(mgl64.Vec3{5, 1 + 2e-6, 0}, 1), dir: mgl64.Vec3{1, 0, 0}, maxDistance: 10},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			hit, ok := MarchSDF(test.sdf, test.start, test.dir, test.maxDistance, 1e-6)
+			if ok != test.ok || ok && hit.Sub(test.want).Len() > 1e-5 {
+				t.Errorf("got %v, %v, want %v, %v", hit, ok, test.want, test.ok)
+			}
+		})
+	}
+}
+
+func TestMarchDensity(t *testing.T) {
+	centre, radius := mgl64.Vec3{8, 0.5, 0.5}, 2.0
+	density := func(p mgl64.Vec3) float64 { return -sphereSDF(centre, radius)(p) }
+	start, dir := mgl64.Vec3{0, 1, 0.5}, mgl64.Vec3{1, 0, 0}
+	want, _ := raySphere(start, dir, centre, radius)
+	hit, ok := MarchDensity(density, start, dir, 20, 0.25)
+	if !ok || math.Abs(hit[0]-start[0]-want) > 1e-6 {
+		t.Errorf("got %v, %v, want a hit at distance %v", hit, ok, want)
+	}
+	// A step larger than the sphere steps over it.
+	if hit, ok := MarchDensity(density, start, dir, 20, 11); ok {
+		t.Errorf("got hit %v with a step larger than the sphere, want none", hit)
+	}
+	if _, ok := MarchDensity(density, start, dir, 20, 0); ok {
+		t.Error("got a hit for a step of 0, want none")
+	}
+}
+
+func TestFirstSolidHitSDF(t *testing.T) {
+	g := NewSparseGrid()
+	g.Set(3, 0, 0, true)
+	g.Set(6, 0, 0, true)
+	// Voxel 3 holds a small ball in its centre, while voxel 6 is a full block.
+	ball := sphereSDF(mgl64.Vec3{3.5, 0.5, 0.5}, 0.25)
+	sdfAt := func(pos BlockPos) func(p mgl64.Vec3) float64 {
+		if pos == (BlockPos{3, 0, 0}) {
+			return ball
+		}
+		return nil
+	}
+
+	hit, ok, err := FirstSolidHitSDF(g, sdfAt, mgl64.Vec3{0.5, 0.5, 0.5}, mgl64.Vec3{10.5, 0.5, 0.5}, 1e-7)
+	if err != nil || !ok || hit.Pos != (BlockPos{3, 0, 0}) || hit.Face != FaceWest || math.Abs(hit.Distance-2.75) > 1e-6 || math.Abs(hit.Point[0]-3.25) > 1e-6 {
+		t.Errorf("got %+v, %v, %v, want the surface of the ball at X 3.25", hit, ok, err)
+	}
+	// A ray passing through voxel 3 above the ball continues to the full block.
+	hit, ok, err = FirstSolidHitSDF(g, sdfAt, mgl64.Vec3{0.5, 0.9, 0.5}, mgl64.Vec3{10.5, 0.9, 0.5}, 1e-7)
+	if err != nil || !ok || hit.Pos != (BlockPos{6, 0, 0}) || hit.Point != (mgl64.Vec3{6, 0.9, 0.5}) {
+		t.Errorf("got %+v, %v, %v, want a hit on the full block", hit, ok, err)
+	}
+}
@@ -0,0 +1,53 @@
+package voxelraytrace
+
+import (
+	"errors"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// seedTolerance is the maximum distance that a seed point may lie away from the segment it is on.
+const seedTolerance = 1e-6
+
+// BetweenPointsSeeded performs a ray trace between the start and end coordinates like BetweenPoints, but resumes it
+// from the voxel containing nearPoint instead of starting at the start coordinates. The voxels before nearPoint are
+// not visited, so a long trace may be resumed cheaply, for example after a chunk it passes through was loaded. The
+// voxel containing nearPoint is the first voxel returned. An error is returned if nearPoint does not lie on the
+// segment between start and end. If nearPoint lies on a voxel boundary, the trace is resumed from the voxel beyond
+// it, unless nearPoint is the end of the ray.
+// The voxels returned are the same as the tail of the result of BetweenPoints.
+func BetweenPointsSeeded(start, end, nearPoint mgl64.Vec3, opts ...Option) ([]mgl64.Vec3, error) {
+	t, err := newTracer(start, end, newConfig(opts))
+	if err != nil {
+		return nil, err
+	}
+	offset := nearPoint.Sub(start)
+	dir := t.worldDir()
+	dist := offset.Dot(dir)
+	if dist < -seedTolerance || dist > t.radius+seedTolerance || offset.Sub(dir.Mul(dist)).Len() > seedTolerance {
+		return nil, errors.New("near point does not lie on the segment between the start and end points")
+	}
+	// The tracer is moved to just before the point and stepped the rest of the way, so that it resumes in the same
+	// voxel as BetweenPoints would pass through, even if the point lies on a voxel edge or corner. Points within
+	// boundaryTolerance of a boundary are considered to lie on it, as the distance of the point along the ray is not
+	// computed the same way as the distances to the boundaries.
+	// The voxels stepped through before the point are neither passed to the step hook nor counted towards
+	// WithMaxVoxels.
+	hook, maxVoxels := t.hook, t.maxVoxels
+	t.hook, t.maxVoxels = nil, 0
+	t.approach(dist)
+	for {
+		if tMax, _ := t.peek(); tMax > dist+boundaryTolerance || !t.next() {
+			break
+		}
+	}
+	t.hook, t.maxVoxels, t.visited = hook, maxVoxels, 1
+
+	var vectors []mgl64.Vec3
+	for {
+		vectors = append(vectors, t.pos().Vec3Min())
+		if !t.next() {
+			break
+		}
+	}
+	return vectors, t.err
+}
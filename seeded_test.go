@@ -0,0 +1,74 @@
+package voxelraytrace
+
+import (
+	"github.com/go-gl/mathgl/mgl64"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestBetweenPointsSeededMatchesTail(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 20000; i++ {
+		var start, end mgl64.Vec3
+		if i%2 == 0 {
+			start, end = randomPoint(rng, 8), randomPoint(rng, 8)
+		} else {
+			// Points on a coarse grid make the ray and the seed points pass through voxel edges and corners.
+			for j := 0; j < 3; j++ {
+				start[j], end[j] = float64(rng.Intn(33)-16)/4, float64(rng.Intn(33)-16)/4
+			}
+		}
+		if start == end {
+			continue
+		}
+		full, err := BetweenPoints(start, end)
+		if err != nil {
+			t.Fatalf("trace %v -> %v: unexpected error: %v", start, end, err)
+		}
+		near := start.Add(end.Sub(start).Mul(float64(rng.Intn(9)) / 8))
+		got, err := BetweenPointsSeeded(start, end, near)
+		if err != nil {
+			t.Fatalf("trace %v -> %v from %v: unexpected error: %v", start, end, near, err)
+		}
+		tail := full
+		for len(tail) > 0 && tail[0] != got[0] {
+			tail = tail[1:]
+		}
+		if !reflect.DeepEqual(got, tail) {
+			t.Fatalf("trace %v -> %v from %v: got %v, want the tail of %v", start, end, near, got, full)
+		}
+	}
+}
+
+func TestBetweenPointsSeeded(t *testing.T) {
+	start, end := mgl64.Vec3{0.5, 0.5, 0.5}, mgl64.Vec3{2.5, 2.5, 0.5}
+	tests := []struct {
+		name string
+		near mgl64.Vec3
+		opts []Option
+		want []mgl64.Vec3
+	}{
+		{name: "start", near: start, want: []mgl64.Vec3{{0, 0, 0}, {0, 1, 0}, {1, 1, 0}, {1, 2, 0}, {2, 2, 0}}},
+		{name: "edge", near: mgl64.Vec3{1, 1, 0.5}, want: []mgl64.Vec3{{1, 1, 0}, {1, 2, 0}, {2, 2, 0}}},
+		{name: "end", near: end, want: []mgl64.Vec3{{2, 2, 0}}},
+		{name: "max voxels", near: mgl64.Vec3{1.5, 1.5, 0.5}, opts: []Option{WithMaxVoxels(1)}, want: []mgl64.Vec3{{1, 1, 0}}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, _ := BetweenPointsSeeded(start, end, test.near, test.opts...)
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestBetweenPointsSeededOffSegment(t *testing.T) {
+	start, end := mgl64.Vec3{0.5, 0.5, 0.5}, mgl64.Vec3{8.5, 0.5, 0.5}
+	for _, near := range []mgl64.Vec3{{4.5, 0.6, 0.5}, {-0.5, 0.5, 0.5}, {9.5, 0.5, 0.5}} {
+		if _, err := BetweenPointsSeeded(start, end, near); err == nil {
+			t.Errorf("expected an error for near point %v", near)
+		}
+	}
+}
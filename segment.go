@@ -0,0 +1,85 @@
+package voxelraytrace
+
+import (
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// Segment is a line segment between a start and end point. It is a value type and may be stored and copied cheaply.
+// The zero value is a zero-length segment at the origin.
+type Segment struct {
+	Start, End mgl64.Vec3
+}
+
+// Voxels performs a ray trace along the segment and returns the coordinates of the voxels it passes through, like
+// BetweenPoints.
+func (s Segment) Voxels(opts ...Option) ([]mgl64.Vec3, error) {
+	return BetweenPoints(s.Start, s.End, opts...)
+}
+
+// Length returns the length of the segment.
+func (s Segment) Length() float64 {
+	return distance(s.Start, s.End)
+}
+
+// Direction returns the normalised direction from the start to the end of the segment. An error is returned if the
+// segment has a length of zero.
+func (s Segment) Direction() (mgl64.Vec3, error) {
+	r, err := s.ToRay()
+	return r.Direction, err
+}
+
+// Midpoint returns the point halfway between the start and end of the segment.
+func (s Segment) Midpoint() mgl64.Vec3 {
+	return s.Lerp(0.5)
+}
+
+// Lerp returns the point on the segment at the parameter t, with 0 being the start and 1 being the end.
+func (s Segment) Lerp(t float64) mgl64.Vec3 {
+	return s.Start.Add(s.End.Sub(s.Start).Mul(t))
+}
+
+// ContainsVoxel checks if the segment passes through the voxel passed. The whole segment is traced to check this,
+// so the cost grows with the length of the segment.
+func (s Segment) ContainsVoxel(v mgl64.Vec3) bool {
+	voxels, err := s.Voxels()
+	if err != nil {
+		return false
+	}
+	for _, voxel := range voxels {
+		if voxel == v {
+			return true
+		}
+	}
+	return false
+}
+
+// ToRay returns a Ray from the start to the end of the segment. An error is returned if the segment has a length of
+// zero.
+func (s Segment) ToRay() (Ray, error) {
+	diff := s.End.Sub(s.Start)
+	return NewRay(s.Start, diff, diff.Len())
+}
+
+// ExtendRay returns the segment between the start and end coordinates with its end moved further along the segment
+// by amount. The direction of the segment need not be normalised. If the segment has a length of zero, it has no
+// direction to be extended in, and the coordinates are returned unchanged with false.
+func ExtendRay(start, end mgl64.Vec3, amount float64) (mgl64.Vec3, mgl64.Vec3, bool) {
+	diff := end.Sub(start)
+	l := diff.Len()
+	if l == 0 {
+		return start, end, false
+	}
+	return start, end.Add(diff.Mul(amount / l)), true
+}
+
+// ShrinkRay returns the segment between the start and end coordinates with its end moved back towards the start by
+// amount. If amount is at least the length of the segment, including for segments with a length of zero, the
+// coordinates are returned unchanged with false.
+func ShrinkRay(start, end mgl64.Vec3, amount float64) (mgl64.Vec3, mgl64.Vec3, bool) {
+	diff := end.Sub(start)
+	l := diff.Len()
+	if amount >= l {
+		return start, end, false
+	}
+	return start, end.Sub(diff.Mul(amount / l)), true
+}
@@ -0,0 +1,53 @@
+package voxelraytrace
+
+import (
+	"github.com/go-gl/mathgl/mgl64"
+	"testing"
+)
+
+func TestExtendRay(t *testing.T) {
+	tests := []struct {
+		name       string
+		start, end mgl64.Vec3
+		amount     float64
+		want       mgl64.Vec3
+		ok         bool
+	}{
+		{name: "axis aligned", start: mgl64.Vec3{1, 2, 3}, end: mgl64.Vec3{1, 2, 7}, amount: 0.5, want: mgl64.Vec3{1, 2, 7.5}, ok: true},
+		{name: "not normalised", start: mgl64.Vec3{0, 0, 0}, end: mgl64.Vec3{-3, 4, 0}, amount: 5, want: mgl64.Vec3{-6, 8, 0}, ok: true},
+		{name: "zero amount", start: mgl64.Vec3{0, 0, 0}, end: mgl64.Vec3{-3, 4, 0}, want: mgl64.Vec3{-3, 4, 0}, ok: true},
+		{name: "zero length", start: mgl64.Vec3{1, 2, 3}, end: mgl64.Vec3{1, 2, 3}, amount: 1, want: mgl64.Vec3{1, 2, 3}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			start, end, ok := ExtendRay(test.start, test.end, test.amount)
+			if start != test.start || !vec3Near(end, test.want) || ok != test.ok {
+				t.Errorf("got %v, %v, %v, want %v, %v, %v", start, end, ok, test.start, test.want, test.ok)
+			}
+		})
+	}
+}
+
+func TestShrinkRay(t *testing.T) {
+	tests := []struct {
+		name       string
+		start, end mgl64.Vec3
+		amount     float64
+		want       mgl64.Vec3
+		ok         bool
+	}{
+		{name: "axis aligned", start: mgl64.Vec3{1, 2, 3}, end: mgl64.Vec3{1, 2, 7}, amount: 0.5, want: mgl64.Vec3{1, 2, 6.5}, ok: true},
+		{name: "not normalised", start: mgl64.Vec3{0, 0, 0}, end: mgl64.Vec3{-6, 8, 0}, amount: 5, want: mgl64.Vec3{-3, 4, 0}, ok: true},
+		{name: "whole length", start: mgl64.Vec3{0, 0, 0}, end: mgl64.Vec3{-3, 4, 0}, amount: 5, want: mgl64.Vec3{-3, 4, 0}},
+		{name: "beyond start", start: mgl64.Vec3{0, 0, 0}, end: mgl64.Vec3{-3, 4, 0}, amount: 6, want: mgl64.Vec3{-3, 4, 0}},
+		{name: "zero length", start: mgl64.Vec3{1, 2, 3}, end: mgl64.Vec3{1, 2, 3}, want: mgl64.Vec3{1, 2, 3}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			start, end, ok := ShrinkRay(test.start, test.end, test.amount)
+			if start != test.start || !vec3Near(end, test.want) || ok != test.ok {
+				t.Errorf("got %v, %v, %v, want %v, %v, %v", start, end, ok, test.start, test.want, test.ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package voxelraytrace
+
+import (
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// VoxelSetUnion returns every voxel that is in a or b once, in the order of a followed by the voxels of b that are
+// not in a. Voxels are compared by the voxel containing them, so vectors are considered equal if their floored
+// coordinates are.
+func VoxelSetUnion(a, b []mgl64.Vec3) []mgl64.Vec3 {
+	var union []mgl64.Vec3
+	seen := make(map[BlockPos]struct{}, len(a)+len(b))
+	for _, voxels := range [2][]mgl64.Vec3{a, b} {
+		for _, v := range voxels {
+			pos := BlockPosFromVec3(v)
+			if _, ok := seen[pos]; !ok {
+				seen[pos] = struct{}{}
+				union = append(union, v)
+			}
+		}
+	}
+	return union
+}
+
+// VoxelSetIntersection returns every voxel of a that is also in b once, in the order of a. Voxels are compared like
+// in VoxelSetUnion.
+func VoxelSetIntersection(a, b []mgl64.Vec3) []mgl64.Vec3 {
+	return filterSet(a, b, true)
+}
+
+// VoxelSetDifference returns every voxel of a that is not in b once, in the order of a. Voxels are compared like in
+// VoxelSetUnion.
+func VoxelSetDifference(a, b []mgl64.Vec3) []mgl64.Vec3 {
+	return filterSet(a, b, false)
+}
+
+// filterSet returns every voxel of a once for which being in b matches in, in the order of a.
+func filterSet(a, b []mgl64.Vec3, in bool) []mgl64.Vec3 {
+	set := make(map[BlockPos]struct{}, len(b))
+	for _, v := range b {
+		set[BlockPosFromVec3(v)] = struct{}{}
+	}
+	var filtered []mgl64.Vec3
+	seen := make(map[BlockPos]struct{}, len(a))
+	for _, v := range a {
+		pos := BlockPosFromVec3(v)
+		if _, ok := seen[pos]; ok {
+			continue
+		}
+		seen[pos] = struct{}{}
+		if _, ok := set[pos]; ok == in {
+			filtered = append(filtered, v)
+		}
+	}
+	return filtered
+}
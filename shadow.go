@@ -0,0 +1,80 @@
+package voxelraytrace
+
+import (
+	"errors"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// BitGrid is a BitsetGrid holding a single bit for every voxel of a region, such as the mask returned by ShadowMask.
+type BitGrid struct {
+	BitsetGrid
+}
+
+// newBitGrid creates a BitGrid with all bits unset, spanning the region passed.
+func newBitGrid(region AABBInt) *BitGrid {
+	size := region.Max.Add(BlockPos{1 - region.Min[0], 1 - region.Min[1], 1 - region.Min[2]})
+	return &BitGrid{BitsetGrid: *NewBitsetGrid(region.Min, size[0], size[1], size[2])}
+}
+
+// Occluded returns true if the bit of the voxel at the coordinates passed is set. It is the same as Solid.
+func (g *BitGrid) Occluded(x, y, z int) bool {
+	return g.Solid(x, y, z)
+}
+
+// ShadowMask computes which voxels of the region passed are in the shadow of a sun shining in the direction of sunDir,
+// which points from the sun towards the world. A voxel is occluded if the ray traced from its centre towards the sun
+// passes through a solid voxel other than itself before leaving the region, so solid voxels outside the region never
+// cast shadows into it. The result is the same as tracing every one of these rays, but as all rays start at the centre
+// of a voxel and share their direction, they pass through the same voxels relative to their start. This path is
+// traced only once, and is then walked backwards from every solid voxel to mark the voxels it occludes. An error is
+// returned if the region is empty or if sunDir is zero.
+func ShadowMask(g Grid, region AABBInt, sunDir mgl64.Vec3) (*BitGrid, error) {
+	if region.Empty() {
+		return nil, errors.New("shadow mask region must not be empty")
+	}
+	if sunDir.LenSqr() <= 0 {
+		return nil, ErrZeroDirection
+	}
+	mask := newBitGrid(region)
+	size := BlockPos{mask.w, mask.h, mask.d}
+
+	// The path of a ray towards the sun, relative to the voxel it starts in. It ends once it is offset by the size of
+	// the region on any axis, as no ray starting inside the region can be inside it any longer from there.
+	var path []BlockPos
+	t := newUnitTracer(mgl64.Vec3{0.5, 0.5, 0.5}, yUp.normalize(sunDir.Mul(-1)), size.Vec3Min().Len()+2, newConfig(nil))
+	for t.next() {
+		offset := t.pos()
+		if absInt(offset[0]) >= size[0] || absInt(offset[1]) >= size[1] || absInt(offset[2]) >= size[2] {
+			break
+		}
+		path = append(path, offset)
+	}
+
+	for x := region.Min[0]; x <= region.Max[0]; x++ {
+		for y := region.Min[1]; y <= region.Max[1]; y++ {
+			for z := region.Min[2]; z <= region.Max[2]; z++ {
+				if !g.Solid(x, y, z) {
+					continue
+				}
+				// The path only ever moves away from its start on every axis, so once it leaves the region walking
+				// backwards, it never enters it again.
+				for _, offset := range path {
+					pos := BlockPos{x - offset[0], y - offset[1], z - offset[2]}
+					if !insideRegion(pos, region.Min, region.Max) {
+						break
+					}
+					mask.Set(pos[0], pos[1], pos[2], true)
+				}
+			}
+		}
+	}
+	return mask, nil
+}
+
+// absInt returns the absolute value of the integer passed.
+func absInt(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,61 @@
+package voxelraytrace
+
+import (
+	"github.com/go-gl/mathgl/mgl64"
+	"math/rand"
+	"testing"
+)
+
+func TestShadowMaskMatchesTracedRays(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	region := AABBInt{Min: BlockPos{-3, 60, 2}, Max: BlockPos{4, 67, 7}}
+	g := NewSparseGrid()
+	for i := 0; i < 40; i++ {
+		g.Set(region.Min[0]+rng.Intn(8), region.Min[1]+rng.Intn(8), region.Min[2]+rng.Intn(6), true)
+	}
+	// A solid voxel outside the region, which may not cast a shadow into it.
+	g.Set(0, 70, 4, true)
+
+	dirs := []mgl64.Vec3{{0, -1, 0}, {1, -1, 0}, {-1, -2, 0.5}, {0.3, -0.8, -0.4}, {1, 0, 0}}
+	for i := 0; i < 5; i++ {
+		dirs = append(dirs, mgl64.Vec3{rng.Float64()*2 - 1, rng.Float64()*2 - 1, rng.Float64()*2 - 1})
+	}
+	for _, dir := range dirs {
+		mask, err := ShadowMask(g, region, dir)
+		if err != nil {
+			t.Fatalf("sun direction %v: unexpected error: %v", dir, err)
+		}
+		for x := region.Min[0]; x <= region.Max[0]; x++ {
+			for y := region.Min[1]; y <= region.Max[1]; y++ {
+				for z := region.Min[2]; z <= region.Max[2]; z++ {
+					pos := BlockPos{x, y, z}
+					if want := tracedOccluded(g, region, pos, dir); mask.Occluded(x, y, z) != want {
+						t.Fatalf("sun direction %v: got %v for %v, want %v", dir, !want, pos, want)
+					}
+				}
+			}
+		}
+	}
+	if _, err := ShadowMask(g, region, mgl64.Vec3{}); err != ErrZeroDirection {
+		t.Errorf("got %v for a zero sun direction, want %v", err, ErrZeroDirection)
+	}
+	if _, err := ShadowMask(g, AABBInt{Min: BlockPos{1, 1, 1}, Max: BlockPos{0, 0, 0}}, mgl64.Vec3{0, -1, 0}); err == nil {
+		t.Error("expected an error for an empty region")
+	}
+}
+
+// tracedOccluded traces a ray from the centre of the voxel passed towards the sun and checks if it passes through a
+// solid voxel other than the one it starts in before leaving the region.
+func tracedOccluded(g Grid, region AABBInt, pos BlockPos, sunDir mgl64.Vec3) bool {
+	start := pos.Vec3Centre()
+	positions, _ := BetweenPointsInt(start, start.Sub(sunDir.Normalize().Mul(64)))
+	for _, p := range positions[1:] {
+		if !insideRegion(p, region.Min, region.Max) {
+			return false
+		}
+		if g.Solid(p[0], p[1], p[2]) {
+			return true
+		}
+	}
+	return false
+}
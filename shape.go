@@ -0,0 +1,51 @@
+package voxelraytrace
+
+import (
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// ShapeProvider provides the collision shapes of voxels, for worlds with blocks that do not fill their voxel
+// entirely, such as slabs, stairs and fences.
+type ShapeProvider interface {
+	// Shapes returns the boxes making up the collision shape of the voxel at the coordinates passed, relative to the
+	// minimum corner of the voxel, so that a full block is the box from (0, 0, 0) to (1, 1, 1). Voxels without a
+	// collision shape, such as air, return no boxes.
+	Shapes(x, y, z int) []AABB
+}
+
+// FirstShapeHit performs a ray trace between the start and end coordinates and returns the first collision shape of
+// the ShapeProvider passed that is hit by the ray. The voxels are visited in the same order as they are returned by
+// BetweenPoints, and the ray is clipped against the boxes of every voxel using ClipAABB, so that a ray passing
+// through the empty half of a slab does not hit it. The HitResult holds the voxel the shape belongs to and the point
+// and face at which the ray entered the nearest box of the shape. If the ray starts inside a box, the voxel is
+// returned with HitResult.StartedInside set.
+// Only the boxes of voxels passed through are tested, so parts of boxes reaching out of their voxel, such as the top
+// of a fence, are missed by rays that do not pass through the voxel itself.
+func FirstShapeHit(s ShapeProvider, start, end mgl64.Vec3, opts ...Option) (HitResult, bool, error) {
+	t, err := newTracer(start, end, newConfig(opts))
+	if err != nil {
+		return HitResult{}, false, err
+	}
+	for {
+		min, pos := t.world(t.rawPos().Vec3Min()), t.pos()
+		var (
+			hit   HitResult
+			found bool
+		)
+		for _, box := range s.Shapes(pos[0], pos[1], pos[2]) {
+			point, face, ok := ClipAABB(start, end, box.Translate(min))
+			if !ok {
+				continue
+			}
+			if dist := distance(start, point); !found || dist < hit.Distance {
+				hit, found = HitResult{Pos: pos, Point: point, Face: face, Distance: dist, StartedInside: face == FaceNone}, true
+			}
+		}
+		if found {
+			return hit, true, nil
+		}
+		if !t.next() {
+			return HitResult{}, false, t.err
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package voxelraytrace
+
+import (
+	"errors"
+	"github.com/go-gl/mathgl/mgl64"
+	"math"
+)
+
+// SmoothPath removes redundant waypoints from a path, such as one found by a path finder moving between neighbouring
+// voxels, using greedy string pulling: starting at the first waypoint, every waypoint is skipped for as long as the
+// segment from the last waypoint kept to the one after it does not touch any solid voxel of the Grid passed. The
+// first and last waypoints are always kept.
+// If clearance is positive, a segment is only considered free if no solid voxel lies within the clearance of it,
+// as if a sphere with that radius were moved along it. This check is conservative, so it may keep a waypoint that is
+// not strictly needed. SmoothPath never removes waypoints such that the path passes through a solid voxel, as long as
+// the segments between the original waypoints do not pass through one.
+func SmoothPath(g Grid, waypoints []mgl64.Vec3, clearance float64) ([]mgl64.Vec3, error) {
+	if clearance < 0 || math.IsNaN(clearance) {
+		return nil, errors.New("clearance must not be negative")
+	}
+	if len(waypoints) <= 2 {
+		return append([]mgl64.Vec3(nil), waypoints...), nil
+	}
+	smoothed := []mgl64.Vec3{waypoints[0]}
+	anchor := waypoints[0]
+	for i := 2; i < len(waypoints); i++ {
+		free, err := segmentFree(g, anchor, waypoints[i], clearance)
+		if err != nil {
+			return nil, err
+		}
+		if !free {
+			anchor = waypoints[i-1]
+			smoothed = append(smoothed, anchor)
+		}
+	}
+	return append(smoothed, waypoints[len(waypoints)-1]), nil
+}
+
+// segmentFree checks if no solid voxel of the Grid passed lies within the clearance of the segment between a and b.
+func segmentFree(g Grid, a, b mgl64.Vec3, clearance float64) (bool, error) {
+	if clearance == 0 {
+		if a == b {
+			pos := BlockPosFromVec3(a)
+			return !g.Solid(pos[0], pos[1], pos[2]), nil
+		}
+		_, hit, err := FirstSolidHit(g, a, b)
+		return !hit && err == nil, err
+	}
+	// A voxel lies within the clearance of the segment if any point of it does. The distance from the centre of the
+	// voxel to the segment minus the distance from the centre to a corner never overestimates that distance.
+	maxDist := clearance + math.Sqrt(3)/2
+	expand := mgl64.Vec3{clearance, clearance, clearance}
+	min := mgl64.Vec3{math.Min(a[0], b[0]), math.Min(a[1], b[1]), math.Min(a[2], b[2])}.Sub(expand)
+	max := mgl64.Vec3{math.Max(a[0], b[0]), math.Max(a[1], b[1]), math.Max(a[2], b[2])}.Add(expand)
+	free := true
+	VoxelizeAABBFunc(min, max, func(pos BlockPos) bool {
+		if g.Solid(pos[0], pos[1], pos[2]) && distanceToSegment(pos.Vec3Centre(), a, b) <= maxDist {
+			free = false
+		}
+		return free
+	})
+	return free, nil
+}
+
+// distanceToSegment returns the distance from the point p to the closest point on the segment between a and b.
+func distanceToSegment(p, a, b mgl64.Vec3) float64 {
+	ab := b.Sub(a)
+	l := ab.LenSqr()
+	if l == 0 {
+		return p.Sub(a).Len()
+	}
+	t := math.Max(0, math.Min(1, p.Sub(a).Dot(ab)/l))
+	return p.Sub(a.Add(ab.Mul(t))).Len()
+}
@@ -0,0 +1,116 @@
+package voxelraytrace
+
+import (
+	"github.com/go-gl/mathgl/mgl64"
+	"testing"
+)
+
+// maze is a layer of voxels at Y=0, with # marking solid voxels. X increases to the right and Z downwards.
+var maze = []string{
+	"###########",
+	"#.....#...#",
+	"#.###.#.#.#",
+	"#.#...#.#.#",
+	"#.#.#####.#",
+	"#...#.....#",
+	"###.#.###.#",
+	"#...#...#.#",
+	"#.#####.#.#",
+	"#.......#.#",
+	"###########",
+}
+
+// mazeGrid returns a SparseGrid holding the walls of the maze.
+func mazeGrid() *SparseGrid {
+	g := NewSparseGrid()
+	for z, row := range maze {
+		for x, c := range row {
+			if c == '#' {
+				g.Set(x, 0, z, true)
+			}
+		}
+	}
+	return g
+}
+
+// mazePath finds the shortest path between the voxels passed through the maze, moving between neighbouring voxels
+// only, and returns the centres of the voxels passed through.
+func mazePath(g Grid, from, to BlockPos) []mgl64.Vec3 {
+	prev := map[BlockPos]BlockPos{from: from}
+	queue := []BlockPos{from}
+	for len(queue) > 0 && queue[0] != to {
+		pos := queue[0]
+		queue = queue[1:]
+		for _, f := range []Face{FaceNorth, FaceSouth, FaceWest, FaceEast} {
+			next := pos.Side(f)
+			if _, ok := prev[next]; ok || g.Solid(next[0], next[1], next[2]) {
+				continue
+			}
+			prev[next] = pos
+			queue = append(queue, next)
+		}
+	}
+	var path []mgl64.Vec3
+	for pos := to; pos != from; pos = prev[pos] {
+		path = append([]mgl64.Vec3{pos.Vec3Centre()}, path...)
+	}
+	return append([]mgl64.Vec3{from.Vec3Centre()}, path...)
+}
+
+func TestSmoothPathMaze(t *testing.T) {
+	g := mazeGrid()
+	waypoints := mazePath(g, BlockPos{1, 0, 1}, BlockPos{9, 0, 9})
+	for _, clearance := range []float64{0, 0.3} {
+		smoothed, err := SmoothPath(g, waypoints, clearance)
+		if err != nil {
+			t.Fatalf("clearance %v: unexpected error: %v", clearance, err)
+		}
+		if smoothed[0] != waypoints[0] || smoothed[len(smoothed)-1] != waypoints[len(waypoints)-1] {
+			t.Fatalf("clearance %v: path %v does not keep the end points", clearance, smoothed)
+		}
+		if len(smoothed) >= len(waypoints) {
+			t.Errorf("clearance %v: no waypoints removed from %v waypoints", clearance, len(waypoints))
+		}
+		// The waypoints kept are a subsequence of the original ones.
+		j := 0
+		for _, w := range smoothed {
+			for j < len(waypoints) && waypoints[j] != w {
+				j++
+			}
+			if j == len(waypoints) {
+				t.Fatalf("clearance %v: waypoint %v is not one of the original waypoints, or out of order", clearance, w)
+			}
+		}
+		for i := 1; i < len(smoothed); i++ {
+			a, b := smoothed[i-1], smoothed[i]
+			if _, hit, _ := FirstSolidHit(g, a, b); hit {
+				t.Fatalf("clearance %v: segment %v -> %v passes through a solid voxel", clearance, a, b)
+			}
+			if clearance == 0 {
+				continue
+			}
+			// No solid voxel may lie within the clearance of any point of the segment.
+			for s := 0.0; s <= 1; s += 0.01 {
+				p := a.Add(b.Sub(a).Mul(s))
+				for _, pos := range VoxelizeSphere(p, clearance) {
+					if g.Solid(pos[0], pos[1], pos[2]) {
+						t.Fatalf("clearance %v: segment %v -> %v passes within the clearance of %v at %v", clearance, a, b, pos, p)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestSmoothPathShort(t *testing.T) {
+	g := mazeGrid()
+	for _, waypoints := range [][]mgl64.Vec3{nil, {{1.5, 0.5, 1.5}}, {{1.5, 0.5, 1.5}, {9.5, 0.5, 9.5}}} {
+		got, err := SmoothPath(g, waypoints, 0)
+		if err != nil || len(got) != len(waypoints) {
+			t.Errorf("got %v, %v for %v, want the waypoints unchanged", got, err, waypoints)
+		}
+	}
+	if _, err := SmoothPath(g, nil, -1); err == nil {
+		t.Error("expected an error for a negative clearance")
+	}
+}
@@ -0,0 +1,29 @@
+package voxelraytrace
+
+import (
+	"github.com/go-gl/mathgl/mgl64"
+	"math"
+)
+
+// SnapToGrid snaps the point passed down to the minimum corner of the voxel containing it, in a grid of voxels with
+// the size passed. Snapping uses the floor of each coordinate, so that negative coordinates snap towards negative
+// infinity rather than towards zero. The voxel size must be positive.
+func SnapToGrid(point mgl64.Vec3, voxelSize float64) mgl64.Vec3 {
+	return SnapToGrid3D(point, voxelSize, voxelSize, voxelSize)
+}
+
+// SnapToVoxelCenter snaps the point passed to the centre of the voxel containing it, in a grid of voxels with the
+// size passed. Like SnapToGrid, it uses the floor of each coordinate. The voxel size must be positive.
+func SnapToVoxelCenter(point mgl64.Vec3, voxelSize float64) mgl64.Vec3 {
+	return SnapToGrid(point, voxelSize).Add(mgl64.Vec3{voxelSize / 2, voxelSize / 2, voxelSize / 2})
+}
+
+// SnapToGrid3D snaps the point passed down to the minimum corner of the voxel containing it, in a grid of which the
+// voxels have a different size on each axis. All sizes must be positive.
+func SnapToGrid3D(point mgl64.Vec3, scaleX, scaleY, scaleZ float64) mgl64.Vec3 {
+	return mgl64.Vec3{
+		math.Floor(point[0]/scaleX) * scaleX,
+		math.Floor(point[1]/scaleY) * scaleY,
+		math.Floor(point[2]/scaleZ) * scaleZ,
+	}
+}
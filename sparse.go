@@ -0,0 +1,147 @@
+package voxelraytrace
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/go-gl/mathgl/mgl64"
+	"io"
+)
+
+// SparseVoxelGrid is a BoundedVoxelGrid backed by a map holding the positions of all occupied voxels. It has no fixed
+// size and is suited for large worlds. The voxels are keyed by BlockPos, like every other grid of the package, rather
+// than by [3]int64, so that positions need not be converted when passed between them. The bounds are extended when
+// voxels are added, and recomputed on the next call to Bounds after voxels are removed. The zero value is an empty grid
+// ready to use. A SparseVoxelGrid is not safe for concurrent use if any of the goroutines using it modify it.
+type SparseVoxelGrid struct {
+	voxels map[BlockPos]struct{}
+
+	// min and max are the minimum and maximum positions of the occupied voxels. They are only valid if dirty is
+	// false and the grid is not empty.
+	min, max BlockPos
+	dirty    bool
+}
+
+// NewSparseVoxelGrid creates a SparseVoxelGrid with the voxels passed occupied.
+func NewSparseVoxelGrid(occupied []mgl64.Vec3) *SparseVoxelGrid {
+	g := &SparseVoxelGrid{voxels: make(map[BlockPos]struct{}, len(occupied))}
+	for _, v := range occupied {
+		g.Add(v)
+	}
+	return g
+}
+
+// Add marks the voxel containing the vector passed as occupied.
+func (g *SparseVoxelGrid) Add(v mgl64.Vec3) {
+	pos := BlockPosFromVec3(v)
+	if g.voxels == nil {
+		g.voxels = make(map[BlockPos]struct{})
+	}
+	if len(g.voxels) == 0 {
+		g.min, g.max, g.dirty = pos, pos, false
+	} else if !g.dirty {
+		g.extend(pos)
+	}
+	g.voxels[pos] = struct{}{}
+}
+
+// Remove marks the voxel containing the vector passed as empty. The bounds are recomputed lazily, as the voxel
+// removed may have been on the boundary of the grid.
+func (g *SparseVoxelGrid) Remove(v mgl64.Vec3) {
+	pos := BlockPosFromVec3(v)
+	if _, ok := g.voxels[pos]; ok {
+		delete(g.voxels, pos)
+		g.dirty = true
+	}
+}
+
+// Contains returns true if the voxel containing the vector passed is occupied.
+func (g *SparseVoxelGrid) Contains(v mgl64.Vec3) bool {
+	_, ok := g.voxels[BlockPosFromVec3(v)]
+	return ok
+}
+
+// Len returns the amount of occupied voxels in the grid.
+func (g *SparseVoxelGrid) Len() int {
+	return len(g.voxels)
+}
+
+// Bounds returns the world space extent of the occupied voxels of the grid. If voxels were removed since the last
+// call, all voxels are scanned to recompute the bounds, which takes O(n) time. An empty grid has zero bounds.
+func (g *SparseVoxelGrid) Bounds() (min, max mgl64.Vec3) {
+	if len(g.voxels) == 0 {
+		return mgl64.Vec3{}, mgl64.Vec3{}
+	}
+	if g.dirty {
+		first := true
+		for pos := range g.voxels {
+			if first {
+				g.min, g.max, first = pos, pos, false
+				continue
+			}
+			g.extend(pos)
+		}
+		g.dirty = false
+	}
+	return g.min.Vec3Min(), g.max.Add(BlockPos{1, 1, 1}).Vec3Min()
+}
+
+// WriteTo writes the occupied voxels of the grid to the io.Writer passed, in the same binary format as used by
+// OccupancyMap.MarshalBinary, so that the data may be loaded into either type. It returns the amount of bytes written.
+func (g *SparseVoxelGrid) WriteTo(w io.Writer) (int64, error) {
+	positions := make([]BlockPos, 0, len(g.voxels))
+	for pos := range g.voxels {
+		positions = append(positions, pos)
+	}
+	sortBlockPos(positions)
+	n, err := w.Write(encodePositions(positions))
+	return int64(n), err
+}
+
+// ReadFrom reads voxels written using WriteTo from the io.Reader passed until EOF, replacing the voxels currently in
+// the grid. It returns the amount of bytes read. If the data is truncated or malformed, an error is returned and the
+// grid is left unchanged.
+func (g *SparseVoxelGrid) ReadFrom(r io.Reader) (int64, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return int64(len(data)), fmt.Errorf("sparse voxel grid: %w", err)
+	}
+	positions, err := decodePositions(data)
+	if err != nil {
+		return int64(len(data)), fmt.Errorf("sparse voxel grid: %w", err)
+	}
+	g.voxels = make(map[BlockPos]struct{}, len(positions))
+	for _, pos := range positions {
+		g.voxels[pos] = struct{}{}
+	}
+	g.dirty = true
+	return int64(len(data)), nil
+}
+
+// MarshalBinary encodes the occupied voxels of the grid like WriteTo, so that a SparseVoxelGrid implements
+// encoding.BinaryMarshaler and may be used with packages such as encoding/gob.
+func (g *SparseVoxelGrid) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+	if _, err := g.WriteTo(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary decodes voxels encoded using MarshalBinary or WriteTo like ReadFrom, replacing the voxels currently
+// in the grid.
+func (g *SparseVoxelGrid) UnmarshalBinary(data []byte) error {
+	_, err := g.ReadFrom(bytes.NewReader(data))
+	return err
+}
+
+// extend extends the bounds of the grid so that they include the position passed.
+func (g *SparseVoxelGrid) extend(pos BlockPos) {
+	for i := 0; i < 3; i++ {
+		if pos[i] < g.min[i] {
+			g.min[i] = pos[i]
+		}
+		if pos[i] > g.max[i] {
+			g.max[i] = pos[i]
+		}
+	}
+}
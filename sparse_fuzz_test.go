@@ -0,0 +1,36 @@
+//go:build go1.18
+
+package voxelraytrace
+
+import (
+	"bytes"
+	"github.com/go-gl/mathgl/mgl64"
+	"testing"
+)
+
+func FuzzSparseVoxelGridReadFrom(f *testing.F) {
+	data, _ := NewSparseVoxelGrid([]mgl64.Vec3{{0, 0, 0}, {4, 1, 2}, {-2, 3, 1}}).MarshalBinary()
+	f.Add(data)
+	f.Add([]byte("VXOM\x01"))
+	f.Add([]byte{})
+	f.Fuzz(func(t *testing.T, b []byte) {
+		var g SparseVoxelGrid
+		if _, err := g.ReadFrom(bytes.NewReader(b)); err != nil {
+			return
+		}
+		// Data that was read encodes to voxels that read back the same.
+		var buf bytes.Buffer
+		if _, err := g.WriteTo(&buf); err != nil {
+			t.Fatalf("unexpected error writing the grid read: %v", err)
+		}
+		var again SparseVoxelGrid
+		if _, err := again.ReadFrom(&buf); err != nil || again.Len() != g.Len() {
+			t.Fatalf("got %v voxels, %v reading again, want %v", again.Len(), err, g.Len())
+		}
+		for pos := range g.voxels {
+			if _, ok := again.voxels[pos]; !ok {
+				t.Fatalf("voxel %v missing after reading again", pos)
+			}
+		}
+	})
+}
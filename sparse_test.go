@@ -0,0 +1,164 @@
+package voxelraytrace
+
+import (
+	"bytes"
+	"errors"
+	"github.com/go-gl/mathgl/mgl64"
+	"math/rand"
+	"testing"
+)
+
+func TestSparseVoxelGridZeroValue(t *testing.T) {
+	var g SparseVoxelGrid
+	if g.Contains(mgl64.Vec3{}) || g.Len() != 0 {
+		t.Fatal("zero value grid is not empty")
+	}
+	g.Remove(mgl64.Vec3{})
+	g.Add(mgl64.Vec3{1.5, 2.5, -0.5})
+	if !g.Contains(mgl64.Vec3{1.2, 2.9, -0.1}) || g.Len() != 1 {
+		t.Error("voxel added to the zero value grid is not occupied")
+	}
+	if min, max := g.Bounds(); min != (mgl64.Vec3{1, 2, -1}) || max != (mgl64.Vec3{2, 3, 0}) {
+		t.Errorf("got bounds %v, %v, want [1 2 -1], [2 3 0]", min, max)
+	}
+}
+
+func TestSparseVoxelGridBounds(t *testing.T) {
+	g := NewSparseVoxelGrid([]mgl64.Vec3{{0, 0, 0}, {4, 1, 2}, {-2, 3, 1}})
+	if min, max := g.Bounds(); min != (mgl64.Vec3{-2, 0, 0}) || max != (mgl64.Vec3{5, 4, 3}) {
+		t.Errorf("got bounds %v, %v, want [-2 0 0], [5 4 3]", min, max)
+	}
+	// Removing a voxel on the boundary shrinks the bounds, and adding one after extends the recomputed bounds.
+	g.Remove(mgl64.Vec3{4, 1, 2})
+	g.Add(mgl64.Vec3{0, -1, 0})
+	if min, max := g.Bounds(); min != (mgl64.Vec3{-2, -1, 0}) || max != (mgl64.Vec3{1, 4, 2}) {
+		t.Errorf("got bounds %v, %v, want [-2 -1 0], [1 4 2]", min, max)
+	}
+	g.Remove(mgl64.Vec3{0, 0, 0})
+	g.Remove(mgl64.Vec3{0, -1, 0})
+	g.Remove(mgl64.Vec3{-2, 3, 1})
+	if min, max := g.Bounds(); g.Len() != 0 || min != (mgl64.Vec3{}) || max != (mgl64.Vec3{}) {
+		t.Errorf("got %v voxels in bounds %v, %v, want an empty grid", g.Len(), min, max)
+	}
+}
+
+func TestSparseVoxelGridMarshalBinary(t *testing.T) {
+	g := NewSparseVoxelGrid([]mgl64.Vec3{{0, 0, 0}, {4, 1, 2}, {-2, 3, 1}})
+	data, err := g.MarshalBinary()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var decoded SparseVoxelGrid
+	if err := decoded.UnmarshalBinary(data); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if decoded.Len() != g.Len() {
+		t.Fatalf("got %v voxels, want %v", decoded.Len(), g.Len())
+	}
+	for pos := range g.voxels {
+		if !decoded.Contains(pos.Vec3Min()) {
+			t.Errorf("voxel %v missing after decoding", pos)
+		}
+	}
+	minA, maxA := g.Bounds()
+	if minB, maxB := decoded.Bounds(); minA != minB || maxA != maxB {
+		t.Errorf("got bounds %v, %v, want %v, %v", minB, maxB, minA, maxA)
+	}
+	if err := decoded.UnmarshalBinary(data[:len(data)-1]); err == nil {
+		t.Error("expected an error for truncated data")
+	}
+}
+
+func TestSparseVoxelGridWriteTo(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	occupied := make([]mgl64.Vec3, 500)
+	for i := range occupied {
+		occupied[i] = randomPoint(rng, 1000)
+	}
+	g := NewSparseVoxelGrid(occupied)
+	var buf bytes.Buffer
+	n, err := g.WriteTo(&buf)
+	if err != nil || n != int64(buf.Len()) {
+		t.Fatalf("got %v, %v, want %v bytes written", n, err, buf.Len())
+	}
+	// The byte versions encode the same data, which may be loaded into an OccupancyMap too.
+	data, _ := g.MarshalBinary()
+	if !bytes.Equal(data, buf.Bytes()) {
+		t.Errorf("MarshalBinary and WriteTo encode different data")
+	}
+	var m OccupancyMap
+	if err := m.UnmarshalBinary(data); err != nil || m.Len() != g.Len() {
+		t.Errorf("got %v voxels, %v decoding into an OccupancyMap, want %v", m.Len(), err, g.Len())
+	}
+
+	decoded := NewSparseVoxelGrid([]mgl64.Vec3{{9999, 9999, 9999}})
+	n, err = decoded.ReadFrom(bytes.NewReader(data))
+	if err != nil || n != int64(len(data)) {
+		t.Fatalf("got %v, %v, want %v bytes read", n, err, len(data))
+	}
+	if decoded.Len() != g.Len() || decoded.Contains(mgl64.Vec3{9999, 9999, 9999}) {
+		t.Fatalf("got %v voxels, want the %v voxels read to replace the grid", decoded.Len(), g.Len())
+	}
+	for _, v := range occupied {
+		if !decoded.Contains(v) {
+			t.Fatalf("voxel %v missing after reading", v)
+		}
+	}
+}
+
+// errReader is an io.Reader that fails after returning the data it holds.
+type errReader struct {
+	data []byte
+	err  error
+}
+
+// Read reads the data of the reader, returning its error once all data was read.
+func (r *errReader) Read(p []byte) (int, error) {
+	if len(r.data) == 0 {
+		return 0, r.err
+	}
+	n := copy(p, r.data)
+	r.data = r.data[n:]
+	return n, nil
+}
+
+func TestSparseVoxelGridReadFromInvalid(t *testing.T) {
+	g := NewSparseVoxelGrid([]mgl64.Vec3{{0, 0, 0}, {4, 1, 2}, {-2, 3, 1}, {-2, 3, 2}})
+	data, _ := g.MarshalBinary()
+
+	// Every truncation of valid data is rejected, and a failed read leaves the grid as it was.
+	invalid := [][]byte{nil, []byte("VXOM"), []byte("VXOM\x02"), []byte("VXOM\x01\xff\xff\xff\xff\xff\xff\xff\xff\xff\x01")}
+	for i := range data {
+		invalid = append(invalid, data[:i])
+	}
+	invalid = append(invalid, append(append([]byte(nil), data...), 0))
+	for _, input := range invalid {
+		decoded := NewSparseVoxelGrid([]mgl64.Vec3{{7, 7, 7}})
+		if _, err := decoded.ReadFrom(bytes.NewReader(input)); err == nil {
+			t.Fatalf("%x: expected an error", input)
+		}
+		if decoded.Len() != 1 || !decoded.Contains(mgl64.Vec3{7, 7, 7}) {
+			t.Fatalf("%x: grid changed by a failed read", input)
+		}
+		if err := decoded.UnmarshalBinary(input); err == nil {
+			t.Fatalf("%x: UnmarshalBinary succeeded where ReadFrom failed", input)
+		}
+	}
+
+	// Data with any single byte changed may still decode to other voxels, but must never make the grid panic.
+	for i := range data {
+		for _, b := range []byte{0x00, 0x01, 0x7f, 0x80, 0xff} {
+			corrupt := append([]byte(nil), data...)
+			corrupt[i] = b
+			var decoded SparseVoxelGrid
+			if _, err := decoded.ReadFrom(bytes.NewReader(corrupt)); err == nil {
+				decoded.Bounds()
+			}
+		}
+	}
+
+	readErr := errors.New("connection reset")
+	if _, err := g.ReadFrom(&errReader{data: data[:5], err: readErr}); !errors.Is(err, readErr) {
+		t.Errorf("got %v, want the error of the reader", err)
+	}
+}
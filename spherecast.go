@@ -0,0 +1,124 @@
+package voxelraytrace
+
+import (
+	"errors"
+	"github.com/go-gl/mathgl/mgl64"
+	"math"
+	"sort"
+)
+
+// SphereCast returns the positions of all voxels that lie within the radius passed of the segment between the start
+// and end coordinates, which are the voxels touched by a sphere moving along the segment, such as a projectile with a
+// hitbox or a thick laser beam. A voxel is included if the distance between the segment and any point of the voxel,
+// including its surface, is at most the radius, so a radius of 0 includes every voxel touched by the segment. The
+// voxels are ordered by the distance along the ray of their centre, with ties in ascending X, Y, Z order. If the start
+// and end are the same, the voxels within the radius of the start are returned. An error is returned if the radius is
+// negative.
+func SphereCast(start, end mgl64.Vec3, radius float64) ([]BlockPos, error) {
+	if !(radius >= 0) {
+		return nil, errors.New("sphere cast radius must not be negative")
+	}
+	reach := int(math.Ceil(radius)) + 1
+	var candidates []BlockPos
+	if start == end {
+		var set VisitedSet
+		addNeighbourhood(&set, BlockPosFromVec3(start), reach)
+		candidates = set.Ordered()
+	} else {
+		t, err := newTracer(start, end, newConfig(nil))
+		if err != nil {
+			return nil, err
+		}
+		candidates = pathNeighbourhood(&t, reach)
+	}
+
+	var voxels []BlockPos
+	for _, pos := range candidates {
+		if segmentBoxDistanceSqr(start, end, pos.Vec3Min(), pos.Add(BlockPos{1, 1, 1}).Vec3Min()) <= radius*radius {
+			voxels = append(voxels, pos)
+		}
+	}
+	dir := end.Sub(start)
+	sort.SliceStable(voxels, func(i, j int) bool {
+		di, dj := voxels[i].Vec3Centre().Sub(start).Dot(dir), voxels[j].Vec3Centre().Sub(start).Dot(dir)
+		if di != dj {
+			return di < dj
+		}
+		return lessBlockPos(voxels[i], voxels[j])
+	})
+	return voxels, nil
+}
+
+// pathNeighbourhood returns the positions of all voxels within reach voxels on every axis of a voxel passed through
+// by the ray of the tracer passed, from the voxel it is at onwards, without duplicates. As every point within a
+// distance of the ray lies within that distance of a point in a voxel on the path, this holds all voxels near the
+// ray.
+func pathNeighbourhood(t *tracer, reach int) []BlockPos {
+	var set VisitedSet
+	for {
+		addNeighbourhood(&set, t.pos(), reach)
+		if !t.next() {
+			return set.Ordered()
+		}
+	}
+}
+
+// addNeighbourhood adds the positions of all voxels within reach voxels on every axis of the position passed to the
+// VisitedSet.
+func addNeighbourhood(set *VisitedSet, pos BlockPos, reach int) {
+	for x := -reach; x <= reach; x++ {
+		for y := -reach; y <= reach; y++ {
+			for z := -reach; z <= reach; z++ {
+				set.AddPos(pos.Add(BlockPos{x, y, z}))
+			}
+		}
+	}
+}
+
+// segmentBoxDistanceSqr returns the squared distance between the segment from a to b and the box spanning from min to
+// max. The squared distance from a point moving along the segment to the box is a quadratic function of the position
+// along the segment between the points at which it crosses the planes of the box, so it is minimised exactly on each
+// of these pieces.
+func segmentBoxDistanceSqr(a, b, min, max mgl64.Vec3) float64 {
+	d := b.Sub(a)
+	breaks := []float64{0, 1}
+	for i := 0; i < 3; i++ {
+		if d[i] == 0 {
+			continue
+		}
+		for _, plane := range [2]float64{min[i], max[i]} {
+			if t := (plane - a[i]) / d[i]; t > 0 && t < 1 {
+				breaks = append(breaks, t)
+			}
+		}
+	}
+	sort.Float64s(breaks)
+
+	best := math.Inf(1)
+	for k := 0; k+1 < len(breaks); k++ {
+		lo, hi := breaks[k], breaks[k+1]
+		mid := (lo + hi) / 2
+		// On this piece, every axis is either inside the slab of the box, contributing nothing, or on one side of it,
+		// contributing the square of (a[i] - plane) + d[i]*t.
+		var cc, cm, mm float64
+		for i := 0; i < 3; i++ {
+			p := a[i] + d[i]*mid
+			var c float64
+			switch {
+			case p < min[i]:
+				c = a[i] - min[i]
+			case p > max[i]:
+				c = a[i] - max[i]
+			default:
+				continue
+			}
+			cc, cm, mm = cc+c*c, cm+c*d[i], mm+d[i]*d[i]
+		}
+		t := lo
+		if mm > 0 {
+			t = math.Max(lo, math.Min(hi, -cm/mm))
+		}
+		best = math.Min(best, cc+2*cm*t+mm*t*t)
+	}
+	return math.Max(best, 0)
+}
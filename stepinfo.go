@@ -0,0 +1,60 @@
+package voxelraytrace
+
+import (
+	"github.com/go-gl/mathgl/mgl64"
+	"math"
+)
+
+// StepInfo holds everything known about a single voxel passed through by a ray. It is mostly useful for debugging
+// traversals and visualising them, as collecting it is more expensive than the voxel positions alone.
+type StepInfo struct {
+	// Voxel is the position of the voxel.
+	Voxel BlockPos
+	// Axis is the axis that was stepped along to enter the voxel. It is AxisNone for the voxel the ray starts in.
+	Axis Axis
+	// EntryFace is the face through which the voxel was entered. It is FaceNone for the voxel the ray starts in.
+	EntryFace FaceID
+	// TEntry and TExit are the distances along the ray at which it enters and leaves the voxel. TEntry is 0 for the
+	// voxel the ray starts in and TExit is the length of the ray for the voxel it ends in.
+	TEntry, TExit float64
+	// TMax holds the distances along the ray at which the next boundary is crossed on every axis, as kept by the
+	// traversal when the voxel was entered.
+	TMax [3]float64
+	// Step holds the direction in which the traversal steps on every axis: -1, 0 or 1.
+	Step [3]int
+}
+
+// ChordLength returns the length of the part of the ray that lies inside the voxel.
+func (s StepInfo) ChordLength() float64 {
+	return s.TExit - s.TEntry
+}
+
+// BetweenPointsWithStepInfo performs a ray trace between the start and end coordinates like BetweenPoints, but
+// returns a StepInfo for every voxel passed through.
+func BetweenPointsWithStepInfo(start, end mgl64.Vec3, opts ...Option) (steps []StepInfo, err error) {
+	t, err := newTracer(start, end, newConfig(opts))
+	if err != nil {
+		return nil, err
+	}
+	for {
+		steps = append(steps, t.stepInfo())
+		if !t.next() {
+			return steps, t.err
+		}
+	}
+}
+
+// stepInfo returns a StepInfo holding the current state of the tracer.
+func (t *tracer) stepInfo() StepInfo {
+	axis, _ := t.face.axis()
+	exit, _ := t.peek()
+	return StepInfo{
+		Voxel:     t.pos(),
+		Axis:      axis,
+		EntryFace: t.face,
+		TEntry:    t.t,
+		TExit:     math.Min(exit, t.radius),
+		TMax:      [3]float64{t.tMaxX, t.tMaxY, t.tMaxZ},
+		Step:      [3]int{t.stepX, t.stepY, t.stepZ},
+	}
+}
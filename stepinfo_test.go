@@ -0,0 +1,70 @@
+package voxelraytrace
+
+import (
+	"github.com/go-gl/mathgl/mgl64"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestStepHookInvariants(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 5000; i++ {
+		start, end := randomPoint(rng, 8), randomPoint(rng, 8)
+		var steps []StepInfo
+		positions, err := BetweenPointsInt(start, end, WithStepHook(func(s StepInfo) {
+			steps = append(steps, s)
+		}))
+		if err != nil {
+			continue
+		}
+		if len(steps) != len(positions) {
+			t.Fatalf("trace %v -> %v: hook called %v times for %v voxels", start, end, len(steps), len(positions))
+		}
+		withInfo, _ := BetweenPointsWithStepInfo(start, end)
+		if !reflect.DeepEqual(withInfo, steps) {
+			t.Fatalf("trace %v -> %v: got %v from the hook, want %v", start, end, steps, withInfo)
+		}
+		for j, s := range steps {
+			if s.Voxel != positions[j] {
+				t.Fatalf("trace %v -> %v: step %v in %v, want %v", start, end, j, s.Voxel, positions[j])
+			}
+			if s.TEntry > s.TExit {
+				t.Fatalf("trace %v -> %v: step %v leaves the voxel at %v before entering it at %v", start, end, j, s.TExit, s.TEntry)
+			}
+			if j == 0 {
+				if s.Axis != AxisNone || s.EntryFace != FaceNone || s.TEntry != 0 {
+					t.Fatalf("trace %v -> %v: got %+v for the first voxel", start, end, s)
+				}
+				continue
+			}
+			prev := steps[j-1]
+			if s.TEntry != prev.TExit {
+				t.Fatalf("trace %v -> %v: step %v entered at %v, but the voxel before was left at %v", start, end, j, s.TEntry, prev.TExit)
+			}
+			axis := int(s.Axis) - 1
+			if axis < 0 || s.Voxel[axis]-prev.Voxel[axis] != s.Step[axis] || s.EntryFace != axisEntryFace(s.Step[axis], axis) {
+				t.Fatalf("trace %v -> %v: step %v from %v to %v reported along axis %v through %v", start, end, j, prev.Voxel, s.Voxel, s.Axis, s.EntryFace)
+			}
+			for k := 0; k < 3; k++ {
+				if s.TMax[k] < prev.TMax[k] || s.Step[k] != prev.Step[k] {
+					t.Fatalf("trace %v -> %v: step %v has tMax %v and steps %v after %v and %v", start, end, j, s.TMax, s.Step, prev.TMax, prev.Step)
+				}
+			}
+		}
+	}
+}
+
+func BenchmarkStepHook(b *testing.B) {
+	b.Run("none", func(b *testing.B) {
+		benchmarkTraversal(b, func(start, end mgl64.Vec3) {
+			_, _ = BetweenPointsInt(start, end)
+		})
+	})
+	b.Run("set", func(b *testing.B) {
+		hook := WithStepHook(func(StepInfo) {})
+		benchmarkTraversal(b, func(start, end mgl64.Vec3) {
+			_, _ = BetweenPointsInt(start, end, hook)
+		})
+	})
+}
@@ -0,0 +1,38 @@
+package voxelraytrace
+
+import (
+	"errors"
+	"github.com/go-gl/mathgl/mgl64"
+	"math"
+)
+
+// maxPreallocSteps is the largest amount of voxels that InDirectionSteps allocates space for upfront, so that a very
+// large maxSteps does not allocate more than needed if the trace is stopped early by an Option.
+const maxPreallocSteps = 4096
+
+// InDirectionSteps performs a ray trace from the start position in the given direction, for a maximum amount of
+// steps between voxels rather than a maximum distance. It returns the positions of at most maxSteps+1 voxels: the
+// voxel the ray starts in and one for every step. Every step moves to a voxel sharing a face with the previous one,
+// so a diagonal ray covers a shorter distance than an axis-aligned one with the same amount of steps.
+// A maxSteps of 0 returns only the voxel the ray starts in, and a negative maxSteps returns an error.
+func InDirectionSteps(start, dir mgl64.Vec3, maxSteps int, opts ...Option) ([]BlockPos, error) {
+	if maxSteps < 0 {
+		return nil, errors.New("max steps must not be negative")
+	}
+	t, err := newTracer(start, start.Add(dir), newConfig(opts))
+	if err != nil {
+		return nil, err
+	}
+	t.setRadius(math.Inf(1))
+
+	n := maxSteps + 1
+	if n > maxPreallocSteps {
+		n = maxPreallocSteps
+	}
+	positions := make([]BlockPos, 1, n)
+	positions[0] = t.pos()
+	for i := 0; i < maxSteps && t.next(); i++ {
+		positions = append(positions, t.pos())
+	}
+	return positions, t.err
+}
@@ -0,0 +1,73 @@
+package voxelraytrace
+
+import (
+	"github.com/go-gl/mathgl/mgl64"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestInDirectionSteps(t *testing.T) {
+	start := mgl64.Vec3{0.5, 0.5, 0.5}
+	tests := []struct {
+		name string
+		dir  mgl64.Vec3
+		last BlockPos
+	}{
+		// Eight steps along an axis cover eight units, but the same eight steps cover only about 5.7 units along a
+		// diagonal in two dimensions and 4.6 units along one in three, as every step crosses a single face.
+		{name: "axis aligned", dir: mgl64.Vec3{1, 0, 0}, last: BlockPos{8, 0, 0}},
+		{name: "diagonal", dir: mgl64.Vec3{1, 1, 0}, last: BlockPos{4, 4, 0}},
+		{name: "diagonal backwards", dir: mgl64.Vec3{-1, 0, -1}, last: BlockPos{-4, 0, -4}},
+		{name: "space diagonal", dir: mgl64.Vec3{1, 1, 1.0000001}, last: BlockPos{2, 3, 3}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			positions, err := InDirectionSteps(start, test.dir, 8)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(positions) != 9 {
+				t.Fatalf("got %v voxels, want 9", len(positions))
+			}
+			if positions[0] != (BlockPos{}) || positions[8] != test.last {
+				t.Errorf("got %v, want a path from %v to %v", positions, BlockPos{}, test.last)
+			}
+			for i := 1; i < len(positions); i++ {
+				if d := manhattan(positions[i-1], positions[i]); d != 1 {
+					t.Fatalf("step %v from %v to %v is not between neighbouring voxels", i, positions[i-1], positions[i])
+				}
+			}
+		})
+	}
+
+	positions, err := InDirectionSteps(start, mgl64.Vec3{1, 1, 0}, 0)
+	if err != nil || !reflect.DeepEqual(positions, []BlockPos{{}}) {
+		t.Errorf("got %v, %v for zero steps, want only the start voxel", positions, err)
+	}
+	if _, err := InDirectionSteps(start, mgl64.Vec3{1, 0, 0}, -1); err == nil {
+		t.Error("expected an error for a negative amount of steps")
+	}
+	if _, err := InDirectionSteps(start, mgl64.Vec3{}, 8); err != ErrZeroDirection {
+		t.Errorf("got %v for a zero direction, want %v", err, ErrZeroDirection)
+	}
+}
+
+func TestInDirectionStepsMatchesTrace(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 2000; i++ {
+		// The direction is not rounded like the start, as the voxel stepped to first is ambiguous when the ray crosses
+		// several boundaries at once, which rounding the direction makes likely.
+		start, dir := randomPoint(rng, 8), mgl64.Vec3{rng.Float64()*2 - 1, rng.Float64()*2 - 1, rng.Float64()*2 - 1}
+		steps := rng.Intn(20)
+		positions, err := InDirectionSteps(start, dir, steps)
+		if err != nil {
+			t.Fatalf("trace %v along %v: unexpected error: %v", start, dir, err)
+		}
+		// A trace long enough to take more steps than requested passes through the same voxels first.
+		want, _ := BetweenPointsInt(start, start.Add(dir.Normalize().Mul(float64(steps+2))))
+		if !reflect.DeepEqual(positions, want[:steps+1]) {
+			t.Fatalf("trace %v along %v for %v steps: got %v, want %v", start, dir, steps, positions, want[:steps+1])
+		}
+	}
+}
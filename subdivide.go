@@ -0,0 +1,25 @@
+package voxelraytrace
+
+import (
+	"errors"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// SubdividedTrace performs a ray trace between the start and end coordinates on a grid in which every voxel is split
+// into subdivisions parts on every axis. The coordinates returned are those of the minimum corners of the sub-voxels
+// passed through, so they are multiples of 1/subdivisions. A subdivisions value of 1 produces the same result as
+// BetweenPoints.
+func SubdividedTrace(start, end mgl64.Vec3, subdivisions int) ([]mgl64.Vec3, error) {
+	if subdivisions <= 0 {
+		return nil, errors.New("subdivisions must be positive")
+	}
+	scale := float64(subdivisions)
+	vectors, err := BetweenPoints(start.Mul(scale), end.Mul(scale))
+	if err != nil {
+		return nil, err
+	}
+	for i, v := range vectors {
+		vectors[i] = mgl64.Vec3{v[0] / scale, v[1] / scale, v[2] / scale}
+	}
+	return vectors, nil
+}
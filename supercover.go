@@ -0,0 +1,71 @@
+package voxelraytrace
+
+import (
+	"sort"
+)
+
+// supercover calls fn with the voxels passed through by the ray of the tracer, starting with the voxel it is at, like
+// stepping it using next. Where the ray crosses the boundaries of several axes at the same point, which is an edge or
+// corner of a voxel, fn is called with every voxel touching that point beyond the voxel before it, ordered as
+// described in WithSupercover, rather than with only those stepped through by next.
+func (t *tracer) supercover(fn func(pos BlockPos)) {
+	fn(t.pos())
+	for {
+		from, key := t.pos(), t.nextKey()
+		if !t.next() {
+			return
+		}
+		if t.nextKey() != key {
+			fn(t.pos())
+			continue
+		}
+		// The crossings of the other axes at the same point are stepped as well, after which every combination of the
+		// axes stepped leads to a voxel touching the point.
+		for t.nextKey() == key && t.next() {
+		}
+		var axes []int
+		for i, pos := 0, t.pos(); i < 3; i++ {
+			if pos[i] != from[i] {
+				axes = append(axes, i)
+			}
+		}
+		touched := make([]BlockPos, 0, 7)
+		for mask := 1; mask < 1<<uint(len(axes)); mask++ {
+			pos := from
+			for i, axis := range axes {
+				if mask&(1<<uint(i)) != 0 {
+					pos[axis] = t.pos()[axis]
+				}
+			}
+			touched = append(touched, pos)
+		}
+		sort.Slice(touched, func(i, j int) bool {
+			ci, cj := manhattan(touched[i], from), manhattan(touched[j], from)
+			if ci != cj {
+				return ci < cj
+			}
+			// The order of voxels at the same distance is reversed for a reversed line, so that tracing the ray in
+			// the opposite direction passes through the voxels in reverse order.
+			return lessBlockPos(touched[i], touched[j]) != t.line.reversed
+		})
+		for _, pos := range touched {
+			fn(pos)
+		}
+	}
+}
+
+// nextKey returns the order key of the boundary crossing at which the tracer steps next.
+func (t *tracer) nextKey() float64 {
+	switch t.nextAxis() {
+	case 0:
+		return t.keyX
+	case 1:
+		return t.keyY
+	}
+	return t.keyZ
+}
+
+// manhattan returns the Manhattan distance between the voxels a and b.
+func manhattan(a, b BlockPos) int {
+	return absInt(a[0]-b[0]) + absInt(a[1]-b[1]) + absInt(a[2]-b[2])
+}
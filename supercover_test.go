@@ -0,0 +1,79 @@
+package voxelraytrace
+
+import (
+	"github.com/go-gl/mathgl/mgl64"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestSupercover(t *testing.T) {
+	tests := []struct {
+		name       string
+		start, end mgl64.Vec3
+		want       []BlockPos
+	}{
+		{
+			name:  "edge",
+			start: mgl64.Vec3{0.5, 0.5, 0.5}, end: mgl64.Vec3{1.5, 1.5, 0.5},
+			want: []BlockPos{{0, 0, 0}, {0, 1, 0}, {1, 0, 0}, {1, 1, 0}},
+		},
+		{
+			name:  "edge reversed",
+			start: mgl64.Vec3{1.5, 1.5, 0.5}, end: mgl64.Vec3{0.5, 0.5, 0.5},
+			want: []BlockPos{{1, 1, 0}, {1, 0, 0}, {0, 1, 0}, {0, 0, 0}},
+		},
+		{
+			name:  "corner",
+			start: mgl64.Vec3{0.5, 0.5, 0.5}, end: mgl64.Vec3{1.5, 1.5, 1.5},
+			want: []BlockPos{
+				{0, 0, 0},
+				{0, 0, 1}, {0, 1, 0}, {1, 0, 0},
+				{0, 1, 1}, {1, 0, 1}, {1, 1, 0},
+				{1, 1, 1},
+			},
+		},
+		{
+			name:  "no crossings at edges",
+			start: mgl64.Vec3{0.5, 0.25, 0.5}, end: mgl64.Vec3{1.5, 0.75, 0.5},
+			want: []BlockPos{{0, 0, 0}, {1, 0, 0}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := BetweenPointsInt(test.start, test.end, WithSupercover())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestSupercoverSymmetric(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for _, opts := range [][]Option{{WithSupercover()}, {WithSupercover(), WithUpAxis(AxisZ)}} {
+		for i := 0; i < 20000; i++ {
+			var start, end mgl64.Vec3
+			for j := 0; j < 3; j++ {
+				start[j], end[j] = float64(rng.Intn(17)-8)/2, float64(rng.Intn(17)-8)/2
+			}
+			if start == end {
+				continue
+			}
+			forward, err := BetweenPointsInt(start, end, opts...)
+			if err != nil {
+				t.Fatalf("trace %v -> %v: unexpected error: %v", start, end, err)
+			}
+			backward, _ := BetweenPointsInt(end, start, opts...)
+			for j, k := 0, len(backward)-1; j < k; j, k = j+1, k-1 {
+				backward[j], backward[k] = backward[k], backward[j]
+			}
+			if !reflect.DeepEqual(forward, backward) {
+				t.Fatalf("trace %v -> %v: got %v, reversed trace gives %v", start, end, forward, backward)
+			}
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package voxelraytrace
+
+import (
+	"github.com/go-gl/mathgl/mgl64"
+	"math"
+	"sort"
+)
+
+// SweepAABB calls fn for every voxel overlapped by the box passed while it moves by the movement vector, such as the
+// collision box of an entity moving through the world, in the order in which the box first overlaps them, until fn
+// returns false. t is the fraction of the movement, between 0 and 1, at which the box first overlaps the voxel, which
+// is 0 for voxels the box overlaps before moving. Ties are ordered by ascending X, Y and Z. Voxels that the box only
+// touches without overlapping them, such as the voxel directly below a box standing on the ground, are left out.
+func SweepAABB(box AABB, movement mgl64.Vec3, fn func(pos BlockPos, t float64) bool) {
+	// Every voxel overlapped by the box at some point lies within the extent of the box from the voxel containing the
+	// minimum corner of the box at that point, which is found by tracing the minimum corner along the movement. One
+	// extra voxel is included on every side to account for corners lying exactly on voxel boundaries.
+	var extent BlockPos
+	for i := range extent {
+		extent[i] = int(math.Ceil(box.Max[i]-box.Min[i])) + 1
+	}
+	var candidates VisitedSet
+	addAround := func(pos BlockPos) {
+		for x := -1; x <= extent[0]; x++ {
+			for y := -1; y <= extent[1]; y++ {
+				for z := -1; z <= extent[2]; z++ {
+					candidates.AddPos(pos.Add(BlockPos{x, y, z}))
+				}
+			}
+		}
+	}
+	if t, err := newTracer(box.Min, box.Min.Add(movement), newConfig(nil)); err != nil {
+		addAround(BlockPosFromVec3(box.Min))
+	} else {
+		for {
+			addAround(t.pos())
+			if !t.next() {
+				break
+			}
+		}
+	}
+
+	type contact struct {
+		pos BlockPos
+		t   float64
+	}
+	var contacts []contact
+	for _, pos := range candidates.Ordered() {
+		if t, ok := sweepEntry(box, movement, pos); ok {
+			contacts = append(contacts, contact{pos: pos, t: t})
+		}
+	}
+	sort.Slice(contacts, func(i, j int) bool {
+		if contacts[i].t != contacts[j].t {
+			return contacts[i].t < contacts[j].t
+		}
+		return lessBlockPos(contacts[i].pos, contacts[j].pos)
+	})
+	for _, c := range contacts {
+		if !fn(c.pos, c.t) {
+			return
+		}
+	}
+}
+
+// sweepEntry returns the fraction of the movement at which the box passed, moving by the movement vector, first
+// overlaps the voxel at the position passed. False is returned if it never does.
+func sweepEntry(box AABB, movement mgl64.Vec3, pos BlockPos) (float64, bool) {
+	entry, exit := 0.0, 1.0
+	for i := 0; i < 3; i++ {
+		lower, upper := float64(pos[i]), float64(pos[i]+1)
+		if movement[i] == 0 {
+			if box.Max[i] <= lower || box.Min[i] >= upper {
+				return 0, false
+			}
+			continue
+		}
+		// The box overlaps the voxel on this axis strictly between the times t1 and t2.
+		t1, t2 := (lower-box.Max[i])/movement[i], (upper-box.Min[i])/movement[i]
+		if t1 > t2 {
+			t1, t2 = t2, t1
+		}
+		entry, exit = math.Max(entry, t1), math.Min(exit, t2)
+	}
+	return entry, entry < exit
+}
@@ -0,0 +1,86 @@
+// Package tracedebug records ray traces of voxelraytrace and exports them for inspection, such as when diagnosing why
+// a ray missed a block. A Trace may be written as JSON, to be loaded into a web viewer, or as a Wavefront OBJ mesh
+// holding a unit cube for every voxel passed through and a line for the ray, to be loaded into a tool like Blender.
+package tracedebug
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/go-gl/mathgl/mgl64"
+	"github.com/justtaldevelops/voxelraytrace"
+	"io"
+)
+
+// Trace is a recorded ray trace between two points.
+type Trace struct {
+	// Start and End are the points between which the ray was traced.
+	Start, End mgl64.Vec3
+	// Steps holds a StepInfo for every voxel passed through, in order.
+	Steps []voxelraytrace.StepInfo
+}
+
+// Record performs a ray trace between the start and end coordinates using voxelraytrace.BetweenPointsWithStepInfo and
+// returns it as a Trace. If the trace is stopped early by one of the Options passed, the Trace holds the voxels
+// passed through so far and is returned along with the error.
+func Record(start, end mgl64.Vec3, opts ...voxelraytrace.Option) (Trace, error) {
+	steps, err := voxelraytrace.BetweenPointsWithStepInfo(start, end, opts...)
+	return Trace{Start: start, End: end, Steps: steps}, err
+}
+
+// jsonTrace is the JSON representation of a Trace.
+type jsonTrace struct {
+	Start [3]float64 `json:"start"`
+	End   [3]float64 `json:"end"`
+	Steps []jsonStep `json:"steps"`
+}
+
+// jsonStep is the JSON representation of a single step of a Trace.
+type jsonStep struct {
+	X    int     `json:"x"`
+	Y    int     `json:"y"`
+	Z    int     `json:"z"`
+	Face string  `json:"face"`
+	T    float64 `json:"t"`
+	Exit float64 `json:"exit"`
+}
+
+// WriteJSON writes the trace to w as an indented JSON object holding the start and end of the ray as arrays and the
+// steps as an array of objects with the voxel coordinates, the face through which the voxel was entered and the
+// distances along the ray at which it was entered and left.
+func (tr Trace) WriteJSON(w io.Writer) error {
+	out := jsonTrace{Start: tr.Start, End: tr.End, Steps: make([]jsonStep, len(tr.Steps))}
+	for i, s := range tr.Steps {
+		out.Steps[i] = jsonStep{X: s.Voxel[0], Y: s.Voxel[1], Z: s.Voxel[2], Face: s.EntryFace.String(), T: s.TEntry, Exit: s.TExit}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
+
+// cubeFaces holds the vertices of the six faces of a cube, indexed from 1 into the eight corners of the cube ordered
+// like voxelraytrace.AABB.Corners.
+var cubeFaces = [6][4]int{{1, 2, 4, 3}, {5, 7, 8, 6}, {1, 5, 6, 2}, {3, 4, 8, 7}, {1, 3, 7, 5}, {2, 6, 8, 4}}
+
+// WriteOBJ writes the trace to w as a Wavefront OBJ mesh holding a unit cube for every voxel passed through, in the
+// order of the steps, followed by a line from the start to the end of the ray. The cubes are placed at the voxel
+// coordinates, so the line only passes through them for traces using the default grid, without Options such as
+// voxelraytrace.WithCellSize.
+func (tr Trace) WriteOBJ(w io.Writer) error {
+	for i, s := range tr.Steps {
+		for c := 0; c < 8; c++ {
+			if _, err := fmt.Fprintf(w, "v %d %d %d\n", s.Voxel[0]+c>>2&1, s.Voxel[1]+c>>1&1, s.Voxel[2]+c&1); err != nil {
+				return err
+			}
+		}
+		for _, f := range cubeFaces {
+			base := i * 8
+			if _, err := fmt.Fprintf(w, "f %d %d %d %d\n", base+f[0], base+f[1], base+f[2], base+f[3]); err != nil {
+				return err
+			}
+		}
+	}
+	base := len(tr.Steps) * 8
+	_, err := fmt.Fprintf(w, "v %v %v %v\nv %v %v %v\nl %d %d\n", tr.Start[0], tr.Start[1], tr.Start[2],
+		tr.End[0], tr.End[1], tr.End[2], base+1, base+2)
+	return err
+}
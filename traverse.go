@@ -0,0 +1,139 @@
+package voxelraytrace
+
+import (
+	"context"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// TraverseCallback is called by Traverse for every voxel passed through, with the voxel in the vector representation
+// returned by BetweenPoints, the distance along the ray at which it was entered and the face through which it was
+// entered. For the voxel the ray starts in, the distance is 0 and the face is FaceNone. Returning false stops the
+// traversal.
+type TraverseCallback func(voxel mgl64.Vec3, t float64, face FaceID) bool
+
+// Traverse performs a ray trace between the start and end coordinates and calls cb for every voxel passed through, in
+// the same order as BetweenPoints, until it returns false. Traverse does not allocate.
+func Traverse(start, end mgl64.Vec3, cb TraverseCallback, opts ...Option) error {
+	t, err := newTracer(start, end, newConfig(opts))
+	if err != nil {
+		return err
+	}
+	for cb(t.pos().Vec3Min(), t.t, t.face) && t.next() {
+	}
+	return t.err
+}
+
+// InDirectionTraverse performs a ray trace from the start position in the given direction, for a distance of the
+// maxDistance, and calls cb for every voxel passed through like Traverse.
+func InDirectionTraverse(start, directionVector mgl64.Vec3, maxDistance float64, cb TraverseCallback, opts ...Option) error {
+	return Traverse(start, along(start, directionVector, maxDistance), cb, opts...)
+}
+
+// TraverseFunc performs a ray trace between the start and end coordinates and calls visit for every voxel passed
+// through, in the same order as BetweenPoints, until it returns false. Unlike BetweenPoints, no slice of voxels is
+// built, so a trace that is stopped early only costs as much as the voxels visited. Traverse may be used to also
+// receive the distance and face at which every voxel was entered.
+func TraverseFunc(start, end mgl64.Vec3, visit func(voxel mgl64.Vec3) bool, opts ...Option) error {
+	t, err := newTracer(start, end, newConfig(opts))
+	if err != nil {
+		return err
+	}
+	for visit(t.pos().Vec3Min()) && t.next() {
+	}
+	return t.err
+}
+
+// TraverseContext performs a ray trace like TraverseFunc, but stops when the context passed is cancelled or reaches
+// its deadline, such as for map-wide scans that a user may abort. The context is checked before the first voxel is
+// visited and then every 64 voxels, as done by WithContext. If the trace is stopped, the error of the context is
+// returned.
+func TraverseContext(ctx context.Context, start, end mgl64.Vec3, visit func(voxel mgl64.Vec3) bool, opts ...Option) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return TraverseFunc(start, end, visit, append(opts[:len(opts):len(opts)], WithContext(ctx))...)
+}
+
+// streamBuffer is the size of the buffer of the channels returned by Stream.
+const streamBuffer = 64
+
+// Stream performs a ray trace between the start and end coordinates in a new goroutine and sends the voxels passed
+// through to the channel returned, in the same order as BetweenPoints, such as to pipe them into a chunk prefetcher.
+// The channel is buffered, so that the trace may run ahead of the receiver, and is closed once the trace ends, is
+// stopped early by one of the Options passed or the context passed is cancelled. The goroutine keeps running until
+// either happens, so the context must be cancelled if the receiver stops receiving early. An error is returned if the
+// trace cannot be started, in which case no goroutine is started.
+func Stream(ctx context.Context, start, end mgl64.Vec3, opts ...Option) (<-chan mgl64.Vec3, error) {
+	t, err := newTracer(start, end, newConfig(opts))
+	if err != nil {
+		return nil, err
+	}
+	ch := make(chan mgl64.Vec3, streamBuffer)
+	go func() {
+		defer close(ch)
+		for {
+			select {
+			case ch <- t.pos().Vec3Min():
+			case <-ctx.Done():
+				return
+			}
+			if !t.next() {
+				return
+			}
+		}
+	}()
+	return ch, nil
+}
+
+// TraverseOutward performs ray traces from the centre towards both a and b at once and calls cb for every voxel passed
+// through, in order of the distance from the centre at which it was entered, until it returns false, such as to find
+// the nearest occluder to the midpoint between two points. The distance passed to cb is measured from the centre, and
+// the face is the one through which the ray travelling away from the centre entered the voxel. The voxel holding the
+// centre is visited first, once. Voxels entered at the same distance on both sides are visited towards a first. Every
+// half is traced like Traverse, with the Options passed applying to each of them separately. As traces are symmetric,
+// a trace from end to start, rather than this function, may be used to visit voxels in reverse order.
+func TraverseOutward(center, a, b mgl64.Vec3, cb TraverseCallback, opts ...Option) error {
+	conf := newConfig(opts)
+	var (
+		halves [2]tracer
+		more   [2]bool
+	)
+	for i, end := range [2]mgl64.Vec3{a, b} {
+		// A half ending at the centre passes through no voxels other than the one holding it.
+		if end == center {
+			continue
+		}
+		t, err := newTracer(center, end, conf)
+		if err != nil {
+			return err
+		}
+		halves[i], more[i] = t, true
+	}
+	if !more[0] && !more[1] {
+		cb(VoxelAt(center, opts...).Vec3Min(), 0, FaceNone)
+		return nil
+	}
+	// A centre on a voxel boundary may lie in a different voxel for both halves, in which case both are visited.
+	if more[0] && !cb(halves[0].pos().Vec3Min(), 0, FaceNone) {
+		return nil
+	}
+	if more[1] && !(more[0] && halves[1].pos() == halves[0].pos()) && !cb(halves[1].pos().Vec3Min(), 0, FaceNone) {
+		return nil
+	}
+	more[0], more[1] = more[0] && halves[0].next(), more[1] && halves[1].next()
+	for more[0] || more[1] {
+		i := 1
+		if more[0] && (!more[1] || halves[0].t <= halves[1].t) {
+			i = 0
+		}
+		t := &halves[i]
+		if !cb(t.pos().Vec3Min(), t.t, t.face) {
+			return nil
+		}
+		more[i] = t.next()
+	}
+	if halves[0].err != nil {
+		return halves[0].err
+	}
+	return halves[1].err
+}
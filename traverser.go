@@ -0,0 +1,103 @@
+package voxelraytrace
+
+import (
+	"errors"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// Traverser walks the voxels passed through by a ray one at a time, so that the walk may be paused and resumed, for
+// example to interleave it with other work. Unlike BetweenPoints, it never holds more than the voxel it is at. A
+// Traverser is not safe for concurrent use.
+type Traverser struct {
+	t tracer
+	// started specifies if the voxel the ray starts in was returned by Next.
+	started bool
+}
+
+// NewTraverser creates a Traverser for a ray trace between the start and end coordinates. The voxels returned by Next
+// are the same as those returned by BetweenPoints.
+func NewTraverser(start, end mgl64.Vec3, opts ...Option) (*Traverser, error) {
+	t, err := newTracer(start, end, newConfig(opts))
+	if err != nil {
+		return nil, err
+	}
+	return &Traverser{t: t}, nil
+}
+
+// NewTraverserInDirection creates a Traverser for a ray trace from the start position in the given direction, for a
+// distance of the maxDistance. The direction vector need not be normalised.
+func NewTraverserInDirection(start, directionVector mgl64.Vec3, maxDistance float64, opts ...Option) (*Traverser, error) {
+	if sumSquares(directionVector[0], directionVector[1], directionVector[2]) <= 0 {
+		return nil, ErrZeroDirection
+	}
+	if !(maxDistance >= 0) {
+		return nil, errors.New("ray max distance must not be negative")
+	}
+	if !finite(start) || !finite(directionVector) {
+		return nil, ErrNonFiniteInput
+	}
+	conf := newConfig(opts)
+	return &Traverser{t: newUnitTracer(start, conf.order.normalize(directionVector), maxDistance, conf)}, nil
+}
+
+// Next returns the coordinates of the next voxel passed through by the ray, starting with the voxel the ray starts in.
+// If the ray ended or was stopped early by one of the Options passed, false is returned. Err may be used to tell
+// these apart.
+func (tr *Traverser) Next() (mgl64.Vec3, bool) {
+	if !tr.started {
+		tr.started = true
+		return tr.t.pos().Vec3Min(), true
+	}
+	if !tr.t.next() {
+		return mgl64.Vec3{}, false
+	}
+	return tr.t.pos().Vec3Min(), true
+}
+
+// NextPos returns the position of the next voxel passed through by the ray like Next, but as a BlockPos, which may
+// be used to index voxel storage directly.
+func (tr *Traverser) NextPos() (BlockPos, bool) {
+	if !tr.started {
+		tr.started = true
+		return tr.t.pos(), true
+	}
+	if !tr.t.next() {
+		return BlockPos{}, false
+	}
+	return tr.t.pos(), true
+}
+
+// Face returns the face through which the ray entered the voxel last returned by Next or NextPos, such as to place a
+// block against it. It is FaceNone for the voxel the ray starts in.
+func (tr *Traverser) Face() Face {
+	return tr.t.face
+}
+
+// Reset makes the Traverser start over with a ray trace between the start and end coordinates, as if it was created
+// anew using NewTraverser, so that it may be reused without allocating. The Options used before are not kept. If an
+// error is returned, the Traverser is left unchanged.
+func (tr *Traverser) Reset(start, end mgl64.Vec3, opts ...Option) error {
+	t, err := newTracer(start, end, newConfig(opts))
+	if err != nil {
+		return err
+	}
+	tr.t, tr.started = t, false
+	return nil
+}
+
+// Err returns the error that stopped the ray early, if any.
+func (tr *Traverser) Err() error {
+	return tr.t.err
+}
+
+// Extend makes the ray longer by the extra distance passed, so that Next continues with the voxels beyond the
+// previous end of the ray, even if it already returned false. The state of the traversal is kept, so no voxel is
+// skipped or returned twice. Extending a Traverser created using NewTraverserInDirection, in any amount of steps,
+// returns exactly the same voxels as creating it with the total distance at once, unless a previous end of the ray
+// lay exactly on an edge or corner of a voxel: the ray then first steps into the voxel containing that end, which a
+// longer ray may pass by. Extend has no effect if the extra distance is not positive.
+func (tr *Traverser) Extend(extraDistance float64) {
+	if extraDistance > 0 {
+		tr.t.extend(extraDistance)
+	}
+}
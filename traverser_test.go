@@ -0,0 +1,56 @@
+package voxelraytrace
+
+import (
+	"github.com/go-gl/mathgl/mgl64"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+// drain returns the positions of all voxels left to be returned by the Traverser passed.
+func drain(tr *Traverser) []BlockPos {
+	positions := []BlockPos{}
+	for pos, ok := tr.NextPos(); ok; pos, ok = tr.NextPos() {
+		positions = append(positions, pos)
+	}
+	return positions
+}
+
+func TestTraverserExtend(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 2000; i++ {
+		// The ray is not rounded, so that none of the ends of the increments lies on an edge or corner of a voxel.
+		start, dir, _ := randomUnitRay(rng)
+		want, err := NewTraverserInDirection(start, dir, 10)
+		if err != nil {
+			t.Fatalf("trace %v along %v: unexpected error: %v", start, dir, err)
+		}
+		tr, _ := NewTraverserInDirection(start, dir, 1)
+		got := drain(tr)
+		for j := 1; j < 10; j++ {
+			tr.Extend(1)
+			got = append(got, drain(tr)...)
+		}
+		if wantPositions := drain(want); !reflect.DeepEqual(got, wantPositions) {
+			t.Fatalf("trace %v along %v: got %v in ten increments, want %v", start, dir, got, wantPositions)
+		}
+	}
+}
+
+func TestTraverserExtendBetweenPoints(t *testing.T) {
+	tr, err := NewTraverser(mgl64.Vec3{0.5, 0.5, 0.5}, mgl64.Vec3{2.5, 0.5, 0.5})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got := drain(tr)
+	// Extending by a distance that is not positive has no effect.
+	tr.Extend(0)
+	tr.Extend(-1)
+	got = append(got, drain(tr)...)
+	tr.Extend(2)
+	got = append(got, drain(tr)...)
+	want := []BlockPos{{0, 0, 0}, {1, 0, 0}, {2, 0, 0}, {3, 0, 0}, {4, 0, 0}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
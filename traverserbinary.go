@@ -0,0 +1,157 @@
+package voxelraytrace
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// traverserMagic is the magic at the start of a Traverser encoded using MarshalBinary.
+const traverserMagic = "VXTR"
+
+// traverserVersion is the current version of the binary format of a Traverser.
+const traverserVersion = 5
+
+// traverserState is the state of a Traverser as encoded by MarshalBinary. All fields have a fixed size, so that it
+// may be written and read using encoding/binary.
+type traverserState struct {
+	Started bool
+
+	Start, Dir, Offset  [3]float64
+	Cell                [3]float64
+	X, Y, Z             int64
+	StepX, StepY, StepZ int8
+	TMax, Key           [3]float64
+
+	Origin, Far, LineDir [3]float64
+	Length               float64
+	Reversed, Directed   bool
+
+	XBeforeY, XBeforeZ, YBeforeZ bool
+	FaceX, FaceY, FaceZ, Face    uint8
+
+	T, Radius float64
+	Limit     [3]float64
+	Ceil      bool
+	Toward    bool
+
+	Visited, MaxVoxels int64
+	RangeSet           [3]bool
+	RangeMin, RangeMax [3]int64
+	StartVoxel         [3]int64
+	MaxManhattan       int64
+	Wrap               [3]int64
+}
+
+// MarshalBinary encodes the full state of the Traverser, so that a Traverser decoded from it using UnmarshalBinary
+// returns exactly the same voxels as this one from this point on. The format starts with the four byte magic "VXTR"
+// and a version byte, followed by the state as fixed size little-endian values. The time budget, context and step
+// hook set using Options cannot be encoded and are not kept.
+func (tr *Traverser) MarshalBinary() ([]byte, error) {
+	t := tr.t
+	s := traverserState{
+		Started: tr.started,
+
+		Start: t.start, Dir: t.dir, Offset: t.offset, Cell: t.cell,
+		X: int64(t.x), Y: int64(t.y), Z: int64(t.z),
+		StepX: int8(t.stepX), StepY: int8(t.stepY), StepZ: int8(t.stepZ),
+		TMax: [3]float64{t.tMaxX, t.tMaxY, t.tMaxZ},
+		Key:  [3]float64{t.keyX, t.keyY, t.keyZ},
+
+		Origin: t.line.origin, Far: t.line.far, LineDir: t.line.dir,
+		Length:   t.line.length,
+		Reversed: t.line.reversed, Directed: t.line.directed,
+
+		XBeforeY: t.xBeforeY, XBeforeZ: t.xBeforeZ, YBeforeZ: t.yBeforeZ,
+		FaceX: uint8(t.faceX), FaceY: uint8(t.faceY), FaceZ: uint8(t.faceZ), Face: uint8(t.face),
+
+		T: t.t, Radius: t.radius,
+		Limit: [3]float64{t.limitX, t.limitY, t.limitZ},
+		Ceil:  t.ceil, Toward: t.toward,
+
+		Visited: int64(t.visited), MaxVoxels: int64(t.maxVoxels),
+		StartVoxel:   [3]int64{int64(t.origin[0]), int64(t.origin[1]), int64(t.origin[2])},
+		MaxManhattan: int64(t.maxManhattan),
+	}
+	for i, r := range t.ranges {
+		s.RangeSet[i], s.RangeMin[i], s.RangeMax[i] = r.set, int64(r.min), int64(r.max)
+		s.Wrap[i] = int64(t.wrap[i])
+	}
+
+	buf := bytes.NewBuffer(make([]byte, 0, len(traverserMagic)+1+binary.Size(s)))
+	buf.WriteString(traverserMagic)
+	buf.WriteByte(traverserVersion)
+	if err := binary.Write(buf, binary.LittleEndian, s); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary decodes a Traverser encoded using MarshalBinary, replacing the state of the Traverser.
+func (tr *Traverser) UnmarshalBinary(data []byte) error {
+	if !bytes.HasPrefix(data, []byte(traverserMagic)) {
+		return errors.New("traverser: invalid magic")
+	}
+	data = data[len(traverserMagic):]
+	if len(data) == 0 {
+		return errors.New("traverser: missing version")
+	}
+	if data[0] != traverserVersion {
+		return fmt.Errorf("traverser: unsupported version %v", data[0])
+	}
+	var s traverserState
+	if len(data)-1 != binary.Size(s) {
+		return fmt.Errorf("traverser: invalid state length %v", len(data)-1)
+	}
+	if err := binary.Read(bytes.NewReader(data[1:]), binary.LittleEndian, &s); err != nil {
+		return fmt.Errorf("traverser: %w", err)
+	}
+	for _, step := range [...]int8{s.StepX, s.StepY, s.StepZ} {
+		if step < -1 || step > 1 {
+			return fmt.Errorf("traverser: invalid step %v", step)
+		}
+	}
+	for _, f := range [...]uint8{s.FaceX, s.FaceY, s.FaceZ, s.Face} {
+		if Face(f) > FaceEast {
+			return fmt.Errorf("traverser: invalid face %v", f)
+		}
+	}
+
+	t := tracer{
+		start: s.Start, dir: s.Dir, offset: s.Offset, cell: s.Cell,
+		x: int(s.X), y: int(s.Y), z: int(s.Z),
+		stepX: int(s.StepX), stepY: int(s.StepY), stepZ: int(s.StepZ),
+		tMaxX: s.TMax[0], tMaxY: s.TMax[1], tMaxZ: s.TMax[2],
+		keyX: s.Key[0], keyY: s.Key[1], keyZ: s.Key[2],
+
+		line: canonicalLine{
+			origin: s.Origin, far: s.Far, dir: s.LineDir,
+			length:   s.Length,
+			reversed: s.Reversed, directed: s.Directed,
+		},
+
+		xBeforeY: s.XBeforeY, xBeforeZ: s.XBeforeZ, yBeforeZ: s.YBeforeZ,
+		faceX: Face(s.FaceX), faceY: Face(s.FaceY), faceZ: Face(s.FaceZ), face: Face(s.Face),
+
+		t: s.T, radius: s.Radius,
+		limitX: s.Limit[0], limitY: s.Limit[1], limitZ: s.Limit[2],
+		ceil: s.Ceil, toward: s.Toward,
+
+		visited: int(s.Visited), maxVoxels: int(s.MaxVoxels),
+		origin:       BlockPos{int(s.StartVoxel[0]), int(s.StartVoxel[1]), int(s.StartVoxel[2])},
+		maxManhattan: int(s.MaxManhattan),
+	}
+	for i := range t.ranges {
+		t.ranges[i] = axisRange{set: s.RangeSet[i], min: int(s.RangeMin[i]), max: int(s.RangeMax[i])}
+		t.limited = t.limited || s.RangeSet[i]
+		if s.Wrap[i] < 0 {
+			return fmt.Errorf("traverser: invalid wrap size %v", s.Wrap[i])
+		}
+		t.wrap[i] = int(s.Wrap[i])
+		t.wrapped = t.wrapped || s.Wrap[i] > 0
+	}
+	t.limited = t.limited || t.maxVoxels > 0 || t.maxManhattan >= 0
+	tr.t, tr.started = t, s.Started
+	return nil
+}
@@ -0,0 +1,75 @@
+package voxelraytrace
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestTraverserBinaryRoundTrip(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	optionSets := [][]Option{
+		nil,
+		{WithMaxVoxels(12), WithYRange(-4, 4)},
+		{WithCeilOwnership(), WithCellSize(0.5, 2, 1), WithGridOrigin(randomPoint(rng, 1))},
+		{WithCenteredVoxels(), WithMaxManhattan(9), WithWrap(8, 0, 8)},
+		{WithBoundaryTowardDirection(), WithUpAxis(AxisZ)},
+	}
+	for _, opts := range optionSets {
+		for i := 0; i < 200; i++ {
+			start, end := randomPoint(rng, 8), randomPoint(rng, 8)
+			tr, err := NewTraverser(start, end, opts...)
+			if err != nil {
+				continue
+			}
+			want := drain(tr)
+			// Split the trace before every voxel, and check that a Traverser decoded at that point returns the same
+			// voxels from there on.
+			for split := 0; split <= len(want); split++ {
+				tr, _ := NewTraverser(start, end, opts...)
+				for j := 0; j < split; j++ {
+					tr.NextPos()
+				}
+				data, err := tr.MarshalBinary()
+				if err != nil {
+					t.Fatalf("trace %v -> %v: unexpected error marshaling: %v", start, end, err)
+				}
+				var decoded Traverser
+				if err := decoded.UnmarshalBinary(data); err != nil {
+					t.Fatalf("trace %v -> %v: unexpected error unmarshaling: %v", start, end, err)
+				}
+				if got := drain(&decoded); !reflect.DeepEqual(got, want[split:]) {
+					t.Fatalf("trace %v -> %v split at %v: got %v, want %v", start, end, split, got, want[split:])
+				}
+			}
+		}
+	}
+}
+
+func TestTraverserUnmarshalBinaryInvalid(t *testing.T) {
+	tr, err := NewTraverser(randomPoint(rand.New(rand.NewSource(1)), 8), randomPoint(rand.New(rand.NewSource(2)), 8))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data, _ := tr.MarshalBinary()
+	stepX := len(traverserMagic) + 1 + 1 + 8*12 + 8*3
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{name: "empty"},
+		{name: "magic", data: append([]byte("VXTX"), data[4:]...)},
+		{name: "missing version", data: data[:4]},
+		{name: "version", data: append(append([]byte(traverserMagic), traverserVersion+1), data[5:]...)},
+		{name: "truncated", data: data[:len(data)-1]},
+		{name: "step", data: append(append(append([]byte{}, data[:stepX]...), 2), data[stepX+1:]...)},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var decoded Traverser
+			if err := decoded.UnmarshalBinary(test.data); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package voxelraytrace
+
+import (
+	"errors"
+	"github.com/go-gl/mathgl/mgl64"
+	"math"
+)
+
+// unitTolerance is the largest difference between 1 and the squared length of a direction vector passed to
+// TraceUnit.
+const unitTolerance = 1e-9
+
+// TraceUnit performs a ray trace from the start coordinates along a direction vector that is already normalised, for
+// the length passed, and calls fn with the position of every voxel passed through until it returns false. The
+// direction vector is only checked to have a squared length within 1e-9 of 1. The ray is traced along the same
+// canonical line as BetweenPoints(start, start.Add(unitDir.Mul(length))), with the end computed without fused
+// multiply-adds, so that the voxels passed through are exactly the same as for that call, including the order in
+// which crossings of voxel edges and corners are broken. Unlike BetweenPoints, the voxels are passed to fn as they
+// are reached rather than collected into a slice.
+func TraceUnit(start, unitDir mgl64.Vec3, length float64, fn func(pos BlockPos) bool, opts ...Option) error {
+	if math.Abs(1-sumSquares(unitDir[0], unitDir[1], unitDir[2])) >= unitTolerance {
+		return errors.New("direction vector is not normalised")
+	}
+	if !(length > 0) || math.IsInf(length, 1) {
+		return errors.New("length must be positive and finite")
+	}
+	if !finite(start) {
+		return ErrNonFiniteInput
+	}
+	// The direction is normalised again from the end of the ray, rather than used as is, as the crossings of the
+	// canonical line are computed from it: a direction that differs in the last bit breaks ties at voxel edges
+	// differently.
+	t, err := newTracer(start, along(start, unitDir, length), newConfig(opts))
+	if err != nil {
+		return err
+	}
+	for fn(t.pos()) && t.next() {
+	}
+	return t.err
+}
@@ -0,0 +1,161 @@
+package voxelraytrace
+
+import (
+	"github.com/go-gl/mathgl/mgl64"
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+// randomUnitRay returns a random start, a normalised direction and a length of a ray. None of them is rounded, so
+// that the end of the ray is not on a voxel boundary.
+func randomUnitRay(rng *rand.Rand) (start, dir mgl64.Vec3, length float64) {
+	start = mgl64.Vec3{rng.Float64()*16 - 8, rng.Float64()*16 - 8, rng.Float64()*16 - 8}
+	for dir.Len() < 0.1 {
+		dir = mgl64.Vec3{rng.Float64()*2 - 1, rng.Float64()*2 - 1, rng.Float64()*2 - 1}
+	}
+	return start, dir.Normalize(), rng.Float64() * 16
+}
+
+func TestTraceUnitMatchesBetweenPoints(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 5000; i++ {
+		start, dir, length := randomUnitRay(rng)
+		var positions []BlockPos
+		err := TraceUnit(start, dir, length, func(pos BlockPos) bool {
+			positions = append(positions, pos)
+			return true
+		})
+		if err != nil {
+			t.Fatalf("trace %v along %v for %v: unexpected error: %v", start, dir, length, err)
+		}
+		want, _ := BetweenPointsInt(start, start.Add(dir.Mul(length)))
+		if !reflect.DeepEqual(positions, want) {
+			t.Fatalf("trace %v along %v for %v: got %v, want %v", start, dir, length, positions, want)
+		}
+	}
+}
+
+// diagonalUnitRay returns a random ray starting at the centre or on an edge of a voxel, along a diagonal between two
+// or three axes, so that the ray crosses voxel edges or corners exactly in the middle of the ray.
+func diagonalUnitRay(rng *rand.Rand) (start, dir mgl64.Vec3, length float64) {
+	axes := 2 + rng.Intn(2)
+	c := math.Sqrt(1 / float64(axes))
+	for i, axis := range rng.Perm(3) {
+		start[axis] = float64(rng.Intn(16) - 8)
+		if rng.Intn(2) == 0 {
+			start[axis] += 0.5
+		}
+		if i < axes {
+			dir[axis] = c
+			if rng.Intn(2) == 0 {
+				dir[axis] = -c
+			}
+		}
+	}
+	return start, dir, rng.Float64() * 16
+}
+
+// traceUnitPositions returns the voxels passed through by TraceUnit for the ray passed.
+func traceUnitPositions(start, dir mgl64.Vec3, length float64, opts ...Option) ([]BlockPos, error) {
+	var positions []BlockPos
+	err := TraceUnit(start, dir, length, func(pos BlockPos) bool {
+		positions = append(positions, pos)
+		return true
+	}, opts...)
+	return positions, err
+}
+
+func TestTraceUnitEdgeCrossings(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	for _, opts := range [][]Option{nil, {WithCeilOwnership()}, {WithUpAxis(AxisZ)}} {
+		for i := 0; i < 5000; i++ {
+			start, dir, length := diagonalUnitRay(rng)
+			if math.Abs(1-sumSquares(dir[0], dir[1], dir[2])) >= unitTolerance {
+				continue
+			}
+			positions, err := traceUnitPositions(start, dir, length, opts...)
+			if err != nil {
+				t.Fatalf("trace %v along %v for %v: unexpected error: %v", start, dir, length, err)
+			}
+			want, _ := BetweenPointsInt(start, start.Add(dir.Mul(length)), opts...)
+			if !reflect.DeepEqual(positions, want) {
+				t.Fatalf("trace %v along %v for %v: got %v, want %v", start, dir, length, positions, want)
+			}
+		}
+	}
+
+	// A ray from the centre of a voxel crossing the edge between two voxels exactly, which was once broken the other
+	// way round by TraceUnit.
+	start, dir, length := mgl64.Vec3{6.5, -6.5, 2.5}, mgl64.Vec3{math.Sqrt(0.5), math.Sqrt(0.5), 0}, 7.2124
+	positions, err := traceUnitPositions(start, dir, length)
+	want, _ := BetweenPointsInt(start, start.Add(dir.Mul(length)))
+	if err != nil || !reflect.DeepEqual(positions, want) {
+		t.Errorf("got %v, %v, want %v", positions, err, want)
+	}
+}
+
+func TestTraceUnit(t *testing.T) {
+	var positions []BlockPos
+	err := TraceUnit(mgl64.Vec3{0.5, 0.5, 0.5}, mgl64.Vec3{1, 0, 0}, 10, func(pos BlockPos) bool {
+		positions = append(positions, pos)
+		return len(positions) < 3
+	})
+	if want := []BlockPos{{0, 0, 0}, {1, 0, 0}, {2, 0, 0}}; err != nil || !reflect.DeepEqual(positions, want) {
+		t.Errorf("got %v, %v, want %v after stopping", positions, err, want)
+	}
+
+	tests := []struct {
+		name   string
+		start  mgl64.Vec3
+		dir    mgl64.Vec3
+		length float64
+	}{
+		{name: "not normalised", dir: mgl64.Vec3{1, 1, 0}, length: 1},
+		{name: "zero direction", length: 1},
+		{name: "zero length", dir: mgl64.Vec3{1, 0, 0}},
+		{name: "negative length", dir: mgl64.Vec3{1, 0, 0}, length: -1},
+		{name: "infinite length", dir: mgl64.Vec3{1, 0, 0}, length: math.Inf(1)},
+		{name: "non-finite start", start: mgl64.Vec3{math.NaN(), 0, 0}, dir: mgl64.Vec3{1, 0, 0}, length: 1},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := TraceUnit(test.start, test.dir, test.length, func(BlockPos) bool { return true }); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func BenchmarkTraceUnit(b *testing.B) {
+	rng := rand.New(rand.NewSource(1))
+	type ray struct {
+		start, dir mgl64.Vec3
+		length     float64
+	}
+	// Short rays, for which the cost of setting up the trace is a larger part of the work.
+	rays := make([]ray, 1024)
+	for i := range rays {
+		start, dir, _ := randomUnitRay(rng)
+		rays[i] = ray{start: start, dir: dir, length: 2}
+	}
+	// Both append to a reused slice, so that neither allocates.
+	dst := make([]mgl64.Vec3, 0, 64)
+	b.Run("unit", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			r := rays[i%len(rays)]
+			dst = dst[:0]
+			_ = TraceUnit(r.start, r.dir, r.length, func(pos BlockPos) bool {
+				dst = append(dst, pos.Vec3Min())
+				return true
+			})
+		}
+	})
+	b.Run("between points", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			r := rays[i%len(rays)]
+			dst, _ = BetweenPointsAppend(dst[:0], r.start, r.start.Add(r.dir.Mul(r.length)))
+		}
+	})
+}
@@ -0,0 +1,77 @@
+package voxelraytrace
+
+import (
+	"fmt"
+	"github.com/go-gl/mathgl/mgl64"
+	"math"
+)
+
+// axisOrder maps the axes of the Y-up convention used by the package to the axes of the coordinate convention used by
+// the caller: the axis at index i is the axis of the caller that plays the role of axis i in the Y-up convention.
+// Traversals compare and combine the axes in this order, so that a ray traced in another convention passes through
+// exactly the voxels that the same ray, with its coordinates swapped, passes through in the Y-up convention.
+type axisOrder [3]int
+
+// yUp is the axisOrder of the Y-up convention.
+var yUp = axisOrder{0, 1, 2}
+
+// upAxisOrder returns the axisOrder of the convention in which the axis passed points up. The up axis is swapped with
+// the Y axis.
+func upAxisOrder(up Axis) axisOrder {
+	switch up {
+	case AxisX:
+		return axisOrder{1, 0, 2}
+	case AxisY:
+		return yUp
+	case AxisZ:
+		return axisOrder{0, 2, 1}
+	}
+	panic(fmt.Sprintf("voxelraytrace: axis %v (%d) is not a valid axis", up, uint8(up)))
+}
+
+// less checks if a comes before b when comparing their coordinates in the order of the axes in the Y-up convention.
+func (o axisOrder) less(a, b mgl64.Vec3) bool {
+	for _, i := range o {
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+	return false
+}
+
+// length returns the length of the vector passed, summing its components in the order of the axes in the Y-up
+// convention.
+func (o axisOrder) length(v mgl64.Vec3) float64 {
+	return math.Sqrt(sumSquares(v[o[0]], v[o[1]], v[o[2]]))
+}
+
+// normalize returns the vector passed divided by its length as returned by length.
+func (o axisOrder) normalize(v mgl64.Vec3) mgl64.Vec3 {
+	l := 1.0 / o.length(v)
+	return mgl64.Vec3{v[0] * l, v[1] * l, v[2] * l}
+}
+
+// WithUpAxis makes a trace use a coordinate convention in which the axis passed points up, rather than the Y axis,
+// such as AxisZ for a Z-up engine. Coordinates are passed and returned in this convention, so no conversion is
+// needed, and the voxels passed through are exactly those of the same ray with its coordinates swapped to the Y-up
+// convention. Only the way the axes are named changes: FaceUp and FaceDown are reported for faces pointing along the
+// up axis, and FaceNorth, FaceSouth and WithZRange refer to the axis that was swapped with it, while WithYRange
+// limits the up axis. Face.Offset and FaceNormal always use the Y-up convention; Face.OffsetFor may be used to find
+// the offset of a face in another convention. WithUpAxis panics if AxisNone or an unknown axis is passed.
+func WithUpAxis(up Axis) Option {
+	order := upAxisOrder(up)
+	return func(c *config) {
+		c.order = order
+	}
+}
+
+// OffsetFor returns the offset of the face like Offset, but in the coordinate convention in which the axis passed
+// points up, as set using WithUpAxis. OffsetFor panics if AxisNone or an unknown axis is passed.
+func (f Face) OffsetFor(up Axis) BlockPos {
+	order, offset := upAxisOrder(up), f.Offset()
+	var pos BlockPos
+	for i, axis := range order {
+		pos[axis] = offset[i]
+	}
+	return pos
+}
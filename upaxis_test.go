@@ -0,0 +1,81 @@
+package voxelraytrace
+
+import (
+	"github.com/go-gl/mathgl/mgl64"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+// swapYZ swaps the Y and Z coordinates of the vector passed, converting between the Y-up and Z-up conventions.
+func swapYZ(v mgl64.Vec3) mgl64.Vec3 {
+	return mgl64.Vec3{v[0], v[2], v[1]}
+}
+
+// swapPosYZ swaps the Y and Z coordinates of the position passed.
+func swapPosYZ(pos BlockPos) BlockPos {
+	return BlockPos{pos[0], pos[2], pos[1]}
+}
+
+func TestWithUpAxisMatchesSwappedTrace(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 10000; i++ {
+		start, end := randomPoint(rng, 8), randomPoint(rng, 8)
+		if start == end {
+			continue
+		}
+		// The Y range of the Z-up trace limits its Z axis, like the Y range of the Y-up trace limits its Y axis.
+		want, err := BetweenPointsInt(start, end, WithYRange(-3, 4))
+		if err != nil {
+			t.Fatalf("trace %v -> %v: unexpected error: %v", start, end, err)
+		}
+		got, _ := BetweenPointsInt(swapYZ(start), swapYZ(end), WithUpAxis(AxisZ), WithYRange(-3, 4))
+		for j := range got {
+			got[j] = swapPosYZ(got[j])
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("trace %v -> %v: got %v swapped, want %v", start, end, got, want)
+		}
+	}
+}
+
+func TestWithUpAxisFaces(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	yUpGrid, zUpGrid := NewSparseGrid(), NewSparseGrid()
+	for i := 0; i < 200; i++ {
+		pos := BlockPos{rng.Intn(16) - 8, rng.Intn(16) - 8, rng.Intn(16) - 8}
+		yUpGrid.Set(pos[0], pos[1], pos[2], true)
+		pos = swapPosYZ(pos)
+		zUpGrid.Set(pos[0], pos[1], pos[2], true)
+	}
+	for i := 0; i < 5000; i++ {
+		start, end := randomPoint(rng, 8), randomPoint(rng, 8)
+		want, wantOK, _ := FirstSolidHit(yUpGrid, start, end)
+		got, ok, err := FirstSolidHit(zUpGrid, swapYZ(start), swapYZ(end), WithUpAxis(AxisZ))
+		if err != nil || ok != wantOK {
+			t.Fatalf("trace %v -> %v: got %v, %v, want %v", start, end, ok, err, wantOK)
+		}
+		if !ok {
+			continue
+		}
+		// The face is reported the same, as FaceUp means +Z in the Z-up convention, and its offset in that
+		// convention points to the same neighbour.
+		if got.Pos != swapPosYZ(want.Pos) || got.Face != want.Face || got.Point != swapYZ(want.Point) {
+			t.Fatalf("trace %v -> %v: got %+v, want %+v swapped", start, end, got, want)
+		}
+		if got.Face != FaceNone && got.Pos.Add(got.Face.OffsetFor(AxisZ)) != swapPosYZ(want.Pos.Side(want.Face)) {
+			t.Fatalf("trace %v -> %v: offset %v of %v does not point to the swapped neighbour", start, end, got.Face.OffsetFor(AxisZ), got.Face)
+		}
+	}
+
+	// A ray falling along the Z axis onto a floor in a Z-up world hits its top face.
+	g := NewSparseGrid()
+	g.Set(0, 0, 0, true)
+	hit, ok, err := FirstSolidHit(g, mgl64.Vec3{0.5, 0.5, 5}, mgl64.Vec3{0.5, 0.5, -5}, WithUpAxis(AxisZ))
+	if err != nil || !ok || hit.Face != FaceUp || hit.Point != (mgl64.Vec3{0.5, 0.5, 1}) {
+		t.Errorf("got %+v, %v, %v, want a hit on the top face at Z 1", hit, ok, err)
+	}
+	if got := FaceUp.OffsetFor(AxisZ); got != (BlockPos{0, 0, 1}) {
+		t.Errorf("got offset %v for the top face in the Z-up convention, want +Z", got)
+	}
+}
@@ -0,0 +1,83 @@
+package voxelraytrace
+
+import (
+	"errors"
+	"fmt"
+	"github.com/go-gl/mathgl/mgl64"
+	"math"
+)
+
+// validateTolerance is the distance by which voxels are grown by ValidatePath before checking if they contain or
+// intersect something, so that differences in rounding between platforms are not reported as violations.
+const validateTolerance = 1e-7
+
+// PathError is returned by ValidatePath for the first voxel of a path that violates one of the properties checked.
+type PathError struct {
+	// Index is the index of the voxel in the path.
+	Index int
+	// Pos is the position of the voxel.
+	Pos BlockPos
+	// Reason describes the property that was violated.
+	Reason string
+}
+
+// Error returns a message holding the index and position of the voxel and the reason it is invalid.
+func (e PathError) Error() string {
+	return fmt.Sprintf("path voxel %v at index %v: %v", e.Pos, e.Index, e.Reason)
+}
+
+// ValidatePath checks if the path passed is a valid result of a ray trace between the start and end coordinates, as
+// returned by BetweenPointsInt without any Options. It checks that every voxel is face-adjacent to the one before
+// it, that the first voxel contains the start coordinates, that the last voxel contains or is face-adjacent to the
+// voxel containing the end coordinates and that every voxel intersects the segment between them. The checks are
+// conservative: voxels are grown by a small tolerance, so that a path is only rejected if it is clearly wrong. A
+// PathError is returned for the first voxel found violating any of these, and an error if the path is empty.
+// ValidatePath is meant for debugging, for example to find issues with floating point behaviour on a platform.
+func ValidatePath(start, end mgl64.Vec3, path []BlockPos) error {
+	if len(path) == 0 {
+		return errors.New("path is empty")
+	}
+	if !voxelContains(path[0], start) {
+		return PathError{Index: 0, Pos: path[0], Reason: fmt.Sprintf("does not contain start %v", start)}
+	}
+	diff := end.Sub(start)
+	for i, pos := range path {
+		if i > 0 && !faceAdjacent(path[i-1], pos) {
+			return PathError{Index: i, Pos: pos, Reason: fmt.Sprintf("is not face-adjacent to previous voxel %v", path[i-1])}
+		}
+		min := pos.Vec3Min().Sub(mgl64.Vec3{validateTolerance, validateTolerance, validateTolerance})
+		max := pos.Vec3Min().Add(mgl64.Vec3{1 + validateTolerance, 1 + validateTolerance, 1 + validateTolerance})
+		if tEntry, tExit, ok := intersectBox(start, diff, min, max); !ok || tEntry > 1 || tExit < 0 {
+			return PathError{Index: i, Pos: pos, Reason: "does not intersect the segment"}
+		}
+	}
+	last := len(path) - 1
+	if !voxelContains(path[last], end) && !faceAdjacent(path[last], BlockPosFromVec3(end)) {
+		return PathError{Index: last, Pos: path[last], Reason: fmt.Sprintf("neither contains nor is adjacent to end %v", end)}
+	}
+	return nil
+}
+
+// voxelContains checks if the voxel passed, grown by validateTolerance, contains the point passed.
+func voxelContains(pos BlockPos, p mgl64.Vec3) bool {
+	for i := 0; i < 3; i++ {
+		if math.IsNaN(p[i]) || p[i] < float64(pos[i])-validateTolerance || p[i] > float64(pos[i]+1)+validateTolerance {
+			return false
+		}
+	}
+	return true
+}
+
+// faceAdjacent checks if the voxels passed share a face.
+func faceAdjacent(a, b BlockPos) bool {
+	d := 0
+	for i := 0; i < 3; i++ {
+		if a[i] != b[i] {
+			if a[i]-b[i] != 1 && b[i]-a[i] != 1 {
+				return false
+			}
+			d++
+		}
+	}
+	return d == 1
+}
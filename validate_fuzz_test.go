@@ -0,0 +1,31 @@
+//go:build go1.18
+
+package voxelraytrace
+
+import (
+	"github.com/go-gl/mathgl/mgl64"
+	"math"
+	"testing"
+)
+
+func FuzzValidatePath(f *testing.F) {
+	f.Add(0.5, 0.5, 0.5, 10.5, 3.25, -2.0)
+	f.Add(0.0, 0.0, 0.0, 2.0, 2.0, 2.0)
+	f.Add(-1.0, 4.0, 0.5, -1.0, -4.0, 0.5)
+	f.Fuzz(func(t *testing.T, x1, y1, z1, x2, y2, z2 float64) {
+		start, end := mgl64.Vec3{x1, y1, z1}, mgl64.Vec3{x2, y2, z2}
+		for _, v := range []float64{x1, y1, z1, x2, y2, z2} {
+			// Very long rays take too long to trace and lose the precision needed to place points within voxels.
+			if math.IsNaN(v) || math.Abs(v) > 1e4 {
+				return
+			}
+		}
+		positions, err := BetweenPointsInt(start, end)
+		if err != nil {
+			return
+		}
+		if err := ValidatePath(start, end, positions); err != nil {
+			t.Fatalf("trace %v -> %v: %v", start, end, err)
+		}
+	})
+}
@@ -0,0 +1,51 @@
+package voxelraytrace
+
+import (
+	"errors"
+	"github.com/go-gl/mathgl/mgl64"
+	"math/rand"
+	"testing"
+)
+
+func TestValidatePathRandomRays(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 20000; i++ {
+		start, end := randomPoint(rng, 64), randomPoint(rng, 64)
+		positions, err := BetweenPointsInt(start, end)
+		if err != nil {
+			continue
+		}
+		if err := ValidatePath(start, end, positions); err != nil {
+			t.Fatalf("trace %v -> %v: %v", start, end, err)
+		}
+	}
+}
+
+func TestValidatePath(t *testing.T) {
+	start, end := mgl64.Vec3{0.5, 0.5, 0.5}, mgl64.Vec3{3.5, 1.5, 0.5}
+	tests := []struct {
+		name  string
+		path  []BlockPos
+		index int
+	}{
+		{name: "not containing start", path: []BlockPos{{1, 0, 0}, {2, 0, 0}, {2, 1, 0}, {3, 1, 0}}, index: 0},
+		{name: "gap", path: []BlockPos{{0, 0, 0}, {2, 0, 0}, {2, 1, 0}, {3, 1, 0}}, index: 1},
+		{name: "diagonal step", path: []BlockPos{{0, 0, 0}, {1, 0, 0}, {2, 1, 0}, {3, 1, 0}}, index: 2},
+		{name: "off segment", path: []BlockPos{{0, 0, 0}, {0, 1, 0}, {1, 1, 0}, {2, 1, 0}, {3, 1, 0}}, index: 1},
+		{name: "short of end", path: []BlockPos{{0, 0, 0}, {1, 0, 0}}, index: 1},
+	}
+	if err := ValidatePath(start, end, []BlockPos{{0, 0, 0}, {1, 0, 0}, {2, 0, 0}, {2, 1, 0}, {3, 1, 0}}); err != nil {
+		t.Errorf("unexpected error for a valid path: %v", err)
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var pathErr PathError
+			if err := ValidatePath(start, end, test.path); !errors.As(err, &pathErr) || pathErr.Index != test.index {
+				t.Errorf("got %v, want a PathError at index %v", err, test.index)
+			}
+		})
+	}
+	if err := ValidatePath(start, end, nil); err == nil {
+		t.Error("expected an error for an empty path")
+	}
+}
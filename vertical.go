@@ -0,0 +1,42 @@
+package voxelraytrace
+
+import (
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// TraceDown finds the first solid voxel in the Grid directly below the position passed, at most maxDrop below it.
+// The voxel is returned along with the Y coordinate of its top surface. If the position is inside a solid voxel, that
+// voxel is returned with the Y coordinate of the position as surface. Rather than performing a full ray trace, only
+// the Y coordinate is stepped, which makes it considerably cheaper. If no solid voxel was found, false is returned.
+// maxDrop must be finite.
+func TraceDown(g Grid, pos mgl64.Vec3, maxDrop float64) (ground BlockPos, surfaceY float64, ok bool) {
+	start := BlockPosFromVec3(pos)
+	if g.Solid(start[0], start[1], start[2]) {
+		return start, pos[1], true
+	}
+	lowest := pos[1] - maxDrop
+	for y := start[1] - 1; float64(y+1) >= lowest; y-- {
+		if g.Solid(start[0], y, start[2]) {
+			return BlockPos{start[0], y, start[2]}, float64(y + 1), true
+		}
+	}
+	return BlockPos{}, 0, false
+}
+
+// TraceUp finds the first solid voxel in the Grid directly above the position passed, at most maxRise above it. The
+// voxel is returned along with the Y coordinate of its bottom surface. If the position is inside a solid voxel, that
+// voxel is returned with the Y coordinate of the position as surface. Like TraceDown, only the Y coordinate is
+// stepped. If no solid voxel was found, false is returned. maxRise must be finite.
+func TraceUp(g Grid, pos mgl64.Vec3, maxRise float64) (ceiling BlockPos, surfaceY float64, ok bool) {
+	start := BlockPosFromVec3(pos)
+	if g.Solid(start[0], start[1], start[2]) {
+		return start, pos[1], true
+	}
+	highest := pos[1] + maxRise
+	for y := start[1] + 1; float64(y) <= highest; y++ {
+		if g.Solid(start[0], y, start[2]) {
+			return BlockPos{start[0], y, start[2]}, float64(y), true
+		}
+	}
+	return BlockPos{}, 0, false
+}
@@ -0,0 +1,85 @@
+package voxelraytrace
+
+import (
+	"github.com/go-gl/mathgl/mgl64"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestTraceDownAndUp(t *testing.T) {
+	g := NewSparseGrid()
+	g.Set(0, 60, 0, true)
+	g.Set(0, 70, 0, true)
+	g.Set(-1, 64, -1, true)
+	tests := []struct {
+		name    string
+		trace   func(g Grid, pos mgl64.Vec3, max float64) (BlockPos, float64, bool)
+		pos     mgl64.Vec3
+		max     float64
+		want    BlockPos
+		surface float64
+		ok      bool
+	}{
+		{name: "down", trace: TraceDown, pos: mgl64.Vec3{0.5, 64.5, 0.5}, max: 10, want: BlockPos{0, 60, 0}, surface: 61, ok: true},
+		{name: "down exactly reaching", trace: TraceDown, pos: mgl64.Vec3{0.5, 64.5, 0.5}, max: 3.5, want: BlockPos{0, 60, 0}, surface: 61, ok: true},
+		{name: "down too short", trace: TraceDown, pos: mgl64.Vec3{0.5, 64.5, 0.5}, max: 3.4},
+		{name: "down inside", trace: TraceDown, pos: mgl64.Vec3{-0.5, 64.25, -0.5}, max: 10, want: BlockPos{-1, 64, -1}, surface: 64.25, ok: true},
+		{name: "down negative", trace: TraceDown, pos: mgl64.Vec3{-0.5, 70.5, -0.5}, max: 10, want: BlockPos{-1, 64, -1}, surface: 65, ok: true},
+		{name: "up", trace: TraceUp, pos: mgl64.Vec3{0.5, 64.5, 0.5}, max: 10, want: BlockPos{0, 70, 0}, surface: 70, ok: true},
+		{name: "up exactly reaching", trace: TraceUp, pos: mgl64.Vec3{0.5, 64.5, 0.5}, max: 5.5, want: BlockPos{0, 70, 0}, surface: 70, ok: true},
+		{name: "up too short", trace: TraceUp, pos: mgl64.Vec3{0.5, 64.5, 0.5}, max: 5.4},
+		{name: "up inside", trace: TraceUp, pos: mgl64.Vec3{0.5, 60, 0.5}, max: 10, want: BlockPos{0, 60, 0}, surface: 60, ok: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, surface, ok := test.trace(g, test.pos, test.max)
+			if got != test.want || surface != test.surface || ok != test.ok {
+				t.Errorf("got %v, %v, %v, want %v, %v, %v", got, surface, ok, test.want, test.surface, test.ok)
+			}
+		})
+	}
+}
+
+func TestTraceDownMatchesFirstSolidHit(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	g := randomGrid(rng, 4, 20)
+	for i := 0; i < 5000; i++ {
+		pos := mgl64.Vec3{rng.Float64() * 4, float64(rng.Intn(17)) / 4, rng.Float64() * 4}
+		max := float64(rng.Intn(17)) / 4
+		if max == 0 {
+			continue
+		}
+		for _, dir := range []float64{-1, 1} {
+			// A surface exactly maxDrop below the position is found by TraceDown, but not entered by a ray ending on
+			// it, so the ray is made slightly longer.
+			end := pos.Add(mgl64.Vec3{0, -max - 1e-9, 0})
+			trace, name := TraceDown, "down"
+			if dir > 0 {
+				end, trace, name = pos.Add(mgl64.Vec3{0, max, 0}), TraceUp, "up"
+			}
+			hit, wantOK, _ := FirstSolidHit(g, pos, end)
+			got, surface, ok := trace(g, pos, max)
+			if ok != wantOK || ok && (got != hit.Pos || math.Abs(surface-hit.Point[1]) > 1e-9) {
+				t.Fatalf("%v from %v for %v: got %v, %v, %v, want %v, %v, %v", name, pos, max, got, surface, ok, hit.Pos, hit.Point[1], wantOK)
+			}
+		}
+	}
+}
+
+func BenchmarkTraceDown(b *testing.B) {
+	g := NewSparseGrid()
+	g.Set(0, 0, 0, true)
+	pos := mgl64.Vec3{0.5, 64.5, 0.5}
+	b.Run("vertical", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_, _, _ = TraceDown(g, pos, 100)
+		}
+	})
+	b.Run("generic", func(b *testing.B) {
+		end := pos.Sub(mgl64.Vec3{0, 100, 0})
+		for i := 0; i < b.N; i++ {
+			_, _, _ = FirstSolidHit(g, pos, end)
+		}
+	})
+}
@@ -0,0 +1,117 @@
+package voxelraytrace
+
+import (
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// VisitedSet is a set of voxel positions that remembers the order in which positions were first added, used to merge
+// the voxels passed through by many overlapping rays. It is implemented as an open-addressed hash table of indices
+// into the ordered positions, which avoids the overhead of a Go map. The zero value is an empty set ready to use. A
+// VisitedSet is not safe for concurrent use.
+type VisitedSet struct {
+	ordered []BlockPos
+	// table holds the index into ordered plus one of the position in every slot, or 0 for empty slots. Its length is
+	// always a power of two.
+	table []int32
+}
+
+// Add adds all positions of the path passed to the set, ignoring those that are already in it.
+func (s *VisitedSet) Add(path []BlockPos) {
+	for _, pos := range path {
+		s.AddPos(pos)
+	}
+}
+
+// AddPos adds the position passed to the set and returns true if it was not yet in it.
+func (s *VisitedSet) AddPos(pos BlockPos) bool {
+	if (len(s.ordered)+1)*4 > len(s.table)*3 {
+		s.grow()
+	}
+	slot, found := s.find(pos)
+	if found {
+		return false
+	}
+	s.ordered = append(s.ordered, pos)
+	s.table[slot] = int32(len(s.ordered))
+	return true
+}
+
+// AddRay performs a ray trace between the start and end coordinates and adds every voxel passed through to the set
+// directly, without collecting the voxels of the ray first.
+func (s *VisitedSet) AddRay(start, end mgl64.Vec3, opts ...Option) error {
+	t, err := newTracer(start, end, newConfig(opts))
+	if err != nil {
+		return err
+	}
+	for {
+		s.AddPos(t.pos())
+		if !t.next() {
+			return t.err
+		}
+	}
+}
+
+// Contains checks if the position passed is in the set.
+func (s *VisitedSet) Contains(pos BlockPos) bool {
+	if len(s.table) == 0 {
+		return false
+	}
+	_, found := s.find(pos)
+	return found
+}
+
+// Len returns the amount of positions in the set.
+func (s *VisitedSet) Len() int {
+	return len(s.ordered)
+}
+
+// Ordered returns the positions in the set in the order in which they were first added. The slice returned is owned
+// by the set and must not be modified. It remains valid until the set is modified.
+func (s *VisitedSet) Ordered() []BlockPos {
+	return s.ordered
+}
+
+// Reset removes all positions from the set, while keeping the memory allocated for them.
+func (s *VisitedSet) Reset() {
+	s.ordered = s.ordered[:0]
+	for i := range s.table {
+		s.table[i] = 0
+	}
+}
+
+// find returns the slot of the table holding the position passed, or the empty slot at which it should be inserted
+// if it is not in the set.
+func (s *VisitedSet) find(pos BlockPos) (slot int, found bool) {
+	mask := len(s.table) - 1
+	for slot = int(hashBlockPos(pos)) & mask; ; slot = (slot + 1) & mask {
+		i := s.table[slot]
+		if i == 0 {
+			return slot, false
+		}
+		if s.ordered[i-1] == pos {
+			return slot, true
+		}
+	}
+}
+
+// grow doubles the size of the table and reinserts all positions.
+func (s *VisitedSet) grow() {
+	size := len(s.table) * 2
+	if size == 0 {
+		size = 64
+	}
+	s.table = make([]int32, size)
+	for i, pos := range s.ordered {
+		slot, _ := s.find(pos)
+		s.table[slot] = int32(i + 1)
+	}
+}
+
+// hashBlockPos returns a hash of the position passed with its bits well mixed, so that it may be masked to index a
+// table.
+func hashBlockPos(pos BlockPos) uint64 {
+	h := uint64(pos[0])*0x9e3779b97f4a7c15 ^ uint64(pos[1])*0xc2b2ae3d27d4eb4f ^ uint64(pos[2])*0x165667b19e3779f9
+	h ^= h >> 32
+	h *= 0xd6e8feb86659fd93
+	return h ^ h>>32
+}
@@ -0,0 +1,114 @@
+package voxelraytrace
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestVisitedSet(t *testing.T) {
+	var s VisitedSet
+	if s.Contains(BlockPos{}) || s.Len() != 0 {
+		t.Fatal("the zero value is not an empty set")
+	}
+	s.Add([]BlockPos{{1, 2, 3}, {0, 0, 0}, {1, 2, 3}, {-4, 5, -6}})
+	s.Add([]BlockPos{{-4, 5, -6}, {7, 7, 7}})
+	want := []BlockPos{{1, 2, 3}, {0, 0, 0}, {-4, 5, -6}, {7, 7, 7}}
+	if got := s.Ordered(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v in order of first insertion", got, want)
+	}
+	if s.AddPos(BlockPos{0, 0, 0}) || !s.AddPos(BlockPos{0, 0, 1}) {
+		t.Error("AddPos did not report if the position was new")
+	}
+	if !s.Contains(BlockPos{-4, 5, -6}) || s.Contains(BlockPos{4, 5, 6}) {
+		t.Error("Contains reported the wrong positions")
+	}
+	s.Reset()
+	if s.Len() != 0 || s.Contains(BlockPos{1, 2, 3}) {
+		t.Errorf("got %v after resetting the set, want it empty", s.Ordered())
+	}
+}
+
+func TestVisitedSetMatchesMap(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	var s VisitedSet
+	seen := make(map[BlockPos]struct{})
+	var ordered []BlockPos
+	// Enough positions to grow the table several times, in a small enough range to add many positions twice.
+	for i := 0; i < 20000; i++ {
+		pos := BlockPos{rng.Intn(41) - 20, rng.Intn(41) - 20, rng.Intn(41) - 20}
+		_, found := seen[pos]
+		if added := s.AddPos(pos); added == found {
+			t.Fatalf("adding %v returned %v, want %v", pos, added, !found)
+		}
+		if !found {
+			seen[pos] = struct{}{}
+			ordered = append(ordered, pos)
+		}
+	}
+	if !reflect.DeepEqual(s.Ordered(), ordered) {
+		t.Fatal("positions are not ordered by first insertion")
+	}
+	for i := 0; i < 1000; i++ {
+		pos := BlockPos{rng.Intn(51) - 25, rng.Intn(51) - 25, rng.Intn(51) - 25}
+		if _, found := seen[pos]; s.Contains(pos) != found {
+			t.Fatalf("Contains(%v) returned %v, want %v", pos, !found, found)
+		}
+	}
+}
+
+func TestVisitedSetAddRay(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	var direct, collected VisitedSet
+	for i := 0; i < 200; i++ {
+		start, end := randomPoint(rng, 16), randomPoint(rng, 16)
+		positions, err := BetweenPointsInt(start, end)
+		if err != nil {
+			continue
+		}
+		collected.Add(positions)
+		if err := direct.AddRay(start, end); err != nil {
+			t.Fatalf("trace %v -> %v: unexpected error: %v", start, end, err)
+		}
+	}
+	if !reflect.DeepEqual(direct.Ordered(), collected.Ordered()) {
+		t.Error("adding rays directly gave different voxels than adding their paths")
+	}
+}
+
+func BenchmarkVisitedSet(b *testing.B) {
+	rng := rand.New(rand.NewSource(1))
+	// A fan of rays from a single point, of which the paths overlap heavily near the origin.
+	var paths [][]BlockPos
+	for i := 0; i < 256; i++ {
+		positions, _ := BetweenPointsInt(randomPoint(rng, 0.5), randomPoint(rng, 32))
+		paths = append(paths, positions)
+	}
+	b.Run("visited set", func(b *testing.B) {
+		var s VisitedSet
+		for i := 0; i < b.N; i++ {
+			s.Reset()
+			for _, path := range paths {
+				s.Add(path)
+			}
+		}
+	})
+	b.Run("map", func(b *testing.B) {
+		seen := make(map[BlockPos]struct{})
+		var ordered []BlockPos
+		for i := 0; i < b.N; i++ {
+			for pos := range seen {
+				delete(seen, pos)
+			}
+			ordered = ordered[:0]
+			for _, path := range paths {
+				for _, pos := range path {
+					if _, ok := seen[pos]; !ok {
+						seen[pos] = struct{}{}
+						ordered = append(ordered, pos)
+					}
+				}
+			}
+		}
+	})
+}
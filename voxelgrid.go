@@ -0,0 +1,54 @@
+package voxelraytrace
+
+import (
+	"github.com/go-gl/mathgl/mgl64"
+	"math"
+)
+
+// VoxelGrid is a set of occupied voxels that may be traced against using TraceGrid. Unlike Grid, voxels are passed
+// as the vectors returned by BetweenPoints, which allows callers working with vectors to use the storage of their
+// world without converting coordinates.
+type VoxelGrid interface {
+	// Contains returns true if the voxel passed is occupied.
+	Contains(v mgl64.Vec3) bool
+}
+
+// BoundedVoxelGrid is a VoxelGrid of which all occupied voxels lie within bounds. TraceGrid clips rays to these
+// bounds before tracing them.
+type BoundedVoxelGrid interface {
+	VoxelGrid
+	// Bounds returns the world space extent of the grid. No voxels outside these bounds are occupied.
+	Bounds() (min, max mgl64.Vec3)
+}
+
+// TraceGrid performs a ray trace between the start and end coordinates and returns the coordinates of the voxels
+// passed through that are occupied in the VoxelGrid passed. If the grid implements BoundedVoxelGrid, the ray is
+// clipped to the bounds of the grid first, so that no voxels outside of the grid are visited. The bounds are relative to
+// the origin of the voxel grid, which only differs from world space if an Option such as WithCenteredVoxels is passed.
+func TraceGrid(start, end mgl64.Vec3, grid VoxelGrid, opts ...Option) ([]mgl64.Vec3, error) {
+	t, err := newTracer(start, end, newConfig(opts))
+	if err != nil {
+		return nil, err
+	}
+	if bounded, ok := grid.(BoundedVoxelGrid); ok {
+		// The bounds are limited to the voxels they overlap, which the ray is clipped to like done by WithBounds.
+		lower, upper := bounded.Bounds()
+		var min, max BlockPos
+		for i := 0; i < 3; i++ {
+			min[i], max[i] = int(math.Floor(lower[i])), int(math.Ceil(upper[i]))-1
+		}
+		if (AABBInt{Min: min, Max: max}).Empty() || !t.clip(min, max) {
+			return nil, t.err
+		}
+	}
+
+	var vectors []mgl64.Vec3
+	for {
+		if v := t.pos().Vec3Min(); grid.Contains(v) {
+			vectors = append(vectors, v)
+		}
+		if !t.next() {
+			return vectors, t.err
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package voxelraytrace
+
+import (
+	"github.com/go-gl/mathgl/mgl64"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+// unboundedVoxelGrid wraps a VoxelGrid so that it does not implement BoundedVoxelGrid.
+type unboundedVoxelGrid struct {
+	VoxelGrid
+}
+
+// filterGrid returns the voxels passed that are occupied in the VoxelGrid passed, in order.
+func filterGrid(voxels []mgl64.Vec3, g VoxelGrid) []mgl64.Vec3 {
+	var occupied []mgl64.Vec3
+	for _, v := range voxels {
+		if g.Contains(v) {
+			occupied = append(occupied, v)
+		}
+	}
+	return occupied
+}
+
+func TestTraceGrid(t *testing.T) {
+	full := NewDenseVoxelGrid(4, 4, 4)
+	full.Fill(true)
+	sparse := NewSparseVoxelGrid([]mgl64.Vec3{{2, -3, -3}, {2, 0, -1}, {1, 1, -1}})
+	tests := []struct {
+		name       string
+		grid       VoxelGrid
+		start, end mgl64.Vec3
+		want       []mgl64.Vec3
+	}{
+		{
+			name:  "ends on the boundary of a dense grid",
+			grid:  full,
+			start: mgl64.Vec3{1.5, 1.5, -4}, end: mgl64.Vec3{1, 1, 0},
+			want: []mgl64.Vec3{{1, 1, 0}},
+		},
+		{
+			name:  "enters a dense grid through an edge",
+			grid:  full,
+			start: mgl64.Vec3{-1, 1.5, 0.5}, end: mgl64.Vec3{1, 3.5, 0.5},
+			want: []mgl64.Vec3{{0, 2, 0}, {0, 3, 0}, {1, 3, 0}},
+		},
+		{
+			name:  "crosses a corner of a dense grid",
+			grid:  full,
+			start: mgl64.Vec3{-4, -0.5, 2}, end: mgl64.Vec3{4.25, 2.5, -0.75},
+			want: []mgl64.Vec3{{0, 0, 0}, {0, 1, 0}, {1, 1, 0}},
+		},
+		{
+			name:  "leaves a sparse grid through an edge",
+			grid:  sparse,
+			start: mgl64.Vec3{1.5, 2, -1}, end: mgl64.Vec3{3.5, -3.5, -3.75},
+			want: []mgl64.Vec3{{1, 1, -1}, {2, -3, -3}},
+		},
+		{
+			name:  "empty sparse grid",
+			grid:  NewSparseVoxelGrid(nil),
+			start: mgl64.Vec3{0.5, 0.5, 0.5}, end: mgl64.Vec3{8, 8, 8},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := TraceGrid(test.start, test.end, test.grid)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+			all, _ := BetweenPoints(test.start, test.end)
+			if want := filterGrid(all, test.grid); !reflect.DeepEqual(got, want) {
+				t.Errorf("got %v, filtered trace is %v", got, want)
+			}
+		})
+	}
+}
+
+func TestTraceGridMatchesFilteredTrace(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	dense := NewDenseVoxelGrid(4, 4, 4)
+	var occupied []mgl64.Vec3
+	for i := 0; i < 40; i++ {
+		dense.Set(rng.Intn(4), rng.Intn(4), rng.Intn(4), true)
+		occupied = append(occupied, mgl64.Vec3{float64(rng.Intn(8) - 4), float64(rng.Intn(8) - 4), float64(rng.Intn(8) - 4)})
+	}
+	sparse := NewSparseVoxelGrid(occupied)
+	for _, g := range []VoxelGrid{dense, sparse} {
+		for i := 0; i < 20000; i++ {
+			start, end := randomPoint(rng, 6), randomPoint(rng, 6)
+			if start == end {
+				continue
+			}
+			got, err := TraceGrid(start, end, g)
+			if err != nil {
+				t.Fatalf("trace %v -> %v: unexpected error: %v", start, end, err)
+			}
+			want, _ := TraceGrid(start, end, unboundedVoxelGrid{g})
+			if !reflect.DeepEqual(got, want) {
+				t.Fatalf("trace %v -> %v: got %v, unbounded trace is %v", start, end, got, want)
+			}
+			all, _ := BetweenPoints(start, end)
+			if want := filterGrid(all, g); !reflect.DeepEqual(got, want) {
+				t.Fatalf("trace %v -> %v: got %v, filtered trace is %v", start, end, got, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package voxelraytrace
+
+import (
+	"github.com/go-gl/mathgl/mgl64"
+	"math"
+)
+
+// VoxelHit holds a voxel passed through by a ray along with the exact points at which the ray entered and left it.
+type VoxelHit struct {
+	// Pos is the position of the voxel.
+	Pos BlockPos
+	// Face is the face through which the ray entered the voxel. It is FaceNone for the voxel the ray starts in and for
+	// voxels that the ray only touches at an edge or corner, as passed through with WithSupercover.
+	Face Face
+	// TEnter and TExit are the distances along the ray at which it enters and leaves the voxel, in world space units
+	// travelled from its start, such as for falloff over distance. TEnter is 0 for the voxel the ray starts in and
+	// TExit is the length of the ray for the voxel it ends in.
+	TEnter, TExit float64
+	// EnterPoint and ExitPoint are the world space points at the distances TEnter and TExit along the ray.
+	EnterPoint, ExitPoint mgl64.Vec3
+}
+
+// Trace performs a ray trace between the start and end coordinates and returns a VoxelHit for every voxel passed
+// through, in the order they are passed through. It is the general entry point of the package: the voxels passed
+// through follow every Option that changes them, such as WithCellSize, WithGridOrigin, WithBounds, WithMinDistance,
+// WithMaxVoxels and WithSupercover, so that new capabilities are added as Options rather than as new functions. If
+// the trace is stopped early by one of the Options passed, the voxels passed through so far are returned along with
+// the error.
+func Trace(start, end mgl64.Vec3, opts ...Option) (hits []VoxelHit, err error) {
+	conf := newConfig(opts)
+	t, err := newTracer(start, end, conf)
+	if err != nil {
+		return nil, err
+	}
+	if !t.enter(conf) {
+		return nil, t.err
+	}
+	if conf.supercover {
+		t.supercover(func(pos BlockPos) {
+			if pos != t.pos() {
+				// The voxel is only touched at the edge or corner at which the ray entered the voxel it is now in.
+				p := t.point(t.t)
+				hits = append(hits, VoxelHit{Pos: pos, Face: FaceNone, TEnter: t.t, TExit: t.t, EnterPoint: p, ExitPoint: p})
+				return
+			}
+			hits = append(hits, t.voxelHit())
+		})
+		return hits, t.err
+	}
+	for {
+		hits = append(hits, t.voxelHit())
+		if !t.next() {
+			return hits, t.err
+		}
+	}
+}
+
+// BetweenPointsVoxelHits performs a ray trace between the start and end coordinates like BetweenPoints, but returns
+// a VoxelHit for every voxel passed through, holding the points at which the ray entered and left it. It is the same
+// as Trace.
+func BetweenPointsVoxelHits(start, end mgl64.Vec3, opts ...Option) (hits []VoxelHit, err error) {
+	return Trace(start, end, opts...)
+}
+
+// voxelHit returns a VoxelHit for the voxel that the tracer is currently at.
+func (t *tracer) voxelHit() VoxelHit {
+	exit, _ := t.peek()
+	exit = math.Min(exit, t.radius)
+	return VoxelHit{
+		Pos:        t.pos(),
+		Face:       t.face,
+		TEnter:     t.t,
+		TExit:      exit,
+		EnterPoint: t.point(t.t),
+		ExitPoint:  t.point(exit),
+	}
+}
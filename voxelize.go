@@ -0,0 +1,333 @@
+package voxelraytrace
+
+import (
+	"github.com/go-gl/mathgl/mgl64"
+	"math"
+)
+
+// VoxelizeSphere returns the positions of all voxels that intersect the sphere with the centre and radius passed,
+// including those only touching it with their boundary. The voxels are ordered by Y, then Z, then X, all ascending. A
+// radius of zero returns the voxel containing the centre, and a negative radius returns no voxels. The voxels of
+// every row are computed directly from the radius, so the cost is proportional to the amount of voxels returned.
+func VoxelizeSphere(center mgl64.Vec3, radius float64) []BlockPos {
+	if radius < 0 {
+		return nil
+	}
+	if radius == 0 {
+		return []BlockPos{BlockPosFromVec3(center)}
+	}
+	positions := make([]BlockPos, 0, sphereCapacity(radius))
+	r2 := radius * radius
+	// The voxel below an integer lower bound touches the sphere with its boundary, as does the one above an integer
+	// upper bound.
+	for y := ceilInt(center[1]-radius) - 1; y <= floorInt(center[1]+radius); y++ {
+		dy := distanceToVoxel(center[1], y)
+		sy := math.Sqrt(math.Max(r2-dy*dy, 0))
+		for z := ceilInt(center[2]-sy) - 1; z <= floorInt(center[2]+sy); z++ {
+			dz := distanceToVoxel(center[2], z)
+			sz := math.Sqrt(math.Max(r2-dy*dy-dz*dz, 0))
+			for x := ceilInt(center[0]-sz) - 1; x <= floorInt(center[0]+sz); x++ {
+				positions = append(positions, BlockPos{x, y, z})
+			}
+		}
+	}
+	return positions
+}
+
+// VoxelizeSphereCenters returns the positions of all voxels of which the centre lies inside the sphere with the centre
+// and radius passed, in the same order as VoxelizeSphere. A radius of zero returns the voxel containing the centre,
+// and a negative radius returns no voxels.
+func VoxelizeSphereCenters(center mgl64.Vec3, radius float64) []BlockPos {
+	if radius < 0 {
+		return nil
+	}
+	if radius == 0 {
+		return []BlockPos{BlockPosFromVec3(center)}
+	}
+	positions := make([]BlockPos, 0, sphereCapacity(radius))
+	r2 := radius * radius
+	for y := ceilInt(center[1] - radius - 0.5); y <= floorInt(center[1]+radius-0.5); y++ {
+		dy := float64(y) + 0.5 - center[1]
+		sy := math.Sqrt(math.Max(r2-dy*dy, 0))
+		for z := ceilInt(center[2] - sy - 0.5); z <= floorInt(center[2]+sy-0.5); z++ {
+			dz := float64(z) + 0.5 - center[2]
+			sz := math.Sqrt(math.Max(r2-dy*dy-dz*dz, 0))
+			for x := ceilInt(center[0] - sz - 0.5); x <= floorInt(center[0]+sz-0.5); x++ {
+				positions = append(positions, BlockPos{x, y, z})
+			}
+		}
+	}
+	return positions
+}
+
+// sphereCapacity returns an estimate of the amount of voxels intersecting a sphere with the radius passed, used to
+// allocate the result of VoxelizeSphere once.
+func sphereCapacity(radius float64) int {
+	r := radius + 1
+	return int(4.0 / 3.0 * math.Pi * r * r * r)
+}
+
+// distanceToVoxel returns the distance from the coordinate passed to the closest point of the voxel at pos on the same
+// axis. If the coordinate lies inside the voxel, 0 is returned.
+func distanceToVoxel(coord float64, pos int) float64 {
+	if coord < float64(pos) {
+		return float64(pos) - coord
+	}
+	if coord > float64(pos+1) {
+		return coord - float64(pos+1)
+	}
+	return 0
+}
+
+// floorInt returns the floor of the float passed as an int.
+func floorInt(f float64) int {
+	return int(math.Floor(f))
+}
+
+// ceilInt returns the ceiling of the float passed as an int.
+func ceilInt(f float64) int {
+	return int(math.Ceil(f))
+}
+
+// VoxelizeAABB returns the positions of all voxels overlapped by the axis-aligned box with the minimum and maximum
+// corners passed, ordered by Y, then Z, then X, all ascending. A face of the box lying exactly on a voxel boundary
+// does not overlap the voxel beyond it, so a box with a maximum X of 5 does not include voxels at X 5. On an axis where
+// the box has no thickness, the voxels containing that coordinate are included. A box of which the minimum exceeds the
+// maximum on any axis overlaps no voxels.
+func VoxelizeAABB(min, max mgl64.Vec3) []BlockPos {
+	lo, hi, ok := aabbVoxels(min, max)
+	if !ok {
+		return nil
+	}
+	positions := make([]BlockPos, 0, (hi[0]-lo[0]+1)*(hi[1]-lo[1]+1)*(hi[2]-lo[2]+1))
+	VoxelizeAABBFunc(min, max, func(pos BlockPos) bool {
+		positions = append(positions, pos)
+		return true
+	})
+	return positions
+}
+
+// VoxelizeAABBFunc calls f for every voxel overlapped by the axis-aligned box with the minimum and maximum corners
+// passed, in the same order as VoxelizeAABB, without allocating. Iteration stops as soon as f returns false.
+func VoxelizeAABBFunc(min, max mgl64.Vec3, f func(pos BlockPos) bool) {
+	lo, hi, ok := aabbVoxels(min, max)
+	if !ok {
+		return
+	}
+	for y := lo[1]; y <= hi[1]; y++ {
+		for z := lo[2]; z <= hi[2]; z++ {
+			for x := lo[0]; x <= hi[0]; x++ {
+				if !f(BlockPos{x, y, z}) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// aabbVoxels returns the inclusive range of voxels overlapped by the axis-aligned box passed, and false if the box is
+// inverted on any axis.
+func aabbVoxels(min, max mgl64.Vec3) (lo, hi BlockPos, ok bool) {
+	for i := 0; i < 3; i++ {
+		if min[i] > max[i] {
+			return lo, hi, false
+		}
+		lo[i] = floorInt(min[i])
+		if max[i] == min[i] {
+			hi[i] = lo[i]
+		} else {
+			hi[i] = ceilInt(max[i]) - 1
+		}
+	}
+	return lo, hi, true
+}
+
+// VoxelizeTriangle returns the positions of all voxels touched by the triangle with the vertices passed, ordered by Y,
+// then Z, then X, all ascending. Every voxel in the bounds of the triangle is tested for overlap using the separating
+// axis theorem, so that thin triangles produce no gaps. A voxel of which only the boundary is touched by the triangle
+// is included. If the vertices are collinear, the voxels of the segment between the two outermost vertices are
+// returned instead, in the order they are traversed.
+func VoxelizeTriangle(a, b, c mgl64.Vec3) []BlockPos {
+	n := b.Sub(a).Cross(c.Sub(a))
+	if n.LenSqr() == 0 {
+		return voxelizeDegenerateTriangle(a, b, c)
+	}
+	var lo, hi BlockPos
+	for i := 0; i < 3; i++ {
+		// The voxel below an integer lower bound touches the triangle with its boundary, as does the one above an integer
+		// upper bound.
+		lo[i] = ceilInt(math.Min(a[i], math.Min(b[i], c[i]))) - 1
+		hi[i] = floorInt(math.Max(a[i], math.Max(b[i], c[i])))
+	}
+
+	var positions []BlockPos
+	for y := lo[1]; y <= hi[1]; y++ {
+		for z := lo[2]; z <= hi[2]; z++ {
+			for x := lo[0]; x <= hi[0]; x++ {
+				pos := BlockPos{x, y, z}
+				if triangleOverlapsVoxel(a, b, c, n, pos) {
+					positions = append(positions, pos)
+				}
+			}
+		}
+	}
+	return positions
+}
+
+// voxelizeDegenerateTriangle returns the voxels of a triangle of which the vertices are collinear, by traversing the
+// segment between the two vertices furthest apart.
+func voxelizeDegenerateTriangle(a, b, c mgl64.Vec3) []BlockPos {
+	start, end := a, b
+	if d := a.Sub(c).LenSqr(); d > start.Sub(end).LenSqr() {
+		start, end = a, c
+	}
+	if d := b.Sub(c).LenSqr(); d > start.Sub(end).LenSqr() {
+		start, end = b, c
+	}
+	positions, err := BetweenPointsInt(start, end)
+	if err != nil {
+		// All vertices are the same point.
+		return []BlockPos{BlockPosFromVec3(a)}
+	}
+	return positions
+}
+
+// triangleOverlapsVoxel checks if the triangle with the vertices and normal passed overlaps the voxel at the position
+// passed, including its boundary, by testing the thirteen potential separating axes of a triangle and a box.
+func triangleOverlapsVoxel(a, b, c, n mgl64.Vec3, pos BlockPos) bool {
+	centre := pos.Vec3Centre()
+	v0, v1, v2 := a.Sub(centre), b.Sub(centre), c.Sub(centre)
+	edges := [3]mgl64.Vec3{v1.Sub(v0), v2.Sub(v1), v0.Sub(v2)}
+	units := [3]mgl64.Vec3{{1, 0, 0}, {0, 1, 0}, {0, 0, 1}}
+
+	separated := func(axis mgl64.Vec3) bool {
+		p0, p1, p2 := v0.Dot(axis), v1.Dot(axis), v2.Dot(axis)
+		r := 0.5 * (math.Abs(axis[0]) + math.Abs(axis[1]) + math.Abs(axis[2]))
+		return math.Min(p0, math.Min(p1, p2)) > r || math.Max(p0, math.Max(p1, p2)) < -r
+	}
+	for _, u := range units {
+		if separated(u) {
+			return false
+		}
+		for _, e := range edges {
+			if separated(u.Cross(e)) {
+				return false
+			}
+		}
+	}
+	return !separated(n)
+}
+
+// VoxelizeFrustum calls fn for every voxel that overlaps the view frustum described by the view and projection
+// matrices passed and lies within maxDistance of origin, which should be the position of the camera of the view
+// matrix. The matrices follow the OpenGL conventions used by mgl64, such as those created by mgl64.LookAtV and
+// mgl64.Perspective. Voxels are visited in shells of increasing distance around the voxel of the origin, so that they
+// are ordered roughly front-to-back. The test is conservative: voxels that are only partly inside the frustum, such as
+// those crossing the near plane, are included, but voxels entirely behind the near plane are not. Enumeration stops
+// as soon as fn returns false.
+func VoxelizeFrustum(origin mgl64.Vec3, view, proj mgl64.Mat4, maxDistance float64, fn func(pos BlockPos) bool) {
+	if maxDistance < 0 {
+		return
+	}
+	planes := frustumPlanes(proj.Mul4(view))
+	r2 := maxDistance * maxDistance
+
+	visit := func(pos BlockPos) bool {
+		min, max := pos.Vec3Min(), pos.Vec3Min().Add(mgl64.Vec3{1, 1, 1})
+		dx, dy, dz := distanceToVoxel(origin[0], pos[0]), distanceToVoxel(origin[1], pos[1]), distanceToVoxel(origin[2], pos[2])
+		if dx*dx+dy*dy+dz*dz > r2 {
+			return true
+		}
+		for _, p := range planes {
+			// Test the corner of the box furthest along the normal of the plane: if it is outside, the whole box is.
+			corner := min
+			for i := 0; i < 3; i++ {
+				if p[i] > 0 {
+					corner[i] = max[i]
+				}
+			}
+			if p.Dot(corner.Vec4(1)) < 0 {
+				return true
+			}
+		}
+		return fn(pos)
+	}
+
+	visitShells(BlockPosFromVec3(origin), maxDistance, visit)
+}
+
+// VoxelizeCone calls fn for every voxel that overlaps the cone with its apex at the position passed, opening up in the
+// direction passed with the half angle in degrees passed, and lies within maxDistance of the apex, such as to select
+// the voxels lit by a flashlight. The half angle must lie between 0 and 90 degrees, and the direction need not be
+// normalised. Voxels are visited in shells of increasing distance around the voxel of the apex like done by
+// VoxelizeFrustum, so that they are ordered roughly front-to-back. The test is conservative: it is done against the
+// sphere enclosing every voxel, so that voxels just next to the surface of the cone may be included. Enumeration stops
+// as soon as fn returns false. Nothing is visited if the direction is zero or the half angle is out of range.
+func VoxelizeCone(apex, dir mgl64.Vec3, halfAngle, maxDistance float64, fn func(pos BlockPos) bool) {
+	if maxDistance < 0 || !(halfAngle > 0 && halfAngle <= 90) || !(sumSquares(dir[0], dir[1], dir[2]) > 0) {
+		return
+	}
+	dir = yUp.normalize(dir)
+	sin, cos := math.Sincos(mgl64.DegToRad(halfAngle))
+	r2 := maxDistance * maxDistance
+	// voxelRadius is the radius of the sphere enclosing a voxel.
+	voxelRadius := math.Sqrt(3) / 2
+
+	visitShells(BlockPosFromVec3(apex), maxDistance, func(pos BlockPos) bool {
+		dx, dy, dz := distanceToVoxel(apex[0], pos[0]), distanceToVoxel(apex[1], pos[1]), distanceToVoxel(apex[2], pos[2])
+		if dx*dx+dy*dy+dz*dz > r2 {
+			return true
+		}
+		// The centre of the voxel is measured along the axis of the cone and away from it, after which the distance
+		// to the surface of the cone follows from the edge of the cone in that plane.
+		v := pos.Vec3Centre().Sub(apex)
+		axial := v.Dot(dir)
+		radial := math.Sqrt(math.Max(v.Dot(v)-axial*axial, 0))
+		if axial*cos+radial*sin < 0 {
+			// The apex is the nearest point of the cone.
+			if v.Len() > voxelRadius {
+				return true
+			}
+		} else if radial*cos-axial*sin > voxelRadius {
+			return true
+		}
+		return fn(pos)
+	})
+}
+
+// visitShells calls visit for every voxel within shells of increasing Chebyshev distance around the centre passed,
+// until the shells reach beyond maxDistance or visit returns false.
+func visitShells(centre BlockPos, maxDistance float64, visit func(pos BlockPos) bool) {
+	shells := ceilInt(maxDistance) + 1
+	for k := 0; k <= shells; k++ {
+		for y := -k; y <= k; y++ {
+			for z := -k; z <= k; z++ {
+				step := 1
+				if y != -k && y != k && z != -k && z != k {
+					// Only the outermost voxels of this row are part of the shell.
+					step = 2 * k
+				}
+				for x := -k; x <= k; x += step {
+					if !visit(centre.Add(BlockPos{x, y, z})) {
+						return
+					}
+					if step == 0 {
+						break
+					}
+				}
+			}
+		}
+	}
+}
+
+// frustumPlanes extracts the six planes of the view frustum described by the combined view-projection matrix passed.
+// The planes are returned as (a, b, c, d), such that a point p lies inside the frustum if a*p.X + b*p.Y + c*p.Z + d
+// is not negative for every plane.
+func frustumPlanes(m mgl64.Mat4) [6]mgl64.Vec4 {
+	r0, r1, r2, r3 := m.Row(0), m.Row(1), m.Row(2), m.Row(3)
+	return [6]mgl64.Vec4{
+		r3.Add(r0), r3.Sub(r0),
+		r3.Add(r1), r3.Sub(r1),
+		r3.Add(r2), r3.Sub(r2),
+	}
+}
@@ -0,0 +1,315 @@
+package voxelraytrace
+
+import (
+	"github.com/go-gl/mathgl/mgl64"
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+// positionSet returns a set holding the positions passed.
+func positionSet(positions []BlockPos) map[BlockPos]struct{} {
+	set := make(map[BlockPos]struct{}, len(positions))
+	for _, pos := range positions {
+		set[pos] = struct{}{}
+	}
+	return set
+}
+
+func TestVoxelizeTriangleAxisAligned(t *testing.T) {
+	// A right triangle in the plane y=0.5 touches every voxel of which the corner nearest to the right angle lies in
+	// the triangle, including those of which only the boundary is touched by its edges.
+	got := VoxelizeTriangle(mgl64.Vec3{0, 0.5, 0}, mgl64.Vec3{2, 0.5, 0}, mgl64.Vec3{0, 0.5, 2})
+	want := []BlockPos{
+		{-1, 0, -1}, {0, 0, -1}, {1, 0, -1}, {2, 0, -1},
+		{-1, 0, 0}, {0, 0, 0}, {1, 0, 0}, {2, 0, 0},
+		{-1, 0, 1}, {0, 0, 1}, {1, 0, 1},
+		{-1, 0, 2}, {0, 0, 2},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestVoxelizeTriangleOnIntegerPlane(t *testing.T) {
+	// A triangle lying on the boundary between two layers of voxels touches both of them in the same way.
+	for axis := 0; axis < 3; axis++ {
+		var a, b, c mgl64.Vec3
+		a[axis], b[axis], c[axis] = 5, 5, 5
+		b[(axis+1)%3], c[(axis+2)%3] = 3.5, 2.25
+		below, above := map[BlockPos]struct{}{}, map[BlockPos]struct{}{}
+		for _, pos := range VoxelizeTriangle(a, b, c) {
+			switch pos[axis] {
+			case 4:
+				pos[axis] = 5
+				below[pos] = struct{}{}
+			case 5:
+				above[pos] = struct{}{}
+			default:
+				t.Fatalf("axis %v: voxel %v does not touch the triangle", axis, pos)
+			}
+		}
+		if len(above) == 0 || !reflect.DeepEqual(below, above) {
+			t.Errorf("axis %v: got %v below the plane and %v above it", axis, below, above)
+		}
+	}
+}
+
+func TestVoxelizeTriangleContainsEdges(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 500; i++ {
+		a, b, c := randomPoint(rng, 8), randomPoint(rng, 8), randomPoint(rng, 8)
+		set := positionSet(VoxelizeTriangle(a, b, c))
+		for _, edge := range [3][2]mgl64.Vec3{{a, b}, {b, c}, {c, a}} {
+			positions, err := BetweenPointsInt(edge[0], edge[1])
+			if err != nil {
+				continue
+			}
+			for _, pos := range positions {
+				if _, ok := set[pos]; !ok {
+					t.Fatalf("triangle %v, %v, %v: voxel %v of edge %v -> %v missing", a, b, c, pos, edge[0], edge[1])
+				}
+			}
+		}
+	}
+}
+
+func TestVoxelizeTriangleDegenerate(t *testing.T) {
+	a, b, c := mgl64.Vec3{0.5, 0.5, 0.5}, mgl64.Vec3{2.5, 1.5, 0.5}, mgl64.Vec3{4.5, 2.5, 0.5}
+	got := VoxelizeTriangle(a, b, c)
+	want, _ := BetweenPointsInt(a, c)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if got := VoxelizeTriangle(a, a, a); !reflect.DeepEqual(got, []BlockPos{{0, 0, 0}}) {
+		t.Errorf("got %v for a triangle of a single point", got)
+	}
+}
+
+// bruteForceSphere returns the voxels near the sphere passed for which the function passed returns true, given the
+// squared distance from the centre of the sphere to the closest point of the voxel and to its centre, in the order
+// documented for VoxelizeSphere.
+func bruteForceSphere(center mgl64.Vec3, radius float64, inside func(closest, centre float64) bool) []BlockPos {
+	var positions []BlockPos
+	lo, hi := BlockPosFromVec3(center).Add(BlockPos{-6, -6, -6}), BlockPosFromVec3(center).Add(BlockPos{6, 6, 6})
+	for y := lo[1]; y <= hi[1]; y++ {
+		for z := lo[2]; z <= hi[2]; z++ {
+			for x := lo[0]; x <= hi[0]; x++ {
+				pos := BlockPos{x, y, z}
+				var closest, centre float64
+				for i := 0; i < 3; i++ {
+					d := distanceToVoxel(center[i], pos[i])
+					closest += d * d
+					c := float64(pos[i]) + 0.5 - center[i]
+					centre += c * c
+				}
+				if inside(closest, centre) {
+					positions = append(positions, pos)
+				}
+			}
+		}
+	}
+	return positions
+}
+
+func TestVoxelizeSphereMatchesBruteForce(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 500; i++ {
+		center, radius := randomPoint(rng, 8), float64(rng.Intn(17))/4
+		if i%2 == 0 {
+			radius = rng.Float64() * 4
+		}
+		if radius == 0 {
+			// A sphere with a radius of zero is tested separately, as it only holds the voxel containing its centre.
+			continue
+		}
+		r2 := radius * radius
+		want := bruteForceSphere(center, radius, func(closest, _ float64) bool { return closest <= r2 })
+		if got := VoxelizeSphere(center, radius); !reflect.DeepEqual(got, want) {
+			t.Fatalf("sphere at %v with radius %v: got %v, want %v", center, radius, got, want)
+		}
+		want = bruteForceSphere(center, radius, func(_, centre float64) bool { return centre <= r2 })
+		if got := VoxelizeSphereCenters(center, radius); len(got) != len(want) || len(got) > 0 && !reflect.DeepEqual(got, want) {
+			t.Fatalf("sphere at %v with radius %v: got centres %v, want %v", center, radius, got, want)
+		}
+	}
+}
+
+func TestVoxelizeSphereEdgeCases(t *testing.T) {
+	center := mgl64.Vec3{-3.5, 64.2, 7.9}
+	for name, voxelize := range map[string]func(mgl64.Vec3, float64) []BlockPos{"intersecting": VoxelizeSphere, "centres": VoxelizeSphereCenters} {
+		if got := voxelize(center, 0); !reflect.DeepEqual(got, []BlockPos{{-4, 64, 7}}) {
+			t.Errorf("%v: got %v for a radius of zero, want only the voxel containing the centre", name, got)
+		}
+		if got := voxelize(center, -1); got != nil {
+			t.Errorf("%v: got %v for a negative radius, want no voxels", name, got)
+		}
+		// The result is allocated once, even for large spheres.
+		if allocs := testing.AllocsPerRun(5, func() { voxelize(center, 40) }); allocs != 1 {
+			t.Errorf("%v: got %v allocations, want 1", name, allocs)
+		}
+	}
+}
+
+func TestVoxelizeAABB(t *testing.T) {
+	tests := []struct {
+		name     string
+		min, max mgl64.Vec3
+		want     []BlockPos
+	}{
+		{
+			name: "integer faces",
+			min:  mgl64.Vec3{3, 0, 0}, max: mgl64.Vec3{5, 1, 1},
+			want: []BlockPos{{3, 0, 0}, {4, 0, 0}},
+		},
+		{
+			name: "fractional faces",
+			min:  mgl64.Vec3{0.5, 0.2, 0.9}, max: mgl64.Vec3{1.5, 0.8, 1.1},
+			want: []BlockPos{{0, 0, 0}, {1, 0, 0}, {0, 0, 1}, {1, 0, 1}},
+		},
+		{
+			name: "negative",
+			min:  mgl64.Vec3{-2, -1.5, -0.3}, max: mgl64.Vec3{-1, -1, 0},
+			want: []BlockPos{{-2, -2, -1}},
+		},
+		{
+			name: "entity",
+			min:  mgl64.Vec3{-0.3, 64, -7.3}, max: mgl64.Vec3{0.3, 65.8, -6.7},
+			want: []BlockPos{{-1, 64, -8}, {0, 64, -8}, {-1, 64, -7}, {0, 64, -7}, {-1, 65, -8}, {0, 65, -8}, {-1, 65, -7}, {0, 65, -7}},
+		},
+		{
+			name: "flat",
+			min:  mgl64.Vec3{0.5, 3, 0.5}, max: mgl64.Vec3{2, 3, 1.5},
+			want: []BlockPos{{0, 3, 0}, {1, 3, 0}, {0, 3, 1}, {1, 3, 1}},
+		},
+		{
+			name: "flat negative",
+			min:  mgl64.Vec3{-1.5, -3, -0.5}, max: mgl64.Vec3{-1.5, -2.5, -0.5},
+			want: []BlockPos{{-2, -3, -1}},
+		},
+		{
+			name: "point",
+			min:  mgl64.Vec3{-1, 2, 3}, max: mgl64.Vec3{-1, 2, 3},
+			want: []BlockPos{{-1, 2, 3}},
+		},
+		{
+			name: "inverted",
+			min:  mgl64.Vec3{0, 0, 1}, max: mgl64.Vec3{1, 1, 0},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := VoxelizeAABB(test.min, test.max)
+			if len(got) != len(test.want) || len(got) > 0 && !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestVoxelizeAABBFunc(t *testing.T) {
+	min, max := mgl64.Vec3{-1.5, 0, -1.5}, mgl64.Vec3{1.5, 2, 1.5}
+	var got []BlockPos
+	VoxelizeAABBFunc(min, max, func(pos BlockPos) bool {
+		got = append(got, pos)
+		return len(got) < 5
+	})
+	if want := VoxelizeAABB(min, max)[:5]; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	n := 0
+	if allocs := testing.AllocsPerRun(10, func() {
+		VoxelizeAABBFunc(min, max, func(BlockPos) bool {
+			n++
+			return true
+		})
+	}); allocs != 0 {
+		t.Errorf("got %v allocations, want 0", allocs)
+	}
+}
+
+// collectVoxels returns the voxels passed to the callback of the voxelization function passed, in order.
+func collectVoxels(voxelize func(fn func(pos BlockPos) bool)) []BlockPos {
+	var positions []BlockPos
+	voxelize(func(pos BlockPos) bool {
+		positions = append(positions, pos)
+		return true
+	})
+	return positions
+}
+
+func TestVoxelizeFrustumMatchesCone(t *testing.T) {
+	origin, dir := mgl64.Vec3{0.3, 64.7, -2.2}, mgl64.Vec3{2, 0.5, 1}.Normalize()
+	fovy, near, maxDistance := 10.0, 0.1, 24.0
+	view := mgl64.LookAtV(origin, origin.Add(dir), mgl64.Vec3{0, 1, 0})
+	proj := mgl64.Perspective(mgl64.DegToRad(fovy), 1, near, 100)
+	frustum := collectVoxels(func(fn func(pos BlockPos) bool) {
+		VoxelizeFrustum(origin, view, proj, maxDistance, fn)
+	})
+	if len(frustum) == 0 {
+		t.Fatal("no voxels inside the frustum")
+	}
+	// A square frustum lies inside the cone through its corners, and holds the cone through the middle of its sides.
+	outer := positionSet(collectVoxels(func(fn func(pos BlockPos) bool) {
+		VoxelizeCone(origin, dir, mgl64.RadToDeg(math.Atan(math.Sqrt2*math.Tan(mgl64.DegToRad(fovy/2)))), maxDistance, fn)
+	}))
+	inner := collectVoxels(func(fn func(pos BlockPos) bool) {
+		VoxelizeCone(origin, dir, fovy/2, maxDistance, fn)
+	})
+	set := positionSet(frustum)
+	for _, pos := range frustum {
+		if _, ok := outer[pos]; !ok {
+			t.Fatalf("voxel %v inside the frustum is outside the enclosing cone", pos)
+		}
+		// Voxels are never entirely behind the camera.
+		if pos.Vec3Centre().Sub(origin).Dot(dir) < -math.Sqrt(3)/2 {
+			t.Fatalf("voxel %v is behind the camera", pos)
+		}
+	}
+	for _, pos := range inner {
+		// The cone test is conservative, so only voxels of which the centre lies inside the inner cone are certainly
+		// inside the frustum, as long as they are in front of the near plane and within the maximum distance.
+		v := pos.Vec3Centre().Sub(origin)
+		axial := v.Dot(dir)
+		if axial <= near || v.Len() > maxDistance || math.Acos(math.Min(axial/v.Len(), 1)) > mgl64.DegToRad(fovy/2) {
+			continue
+		}
+		if _, ok := set[pos]; !ok {
+			t.Fatalf("voxel %v with its centre inside the inner cone is not inside the frustum", pos)
+		}
+	}
+	// The voxels are visited in shells of increasing distance around the voxel of the origin.
+	centre, prev := BlockPosFromVec3(origin), 0
+	for _, pos := range frustum {
+		d := 0
+		for i := 0; i < 3; i++ {
+			if a := pos[i] - centre[i]; a > d {
+				d = a
+			} else if -a > d {
+				d = -a
+			}
+		}
+		if d < prev {
+			t.Fatalf("voxel %v in shell %v visited after shell %v", pos, d, prev)
+		}
+		prev = d
+	}
+}
+
+func TestVoxelizeFrustumStops(t *testing.T) {
+	view := mgl64.LookAtV(mgl64.Vec3{}, mgl64.Vec3{0, 0, -1}, mgl64.Vec3{0, 1, 0})
+	proj := mgl64.Perspective(mgl64.DegToRad(70), 16.0/9, 0.05, 100)
+	n := 0
+	VoxelizeFrustum(mgl64.Vec3{}, view, proj, 16, func(BlockPos) bool {
+		n++
+		return n < 10
+	})
+	if n != 10 {
+		t.Errorf("got %v voxels after stopping at 10", n)
+	}
+	VoxelizeFrustum(mgl64.Vec3{}, view, proj, -1, func(pos BlockPos) bool {
+		t.Fatalf("got voxel %v for a negative max distance", pos)
+		return false
+	})
+}
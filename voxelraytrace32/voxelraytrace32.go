@@ -0,0 +1,57 @@
+// Package voxelraytrace32 provides the ray traces of voxelraytrace for code working with the float32 vectors of
+// mgl32, such as renderers. The coordinates passed are widened to float64 exactly and the traversal itself is that of
+// voxelraytrace, so the voxels passed through are the same as for the same coordinates passed to voxelraytrace.
+// The voxels are produced as mgl32 vectors directly from their integer positions, without converting any mgl64
+// vectors.
+package voxelraytrace32
+
+import (
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/go-gl/mathgl/mgl64"
+	"github.com/justtaldevelops/voxelraytrace"
+)
+
+// BetweenPoints performs a ray trace between the start and end coordinates like voxelraytrace.BetweenPoints and
+// returns the coordinates of the voxels it passes through.
+func BetweenPoints(start, end mgl32.Vec3, opts ...voxelraytrace.Option) ([]mgl32.Vec3, error) {
+	positions, err := voxelraytrace.BetweenPointsInt(vec64(start), vec64(end), opts...)
+	return vectors(positions), err
+}
+
+// InDirection performs a ray trace from the start position in the given direction, for a distance of the
+// maxDistance, like voxelraytrace.InDirection.
+func InDirection(start, directionVector mgl32.Vec3, maxDistance float32, opts ...voxelraytrace.Option) ([]mgl32.Vec3, error) {
+	s, d, dist := vec64(start), vec64(directionVector), float64(maxDistance)
+	// The end is computed the same way as done by voxelraytrace.InDirection, so that the voxels are the same.
+	end := mgl64.Vec3{s[0] + float64(d[0]*dist), s[1] + float64(d[1]*dist), s[2] + float64(d[2]*dist)}
+	positions, err := voxelraytrace.BetweenPointsInt(s, end, opts...)
+	return vectors(positions), err
+}
+
+// FirstSolidHit performs a ray trace between the start and end coordinates like voxelraytrace.FirstSolidHit and
+// returns the position of the first voxel that is solid in the Grid passed, along with the point at which the ray
+// entered it and the distance of that point from the start.
+func FirstSolidHit(g voxelraytrace.Grid, start, end mgl32.Vec3, opts ...voxelraytrace.Option) (pos voxelraytrace.BlockPos, point mgl32.Vec3, dist float32, ok bool, err error) {
+	hit, ok, err := voxelraytrace.FirstSolidHit(g, vec64(start), vec64(end), opts...)
+	if !ok {
+		return voxelraytrace.BlockPos{}, mgl32.Vec3{}, 0, false, err
+	}
+	return hit.Pos, mgl32.Vec3{float32(hit.Point[0]), float32(hit.Point[1]), float32(hit.Point[2])}, float32(hit.Distance), true, nil
+}
+
+// vec64 widens the vector passed to an mgl64.Vec3. Every float32 is exactly representable as a float64.
+func vec64(v mgl32.Vec3) mgl64.Vec3 {
+	return mgl64.Vec3{float64(v[0]), float64(v[1]), float64(v[2])}
+}
+
+// vectors returns the positions passed as mgl32 vectors.
+func vectors(positions []voxelraytrace.BlockPos) []mgl32.Vec3 {
+	if positions == nil {
+		return nil
+	}
+	out := make([]mgl32.Vec3, len(positions))
+	for i, pos := range positions {
+		out[i] = mgl32.Vec3{float32(pos[0]), float32(pos[1]), float32(pos[2])}
+	}
+	return out
+}
@@ -0,0 +1,19 @@
+//go:build go1.23
+
+package voxelraytrace
+
+import (
+	"github.com/go-gl/mathgl/mgl64"
+	"iter"
+)
+
+// Voxels returns an iterator over the voxels passed through by a ray trace between the start and end coordinates, in
+// the same order as BetweenPoints, so that they may be ranged over lazily. The trace is performed anew every time the
+// iterator is used, and breaking out of the loop stops it. If the trace fails, for example because the start and end
+// are the same or because it was stopped early by one of the Options passed, the iterator ends without reporting the
+// error. TraverseFunc may be used where the error is needed.
+func Voxels(start, end mgl64.Vec3, opts ...Option) iter.Seq[mgl64.Vec3] {
+	return func(yield func(mgl64.Vec3) bool) {
+		_ = TraverseFunc(start, end, yield, opts...)
+	}
+}
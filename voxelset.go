@@ -0,0 +1,41 @@
+package voxelraytrace
+
+import (
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// VoxelSet is a set of voxels that are not part of a Grid, such as a layer of scripted or virtual blocks like
+// holograms, selections or clipboards, that may be picked using FirstInSet. VisitedSet implements VoxelSet.
+type VoxelSet interface {
+	// Contains checks if the voxel at the position passed is in the set.
+	Contains(pos BlockPos) bool
+}
+
+// MapVoxelSet is a VoxelSet backed by a map holding the positions of the voxels in it.
+type MapVoxelSet map[BlockPos]struct{}
+
+// Contains checks if the voxel at the position passed is in the set.
+func (s MapVoxelSet) Contains(pos BlockPos) bool {
+	_, ok := s[pos]
+	return ok
+}
+
+// FirstInSet performs a ray trace between the start and end coordinates and returns the first voxel passed through
+// that is in the VoxelSet passed, in the vector representation returned by BetweenPoints. The voxels are visited in
+// the same order as they are returned by BetweenPoints. If none of the voxels are in the set, or the trace is stopped
+// early by one of the Options passed, false is returned.
+func FirstInSet(start, end mgl64.Vec3, set VoxelSet, opts ...Option) (mgl64.Vec3, bool) {
+	conf := newConfig(opts)
+	t, err := newTracer(start, end, conf)
+	if err != nil || !t.enter(conf) {
+		return mgl64.Vec3{}, false
+	}
+	for {
+		if pos := t.pos(); set.Contains(pos) {
+			return pos.Vec3Min(), true
+		}
+		if !t.next() {
+			return mgl64.Vec3{}, false
+		}
+	}
+}